@@ -0,0 +1,393 @@
+//Package bootstrap takes part in the commit-reveal processes that generate the
+//first random number of a DOS network. A process with id cid accepts
+//commitments from StartBlock on for CommitDuration blocks, then the secrets
+//for RevealDuration blocks, and XORs the revealed secrets into a LogRandom.
+package bootstrap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	defaultPollInterval = 15 * time.Second
+	callTimeout         = time.Minute
+	// resultBlocks is how long after the reveal phase a process waits for its
+	// LogRandom before it is given up
+	resultBlocks = 100
+	stateSuffix  = ".json"
+)
+
+//Chain is the part of onchain.ProxyAdapter a participant uses
+type Chain interface {
+	CurrentBlock(ctx context.Context) (uint64, error)
+	Commit(ctx context.Context, cid *big.Int, commitment [32]byte) error
+	Reveal(ctx context.Context, cid *big.Int, secret *big.Int) error
+	SignalBootstrap(ctx context.Context, cid *big.Int) error
+}
+
+type phase string
+
+const (
+	phaseCommit  phase = "commit"
+	phaseReveal  phase = "reveal"
+	phaseSignal  phase = "signal"
+	phaseResult  phase = "result"
+	phaseUnknown phase = ""
+)
+
+// process is the state of one commit-reveal process. It is saved whenever it
+// changes, so that a restarted node still reveals its secret.
+type process struct {
+	Cid        *big.Int
+	StartBlock uint64
+	CommitEnd  uint64
+	RevealEnd  uint64
+	Secret     *big.Int
+	Phase      phase
+	// CommitSent is set before the first commit is sent. The commit may be
+	// mined even if the node never learns of it, so the secret is revealed
+	// anyway once the commit window is over.
+	CommitSent bool
+	// Reveals are the secrets revealed so far, XORed
+	Reveals *big.Int
+	// Revealed are the accounts whose secret is in Reveals. A contract takes
+	// one reveal per account, so a second LogReveal from one is a replay.
+	Revealed map[string]bool
+	// Complete is false if reveals may have been missed while the node was
+	// down
+	Complete bool
+}
+
+func (p *process) id() string {
+	return fmt.Sprintf("%x", p.Cid)
+}
+
+//Participant commits, reveals and signals bootstrap in the block windows of
+//every commit-reveal process it is told about
+type Participant struct {
+	chain     Chain
+	dir       string
+	interval  time.Duration
+	logger    log.Logger
+	lock      sync.Mutex
+	processes map[string]*process
+	lastBlock uint64
+}
+
+//New creates a participant that keeps its secrets in dir and resumes the
+//processes it was taking part in when it stopped
+func New(chain Chain, dir string) (*Participant, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	p := &Participant{
+		chain:     chain,
+		dir:       dir,
+		interval:  defaultPollInterval,
+		logger:    log.New("module", "CommitReveal"),
+		processes: make(map[string]*process),
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), stateSuffix) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pr := &process{}
+		if err := json.Unmarshal(data, pr); err != nil || pr.Cid == nil || pr.Secret == nil {
+			return nil, fmt.Errorf("invalid commit-reveal state %s", f.Name())
+		}
+		if pr.Reveals == nil {
+			pr.Reveals = new(big.Int)
+		}
+		if pr.Revealed == nil {
+			pr.Revealed = make(map[string]bool)
+		}
+		// Secrets revealed while the node was down are lost
+		pr.Complete = pr.Phase == phaseCommit
+		p.processes[pr.id()] = pr
+	}
+	return p, nil
+}
+
+//Start joins the process announced by cr with a new secret below limit
+func (p *Participant) Start(cr *onchain.LogStartCommitReveal, limit *big.Int) error {
+	if cr.StartBlock == nil || cr.CommitDuration == nil || cr.RevealDuration == nil {
+		return errors.New("incomplete LogStartCommitReveal")
+	}
+	if limit == nil || limit.Sign() <= 0 {
+		return errors.New("no upper bound for the secret")
+	}
+	secret, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return err
+	}
+	start := cr.StartBlock.Uint64()
+	pr := &process{
+		Cid:        cr.Cid,
+		StartBlock: start,
+		CommitEnd:  start + cr.CommitDuration.Uint64(),
+		RevealEnd:  start + cr.CommitDuration.Uint64() + cr.RevealDuration.Uint64(),
+		Secret:     secret,
+		Phase:      phaseCommit,
+		Reveals:    new(big.Int),
+		Revealed:   make(map[string]bool),
+		Complete:   true,
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.processes[pr.id()]; ok {
+		return nil
+	}
+	if err := p.save(pr); err != nil {
+		return err
+	}
+	p.processes[pr.id()] = pr
+	p.logger.Event("CRStart", map[string]interface{}{"CID": pr.id(), "StartBlock": pr.StartBlock, "CommitEnd": pr.CommitEnd, "RevealEnd": pr.RevealEnd})
+	return nil
+}
+
+//Run looks for a new block every few seconds until ctx is done
+func (p *Participant) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+			block, err := p.chain.CurrentBlock(callCtx)
+			if err != nil {
+				p.logger.Error(err)
+			} else if block > p.lastBlock {
+				p.lastBlock = block
+				p.OnBlock(callCtx, block)
+			}
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//OnBlock moves every process on whose next phase starts with the block after
+//head. Failed transactions are sent again at the next block while their
+//window is open. A process whose commit was sent moves on to the reveal even
+//if no commit succeeded, since a duplicate commit fails once the first one
+//is mined.
+func (p *Participant) OnBlock(ctx context.Context, head uint64) {
+	// A transaction sent now is mined in the next block at the earliest
+	next := head + 1
+	p.lock.Lock()
+	var todo []*process
+	for _, pr := range p.processes {
+		todo = append(todo, pr)
+	}
+	p.lock.Unlock()
+
+	for _, pr := range todo {
+		switch pr.Phase {
+		case phaseCommit:
+			if next < pr.StartBlock {
+				continue
+			}
+			if next >= pr.CommitEnd {
+				if pr.CommitSent {
+					p.advance(pr, phaseReveal, "CRCommitUnconfirmed", head)
+				} else {
+					p.miss(pr, "commit")
+				}
+				continue
+			}
+			if err := p.commitSent(pr); err != nil {
+				p.logger.Error(err)
+				continue
+			}
+			if err := p.chain.Commit(ctx, pr.Cid, commitment(pr.Secret)); err != nil {
+				p.logger.Event("CRCommitError", map[string]interface{}{"CID": pr.id(), "Error": err.Error(), "Block": head})
+				continue
+			}
+			p.advance(pr, phaseReveal, "CRCommit", head)
+		case phaseReveal:
+			if next < pr.CommitEnd {
+				continue
+			}
+			if next >= pr.RevealEnd {
+				p.miss(pr, "reveal")
+				continue
+			}
+			if err := p.chain.Reveal(ctx, pr.Cid, pr.Secret); err != nil {
+				p.logger.Event("CRRevealError", map[string]interface{}{"CID": pr.id(), "Error": err.Error(), "Block": head})
+				continue
+			}
+			p.advance(pr, phaseSignal, "CRReveal", head)
+		case phaseSignal:
+			if next < pr.RevealEnd {
+				continue
+			}
+			if next >= pr.RevealEnd+resultBlocks {
+				p.miss(pr, "signal")
+				continue
+			}
+			if err := p.chain.SignalBootstrap(ctx, pr.Cid); err != nil {
+				p.logger.Event("CRSignalBootstrapError", map[string]interface{}{"CID": pr.id(), "Error": err.Error(), "Block": head})
+				continue
+			}
+			p.advance(pr, phaseResult, "CRSignalBootstrap", head)
+		case phaseResult:
+			if next >= pr.RevealEnd+resultBlocks {
+				p.miss(pr, "result")
+			}
+		}
+	}
+}
+
+//OnReveal records a secret revealed to a process, once per account however
+//often the event is delivered
+func (p *Participant) OnReveal(reveal *onchain.LogReveal) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pr, ok := p.processes[fmt.Sprintf("%x", reveal.Cid)]
+	if !ok || reveal.Secret == nil {
+		return
+	}
+	from := fmt.Sprintf("%x", reveal.From)
+	if pr.Revealed[from] {
+		return
+	}
+	pr.Revealed[from] = true
+	pr.Reveals.Xor(pr.Reveals, reveal.Secret)
+	if err := p.save(pr); err != nil {
+		p.logger.Error(err)
+	}
+}
+
+//OnRandom checks the random number of a process against the secrets revealed
+//to it and ends the process
+func (p *Participant) OnRandom(random *onchain.LogRandom) (verified bool) {
+	pr := p.end(random.Cid)
+	if pr == nil {
+		return false
+	}
+	info := map[string]interface{}{"CID": pr.id(), "Random": fmt.Sprintf("%x", random.Random)}
+	switch {
+	case !pr.Complete:
+		p.logger.Event("CRRandomUnverified", info)
+	case pr.Reveals.Cmp(random.Random) != 0:
+		info["Expected"] = fmt.Sprintf("%x", pr.Reveals)
+		p.logger.Event("CRRandomMismatch", info)
+	default:
+		verified = true
+		p.logger.Event("CRRandomVerified", info)
+	}
+	return
+}
+
+//OnRandomFailure ends a process that did not get enough reveals
+func (p *Participant) OnRandomFailure(failure *onchain.LogRandomFailure) {
+	if pr := p.end(failure.Cid); pr != nil {
+		p.logger.Event("CRRandomFailure", map[string]interface{}{"CID": pr.id(), "CommitNum": failure.CommitNum, "RevealNum": failure.RevealNum})
+	}
+}
+
+// phase returns the phase of process cid
+func (p *Participant) phase(cid *big.Int) phase {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if pr, ok := p.processes[fmt.Sprintf("%x", cid)]; ok {
+		return pr.Phase
+	}
+	return phaseUnknown
+}
+
+func (p *Participant) advance(pr *process, next phase, event string, head uint64) {
+	p.lock.Lock()
+	pr.Phase = next
+	err := p.save(pr)
+	p.lock.Unlock()
+	if err != nil {
+		p.logger.Error(err)
+	}
+	p.logger.Event(event, map[string]interface{}{"CID": pr.id(), "Block": head})
+}
+
+// commitSent saves that the commit of pr is sent before it is, so that a
+// node restarted after the commit was mined still reveals
+func (p *Participant) commitSent(pr *process) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if pr.CommitSent {
+		return nil
+	}
+	pr.CommitSent = true
+	if err := p.save(pr); err != nil {
+		pr.CommitSent = false
+		return err
+	}
+	return nil
+}
+
+func (p *Participant) miss(pr *process, step string) {
+	p.end(pr.Cid)
+	p.logger.Event("CRMissed", map[string]interface{}{"CID": pr.id(), "Step": step})
+}
+
+func (p *Participant) end(cid *big.Int) *process {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	id := fmt.Sprintf("%x", cid)
+	pr, ok := p.processes[id]
+	if !ok {
+		return nil
+	}
+	delete(p.processes, id)
+	if err := os.Remove(p.path(id)); err != nil && !os.IsNotExist(err) {
+		p.logger.Error(err)
+	}
+	return pr
+}
+
+func (p *Participant) path(id string) string {
+	return filepath.Join(p.dir, id+stateSuffix)
+}
+
+// save writes pr to a temporary file first so that a crash never leaves a
+// half written secret behind
+func (p *Participant) save(pr *process) error {
+	data, err := json.Marshal(pr)
+	if err != nil {
+		return err
+	}
+	tmp := p.path(pr.id()) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path(pr.id()))
+}
+
+// commitment is the hash the contract checks a revealed secret against
+func commitment(secret *big.Int) (hash [32]byte) {
+	copy(hash[:], crypto.Keccak256(abi.U256(secret)))
+	return
+}
@@ -0,0 +1,219 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// crContract checks commitments and reveals against the block windows of a
+// single process like the CommitReveal contract does
+type crContract struct {
+	block      uint64
+	start      uint64
+	commitEnd  uint64
+	revealEnd  uint64
+	commitment [32]byte
+	secret     *big.Int
+	bootstrap  int
+	failCommit bool
+	// lostCommit mines a commit but fails it like a node that went down
+	// before the receipt
+	lostCommit bool
+}
+
+func (c *crContract) CurrentBlock(ctx context.Context) (uint64, error) { return c.block, nil }
+
+func (c *crContract) Commit(ctx context.Context, cid *big.Int, commitment [32]byte) error {
+	// The transaction is mined in the next block
+	if mined := c.block + 1; c.failCommit || mined < c.start || mined >= c.commitEnd {
+		return errors.New("not-in-commit")
+	}
+	if c.commitment != ([32]byte{}) {
+		return errors.New("commit-already-exists")
+	}
+	c.commitment = commitment
+	if c.lostCommit {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (c *crContract) Reveal(ctx context.Context, cid *big.Int, secret *big.Int) error {
+	if mined := c.block + 1; mined < c.commitEnd || mined >= c.revealEnd {
+		return errors.New("not-in-reveal")
+	}
+	if commitment(secret) != c.commitment {
+		return errors.New("revealed-secret-not-match")
+	}
+	c.secret = secret
+	return nil
+}
+
+func (c *crContract) SignalBootstrap(ctx context.Context, cid *big.Int) error {
+	if c.block+1 < c.revealEnd {
+		return errors.New("not-finished")
+	}
+	c.bootstrap++
+	return nil
+}
+
+func (c *crContract) run(p *Participant, from, to uint64) {
+	for c.block = from; c.block <= to; c.block++ {
+		p.OnBlock(context.Background(), c.block)
+	}
+}
+
+func TestParticipant(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	dir, err := ioutil.TempDir("", "commitreveal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &crContract{start: 100, commitEnd: 110, revealEnd: 120, failCommit: true}
+	p, err := New(chain, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid := big.NewInt(7)
+	cr := &onchain.LogStartCommitReveal{Cid: cid, StartBlock: big.NewInt(100), CommitDuration: big.NewInt(10), RevealDuration: big.NewInt(10), RevealThreshold: big.NewInt(1)}
+	if err := p.Start(cr, big.NewInt(0)); err == nil {
+		t.Error("secret without an upper bound accepted")
+	}
+	if err := p.Start(cr, big.NewInt(1000000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is sent before the commit window and failed commits are retried
+	chain.run(p, 90, 100)
+	if p.phase(cid) != phaseCommit {
+		t.Fatalf("phase %q after failed commits, want commit", p.phase(cid))
+	}
+	chain.failCommit = false
+	chain.run(p, 101, 101)
+	if p.phase(cid) != phaseReveal {
+		t.Fatalf("phase %q, want reveal", p.phase(cid))
+	}
+
+	// A restarted node reveals the secret it committed
+	p, err = New(chain, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.run(p, 102, 108)
+	if chain.secret != nil {
+		t.Fatal("revealed before the reveal window")
+	}
+	chain.run(p, 109, 109)
+	if chain.secret == nil || p.phase(cid) != phaseSignal {
+		t.Fatalf("not revealed in the reveal window, phase %q", p.phase(cid))
+	}
+	chain.run(p, 110, 125)
+	if chain.bootstrap != 1 || p.phase(cid) != phaseResult {
+		t.Fatalf("%d bootstrap signals in phase %q, want 1 in result", chain.bootstrap, p.phase(cid))
+	}
+
+	// Secrets revealed while the node was down leave the random unverified
+	p.OnReveal(&onchain.LogReveal{Cid: cid, Secret: chain.secret})
+	if p.OnRandom(&onchain.LogRandom{Cid: cid, Random: chain.secret}) {
+		t.Error("random verified after a restart in the reveal phase")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("%d state files left behind", len(files))
+	}
+}
+
+func TestLostCommit(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	dir, err := ioutil.TempDir("", "commitreveal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &crContract{start: 100, commitEnd: 110, revealEnd: 120, lostCommit: true}
+	p, err := New(chain, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid := big.NewInt(8)
+	cr := &onchain.LogStartCommitReveal{Cid: cid, StartBlock: big.NewInt(100), CommitDuration: big.NewInt(10), RevealDuration: big.NewInt(10), RevealThreshold: big.NewInt(1)}
+	if err := p.Start(cr, big.NewInt(1000000)); err != nil {
+		t.Fatal(err)
+	}
+	chain.run(p, 99, 99)
+	if chain.commitment == ([32]byte{}) || p.phase(cid) != phaseCommit {
+		t.Fatalf("commit not mined or confirmed, phase %q", p.phase(cid))
+	}
+
+	// The restarted node sends duplicate commits, then reveals anyway
+	p, err = New(chain, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.run(p, 100, 109)
+	if p.phase(cid) != phaseReveal {
+		t.Fatalf("phase %q after the commit window, want reveal", p.phase(cid))
+	}
+	chain.run(p, 110, 110)
+	if chain.secret == nil || p.phase(cid) != phaseSignal {
+		t.Fatalf("committed secret not revealed, phase %q", p.phase(cid))
+	}
+}
+
+func TestVerifyRandom(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	dir, err := ioutil.TempDir("", "commitreveal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &crContract{start: 10, commitEnd: 20, revealEnd: 30}
+	p, err := New(chain, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cid := range []int64{1, 2} {
+		cr := &onchain.LogStartCommitReveal{Cid: big.NewInt(cid), StartBlock: big.NewInt(10), CommitDuration: big.NewInt(10), RevealDuration: big.NewInt(10), RevealThreshold: big.NewInt(1)}
+		if err := p.Start(cr, big.NewInt(1000000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.OnReveal(&onchain.LogReveal{Cid: big.NewInt(1), From: []byte{1}, Secret: big.NewInt(0x0f)})
+	p.OnReveal(&onchain.LogReveal{Cid: big.NewInt(1), From: []byte{2}, Secret: big.NewInt(0xf5)})
+	// A reveal delivered again, even after a restart, is counted once
+	p.OnReveal(&onchain.LogReveal{Cid: big.NewInt(1), From: []byte{2}, Secret: big.NewInt(0xf5)})
+	if p, err = New(chain, dir); err != nil {
+		t.Fatal(err)
+	}
+	p.OnReveal(&onchain.LogReveal{Cid: big.NewInt(1), From: []byte{1}, Secret: big.NewInt(0x0f)})
+	if !p.OnRandom(&onchain.LogRandom{Cid: big.NewInt(1), Random: big.NewInt(0xfa)}) {
+		t.Error("XOR of the revealed secrets not verified")
+	}
+	p.OnReveal(&onchain.LogReveal{Cid: big.NewInt(2), From: []byte{1}, Secret: big.NewInt(3)})
+	if p.OnRandom(&onchain.LogRandom{Cid: big.NewInt(2), Random: big.NewInt(4)}) {
+		t.Error("random other than the XOR of the revealed secrets verified")
+	}
+	if p.phase(big.NewInt(2)) != phaseUnknown {
+		t.Error("process not ended by its LogRandom")
+	}
+
+	// A process that never commits is given up after its commit window
+	cr := &onchain.LogStartCommitReveal{Cid: big.NewInt(3), StartBlock: big.NewInt(10), CommitDuration: big.NewInt(10), RevealDuration: big.NewInt(10)}
+	p.Start(cr, big.NewInt(1000))
+	chain.run(p, 25, 25)
+	if p.phase(big.NewInt(3)) != phaseUnknown {
+		t.Error("process kept after its commit window")
+	}
+	p.OnRandomFailure(&onchain.LogRandomFailure{Cid: big.NewInt(3)})
+}
@@ -19,6 +19,25 @@ const (
 	envNodePort      = "NODEPORT"
 	envGroupSize     = "GROUPSIZE"
 	envGroupToPick   = "GROUPTOPICK"
+	envGuardian      = "GUARDIAN"
+	envDiscovery     = "DISCOVERY"
+	envTransport     = "TRANSPORT"
+)
+
+// NodeRoleObserver is the NodeRole of a client that follows the network
+// without registering, joining groups or sending transactions.
+const NodeRoleObserver = "observer"
+
+// Discovery protocols of the p2p network
+const (
+	DiscoveryGossip = "gossip"
+	DiscoveryDHT    = "dht"
+)
+
+// Transports of the p2p network
+const (
+	TransportTCP  = "tcp"
+	TransportQUIC = "quic"
 )
 
 // Config is the configuration for creating a DOS client instance.
@@ -27,6 +46,9 @@ type Config struct {
 	BootStrapIp     []string
 	Port            string
 	ChainConfigs    map[string]map[string]ChainConfig
+	Guardian        GuardianConfig
+	Discovery       string `json:",omitempty"` // "gossip" (default) or "dht"
+	Transport       string `json:",omitempty"` // "tcp" (default) or "quic"
 	randomGroupSize int
 	queryGroupSize  int
 	credentialPath  string
@@ -35,6 +57,22 @@ type Config struct {
 	currentNode     string
 }
 
+// GuardianConfig is the configuration of the guardian that sends the
+// signals which keep the network going.
+type GuardianConfig struct {
+	// Disabled stops the node from acting as a guardian
+	Disabled bool `json:",omitempty"`
+	// Reward is the worth in ether of one GuardianReward, signals costing
+	// more gas are not sent. 0 sends signals whatever they cost.
+	Reward float64 `json:",omitempty"`
+	// MaxBackoff is the largest number of blocks a guardian waits before
+	// signalling, 0 for the default
+	MaxBackoff uint64 `json:",omitempty"`
+	// PollInterval is the number of seconds between two looks for a new
+	// block, 0 for the default
+	PollInterval int `json:",omitempty"`
+}
+
 // ChainConfig is the configuration for connecting to onchan contracts.
 type ChainConfig struct {
 	DOSProxyAddress         string
@@ -42,6 +80,42 @@ type ChainConfig struct {
 	DOSAddressBridgeAddress string
 	CommitReveal            string
 	RemoteNodeAddressPool   []string
+	// Network is the chain ID of a Tendermint chain that every endpoint must report
+	Network string `json:",omitempty"`
+	// ChainID is the EIP-155 chain ID that transactions are signed for and
+	// that every endpoint must report, required for ETH chains
+	ChainID uint64 `json:",omitempty"`
+	// EventSource is "websocket" (default) or "polling" for http-only nodes
+	EventSource string `json:",omitempty"`
+	// PollInterval is the number of seconds between two polls for logs
+	PollInterval int `json:",omitempty"`
+	// PollBlockWindow is the maximum number of blocks asked for in one poll
+	PollBlockWindow uint64 `json:",omitempty"`
+	// PollConfirmations is the number of blocks mined on top of a log
+	// before a poll reports it, 0 for the default
+	PollConfirmations uint64 `json:",omitempty"`
+	// AdvertisedEndpoints are the RPC endpoints this node lets its peers use
+	AdvertisedEndpoints []string `json:",omitempty"`
+	// AllowPeerEndpoints lets the node fall back to endpoints advertised by peers
+	AllowPeerEndpoints bool `json:",omitempty"`
+	// TxType is "auto" (default), "legacy" or "dynamic" for EIP-1559 transactions
+	TxType string `json:",omitempty"`
+	// MaxFeePerGas caps the fee per gas of dynamic-fee transactions in gwei
+	MaxFeePerGas uint64 `json:",omitempty"`
+	// MaxPriorityFeePerGas is the tip per gas in gwei, 0 to use the node's suggestion
+	MaxPriorityFeePerGas uint64 `json:",omitempty"`
+	// LowBalance is the balance in ether below which guardian transactions
+	// pause and a top-up is requested, 0 for the default
+	LowBalance float64 `json:",omitempty"`
+	// CriticalBalance is the balance in ether below which request
+	// transactions are about to fail, 0 for the default
+	CriticalBalance float64 `json:",omitempty"`
+	// FundingAccount is the account top-ups are requested from
+	FundingAccount string `json:",omitempty"`
+	// TopUpURL is the webhook that top-up requests are posted to
+	TopUpURL string `json:",omitempty"`
+	// TopUpAmount is the amount of ether to request, 0 for the default
+	TopUpAmount float64 `json:",omitempty"`
 }
 
 // LoadConfig loads configuration file from path.
@@ -119,11 +193,39 @@ func (c *Config) overWrite() (err error) {
 		c.queryGroupSize = size
 	}
 
+	switch guardian := os.Getenv(envGuardian); guardian {
+	case "":
+	case "on":
+		c.Guardian.Disabled = false
+	case "off":
+		c.Guardian.Disabled = true
+	default:
+		return fmt.Errorf("GUARDIAN must be on or off, not %s", guardian)
+	}
+
 	nodeRole := os.Getenv(envNodeRole)
 	if nodeRole != "" {
 		c.NodeRole = nodeRole
 	}
 
+	if discovery := os.Getenv(envDiscovery); discovery != "" {
+		c.Discovery = discovery
+	}
+	switch c.Discovery {
+	case "", DiscoveryGossip, DiscoveryDHT:
+	default:
+		return fmt.Errorf("DISCOVERY must be %s or %s, not %s", DiscoveryGossip, DiscoveryDHT, c.Discovery)
+	}
+
+	if transport := os.Getenv(envTransport); transport != "" {
+		c.Transport = transport
+	}
+	switch c.Transport {
+	case "", TransportTCP, TransportQUIC:
+	default:
+		return fmt.Errorf("TRANSPORT must be %s or %s, not %s", TransportTCP, TransportQUIC, c.Transport)
+	}
+
 	port := os.Getenv(envNodePort)
 	if port != "" {
 		//TODO:add a check
@@ -140,19 +242,34 @@ func (c *Config) overWrite() (err error) {
 	chainNode := os.Getenv(envChainNode)
 	if chainNode == "" {
 		fmt.Println("No CHAINNODE Environment variable.")
-		chainNode = "rinkeby"
+		// Only pick a chain on the user's behalf if there is no choice to make
+		nodes := c.ChainConfigs[c.currentType]
+		if len(nodes) != 1 {
+			return fmt.Errorf("CHAINNODE must name one of the %d %s chains in the config", len(nodes), c.currentType)
+		}
+		for node := range nodes {
+			chainNode = node
+		}
 	}
 	c.currentNode = chainNode
-	if config, loaded := c.ChainConfigs[c.currentType][c.currentNode]; loaded {
-		gethIP := os.Getenv("GETHPOOL")
-		if gethIP != "" {
-			ipPool := strings.Split(gethIP, ";")
-			for _, ip := range ipPool {
-				config.RemoteNodeAddressPool = append(config.RemoteNodeAddressPool, ip)
-			}
+	config, loaded := c.ChainConfigs[c.currentType][c.currentNode]
+	if !loaded {
+		return fmt.Errorf("no %s chain %s in the config", c.currentType, c.currentNode)
+	}
+	// Transactions are signed for ChainID, it is never taken from an endpoint
+	for node, chain := range c.ChainConfigs["ETH"] {
+		if chain.ChainID == 0 {
+			return fmt.Errorf("ETH chain %s has no ChainID in the config", node)
+		}
+	}
+	gethIP := os.Getenv("GETHPOOL")
+	if gethIP != "" {
+		ipPool := strings.Split(gethIP, ";")
+		for _, ip := range ipPool {
+			config.RemoteNodeAddressPool = append(config.RemoteNodeAddressPool, ip)
 		}
-		c.ChainConfigs[c.currentType][c.currentNode] = config
 	}
+	c.ChainConfigs[c.currentType][c.currentNode] = config
 
 	return
 }
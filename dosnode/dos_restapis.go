@@ -17,6 +17,8 @@ func (d *DosNode) startRESTServer() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", d.status)
 	mux.HandleFunc("/balance", d.balance)
+	mux.HandleFunc("/peers", d.peers)
+	mux.HandleFunc("/dispatch", d.dispatch)
 	mux.HandleFunc("/signalGroupFormation", d.signalGroupFormation)
 	mux.HandleFunc("/signalGroupDissolve", d.signalGroupDissolve)
 	mux.HandleFunc("/signalBootstrap", d.signalBootstrap)
@@ -43,6 +45,9 @@ func (d *DosNode) status(w http.ResponseWriter, r *http.Request) {
 	html = html + "NumOfMembers      : " + strconv.Itoa(d.p.NumOfMembers()) + "\n|"
 	html = html + "State             : " + d.state + "\n|"
 	html = html + "IsPendingnNode    : " + strconv.FormatBool(isPendingNode) + "\n|"
+	if balance := d.funds.Balance(); balance != nil {
+		html = html + "Balance           : " + balance.String() + " (" + d.funds.Level().String() + ")" + "\n|"
+	}
 	html = html + "TotalQuery        : " + strconv.Itoa(d.totalQuery) + "\n|"
 	html = html + "FulfilledQuery    : " + strconv.Itoa(d.fulfilledQuery) + "\n|"
 	html = html + "Group Number      : " + strconv.Itoa(d.numOfworkingGroup) + "\n|"
@@ -81,6 +86,25 @@ func (d *DosNode) status(w http.ResponseWriter, r *http.Request) {
 		html = html + "CurrentBlock      : " + strconv.FormatUint(curBlk, 10) + "\n"
 	}
 	html = html + "=================================================" + "\n"
+	for _, ep := range d.chain.EndpointStatus() {
+		state := "healthy"
+		if ep.Quarantined {
+			state = "quarantined"
+		}
+		html = html + "Endpoint          : " + ep.URL + " (" + state + ")" + "\n|"
+		html = html + "  Head / Lag      : " + strconv.FormatUint(ep.Head, 10) + " / " + strconv.FormatUint(ep.Lag, 10) + "\n|"
+		html = html + "  Latency         : " + ep.Latency.String() + "\n|"
+		html = html + "  ErrorRate       : " + strconv.FormatFloat(ep.ErrorRate, 'f', 2, 64) + "\n|"
+		html = html + "  Score           : " + strconv.FormatFloat(ep.Score, 'f', 2, 64) + "\n"
+	}
+	html = html + "=================================================" + "\n"
+	for _, fee := range d.chain.FeeReport() {
+		html = html + "Fees " + fee.Request + "\n|"
+		html = html + "  Sent / Mined    : " + strconv.FormatUint(fee.Sent, 10) + " / " + strconv.FormatUint(fee.Confirmed+fee.Failed, 10) + " (" + strconv.FormatUint(fee.Failed, 10) + " failed)" + "\n|"
+		html = html + "  GasUsed         : " + strconv.FormatUint(fee.GasUsed, 10) + "\n|"
+		html = html + "  Fee (wei)       : " + fee.Fee.String() + "\n"
+	}
+	html = html + "=================================================" + "\n"
 	w.Write([]byte(html))
 }
 
@@ -90,13 +114,39 @@ func (d *DosNode) balance(w http.ResponseWriter, r *http.Request) {
 	defer cancelFunc()
 	result, err := d.chain.Balance(ctx)
 	if err != nil {
-		html = html + result.String()
-	} else {
 		html = html + err.Error()
+	} else {
+		html = html + result.String() + " (" + d.funds.Level().String() + ")"
 	}
 	w.Write([]byte(html))
 }
 
+func (d *DosNode) peers(w http.ResponseWriter, r *http.Request) {
+	html := "=================================================" + "\n|"
+	for _, peer := range d.p.PeerScores() {
+		state := "active"
+		if peer.Banned {
+			state = "banned until " + peer.BannedUntil.Format("2006-01-02T15:04:05-07:00")
+		}
+		html = html + "Peer              : " + fmt.Sprintf("%x", peer.ID) + " (" + state + ")" + "\n|"
+		html = html + "  IP              : " + peer.IP + "\n|"
+		html = html + "  Score           : " + strconv.FormatFloat(peer.Score, 'f', 2, 64) + "\n"
+	}
+	html = html + "=================================================" + "\n"
+	w.Write([]byte(html))
+}
+
+func (d *DosNode) dispatch(w http.ResponseWriter, r *http.Request) {
+	html := "=================================================" + "\n|"
+	for _, stats := range d.p.DispatchStats() {
+		html = html + "Message           : " + stats.Type + " (" + strconv.Itoa(stats.Subscribers) + " subscribers)" + "\n|"
+		html = html + "  Delivered       : " + strconv.FormatUint(stats.Delivered, 10) + " / " + strconv.FormatUint(stats.Dropped, 10) + " dropped" + "\n|"
+		html = html + "  Handled         : " + strconv.FormatUint(stats.Handled, 10) + " / " + strconv.FormatUint(stats.Unhandled, 10) + " dropped" + "\n"
+	}
+	html = html + "=================================================" + "\n"
+	w.Write([]byte(html))
+}
+
 func (d *DosNode) signalBootstrap(w http.ResponseWriter, r *http.Request) {
 	cid := -1
 	switch r.Method {
@@ -3,19 +3,17 @@ package dosnode
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
-	"unsafe"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
-	"github.com/ethereum/go-ethereum/crypto/sha3"
 
+	"github.com/DOSNetwork/core/bootstrap"
 	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/guardian"
 	"github.com/DOSNetwork/core/log"
 	"github.com/DOSNetwork/core/onchain"
 	"github.com/DOSNetwork/core/p2p"
@@ -23,11 +21,19 @@ import (
 	"github.com/DOSNetwork/core/share/dkg/pedersen"
 	"github.com/DOSNetwork/core/share/vss/pedersen"
 	"github.com/DOSNetwork/core/suites"
+
+	"github.com/golang/protobuf/proto"
 )
 
 const (
-	watchdogInterval = 10 //In minutes
-	envPassPhrase    = "PASSPHRASE"
+	envPassPhrase  = "PASSPHRASE"
+	reconnectDelay = 10 * time.Second
+	// maxEndpointPeers is the number of peers asked for their RPC endpoints
+	maxEndpointPeers = 5
+	// commitRevealPath keeps the secrets of the commit-reveal processes
+	commitRevealPath = "./vault/commitreveal"
+	// warmInterval is the time between two redials of the group members
+	warmInterval = time.Minute
 )
 
 type ctxKey string
@@ -44,6 +50,18 @@ type DosNode struct {
 	eventGrouping chan interface{}
 	id            []byte
 	logger        log.Logger
+	//askPeerEndpoints is set when the node may use RPC endpoints of its peers
+	askPeerEndpoints bool
+	//funds watches the gas balance of the node account
+	funds *onchain.BalanceMonitor
+	//guardian sends the network signals, nil if the node is no guardian
+	guardian *guardian.Guardian
+	//cr takes part in the commit-reveal processes
+	cr *bootstrap.Participant
+	//memberGroups maps the groups the node was grouped into to their members
+	memberGroups sync.Map
+	//peerSigns maps request nonces to the signature shares of the node
+	peerSigns sync.Map
 	//For REST API
 	startTime         time.Time
 	state             string
@@ -59,8 +77,8 @@ type crDurations struct {
 	sec        *big.Int
 }
 
-//NewDosNode creates a DosNode struct
-func NewDosNode(key *keystore.Key) (dosNode *DosNode, err error) {
+//NewDosNode creates a DosNode struct for the node account of signer
+func NewDosNode(signer onchain.Signer) (dosNode *DosNode, err error) {
 
 	//Read Configuration
 	config := configuration.Config{}
@@ -75,7 +93,7 @@ func NewDosNode(key *keystore.Key) (dosNode *DosNode, err error) {
 	chainConfig := config.GetChainConfig()
 
 	//Set up an onchain adapter
-	chainConn, err := onchain.NewProxyAdapter(config.GetCurrentType(), key, chainConfig.DOSProxyAddress, chainConfig.CommitReveal, chainConfig.RemoteNodeAddressPool)
+	chainConn, err := onchain.NewProxyAdapter(config.GetCurrentType(), signer, chainConfig)
 	if err != nil {
 		if err.Error() != "No any working eth client for event tracking" {
 			fmt.Println("NewDosNode failed ", err)
@@ -83,10 +101,23 @@ func NewDosNode(key *keystore.Key) (dosNode *DosNode, err error) {
 		}
 	}
 
-	id := key.Address
+	id := signer.Address()
+
+	funds, err := onchain.NewBalanceMonitor(chainConn, id.Bytes(), chainConfig)
+	if err != nil {
+		fmt.Println("NewBalanceMonitor failed ", err)
+		return
+	}
 
 	//Build a p2p network
-	p, err := p2p.CreateP2PNetwork(id.Bytes(), port, p2p.GossipDiscover)
+	netType := p2p.GossipDiscover
+	if config.Discovery == configuration.DiscoveryDHT {
+		netType = p2p.KademliaDiscover
+	}
+	if config.Transport == configuration.TransportQUIC {
+		netType |= p2p.QUICTransport
+	}
+	p, err := p2p.CreateP2PNetwork(signer, port, netType)
 	if err != nil {
 		fmt.Println("CreateP2PNetwork err ", err)
 		return
@@ -135,6 +166,15 @@ func NewDosNode(key *keystore.Key) (dosNode *DosNode, err error) {
 		totalQuery:        0,
 		fulfilledQuery:    0,
 		numOfworkingGroup: 0,
+		askPeerEndpoints:  chainConfig.AllowPeerEndpoints,
+		funds:             funds,
+	}
+	if dosNode.cr, err = bootstrap.New(chainConn, commitRevealPath); err != nil {
+		fmt.Println("bootstrap.New failed ", err)
+		return nil, err
+	}
+	if !config.Guardian.Disabled {
+		dosNode.guardian = guardian.New(chainConn, id.Bytes(), config.Guardian, funds.AllowNonEssential)
 	}
 
 	return dosNode, nil
@@ -150,6 +190,11 @@ func (d *DosNode) Start() (err error) {
 	return
 }
 
+//SetTopUpHook makes the node ask hook for a top-up when its balance runs low
+func (d *DosNode) SetTopUpHook(hook onchain.TopUpHook) {
+	d.funds.SetTopUpHook(hook)
+}
+
 //End is an operation that does a graceful shutdown
 func (d *DosNode) End() {
 	close(d.done)
@@ -264,6 +309,7 @@ func (d *DosNode) handleGrouping(participants [][]byte, groupID string) {
 	if !isMember {
 		return
 	}
+	d.memberGroups.Store(groupID, participants)
 	d.logger.Event("Grouping", map[string]interface{}{"GroupID": groupID})
 	defer d.logger.TimeTrack(time.Now(), "TimeGrouping", map[string]interface{}{"GroupID": groupID})
 
@@ -306,167 +352,47 @@ func (d *DosNode) groupInfo(groupID string) (ids [][]byte, pubPoly *share.PubPol
 	return
 }
 
-func (d *DosNode) handleCR(cr *onchain.LogStartCommitReveal, randSeed *big.Int) {
-
-	ctx, cancel := d.chain.GetTimeoutCtx(time.Duration(160 * 15 * time.Second))
-	defer cancel()
-
-	// Generate random numbers in range [0..randSeed]
-
-	sec, err := rand.Int(rand.Reader, randSeed)
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	h := sha3.NewKeccak256()
-	h.Write(abi.U256(sec))
-	b := h.Sum(nil)
-	hash := byte32(b)
-	currentBlockNumber, err := d.chain.CurrentBlock(ctx)
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-
-	cid := cr.Cid
-	waitCommit := cr.StartBlock.Uint64() - currentBlockNumber + 1
-	waitReveal := cr.CommitDuration.Uint64() + 1
-	waitRandom := cr.RevealDuration.Uint64() + 1
-	if waitCommit < 0 {
-		waitReveal = waitReveal - waitCommit
-		waitRandom = waitRandom - waitCommit
-		waitCommit = 0
-	}
-
-	time.Sleep(time.Duration(waitCommit*15) * time.Second)
-	fmt.Println("Commit", *hash)
-	d.logger.Event("Commit", map[string]interface{}{"CID": fmt.Sprintf("%x", cid)})
-	if err := d.chain.Commit(ctx, cid, *hash); err != nil {
-		fmt.Println("Commit err ", err)
-		d.logger.Error(err)
-	}
-	<-time.After(time.Duration(waitReveal*15) * time.Second)
-
-	fmt.Println("Reveal", fmt.Sprintf("%x", sec))
-	d.logger.Event("Reveal", map[string]interface{}{"CID": fmt.Sprintf("%x", cid)})
-	if err := d.chain.Reveal(ctx, cid, sec); err != nil {
-		fmt.Println("Reveal err ", err)
-		d.logger.Error(err)
-	}
-	<-time.After(time.Duration(waitRandom*15) * time.Second)
-
-	fmt.Println("SignalBootstrap")
-	d.logger.Event("SignalBootstrap", map[string]interface{}{"CID": fmt.Sprintf("%x", cid)})
-	if err := d.chain.SignalBootstrap(ctx, cid); err != nil {
-		fmt.Println("SignalBootstrap err ", err)
-
-		d.logger.Error(err)
-	}
-}
-
-func (d *DosNode) handleGroupFormation(currentBlockNumber uint64) {
-
-	groupSize, err := d.chain.GroupSize(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	pendingNodeSize, err := d.chain.NumPendingNodes(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-
-	if pendingNodeSize >= groupSize+(groupSize/2) {
-		d.chain.SignalGroupFormation(context.Background())
-	}
-}
-
-func (d *DosNode) handleRandom(currentBlockNumber uint64) {
-
-	groupToPick, err := d.chain.GroupToPick(context.Background())
-	if err != nil {
-		return
-	}
-	workingGroup, err := d.chain.GetWorkingGroupSize(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	lastUpdatedBlock, err := d.chain.LastUpdatedBlock(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	sysrandInterval, err := d.chain.RefreshSystemRandomHardLimit(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	if workingGroup >= groupToPick {
-		diff := currentBlockNumber - lastUpdatedBlock
-		if diff > sysrandInterval {
-			d.chain.SignalRandom(context.Background())
-		}
-	}
-}
-
-func (d *DosNode) handleGroupDissolve() {
-	pendingGrouSize, err := d.chain.NumPendingGroups(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	expiredWGSize, err := d.chain.GetExpiredWorkingGroupSize(context.Background())
-	if err != nil {
-		d.logger.Error(err)
-		return
-	}
-	if expiredWGSize > 0 || pendingGrouSize > 0 {
-		d.chain.SignalGroupDissolve(context.Background())
-	}
-}
-
 func (d *DosNode) listen() {
-	watchdog := time.NewTicker(watchdogInterval * time.Minute)
-	defer watchdog.Stop()
-	peerEvent, _ := d.p.SubscribeEvent(50, vss.Signature{})
-	peerSignMap := make(map[string]*vss.Signature)
+	d.p.Handle(vss.Signature{}, d.handleSignature)
+	d.p.Handle(onchain.Endpoints{}, func(p2p.P2PMessage) (proto.Message, error) {
+		return d.chain.AdvertisedEndpoints(), nil
+	})
 	//	latestRandm := big.NewInt(0)
-	defer d.p.UnSubscribeEvent(vss.Signature{})
+	defer d.p.Handle(vss.Signature{}, nil)
+	defer d.p.Handle(onchain.Endpoints{}, nil)
 	subescriptions := []int{onchain.SubscribeLogGrouping, onchain.SubscribeLogGroupDissolve, onchain.SubscribeLogUrl,
 		onchain.SubscribeLogUpdateRandom, onchain.SubscribeLogRequestUserRandom,
-		onchain.SubscribeLogPublicKeyAccepted, onchain.SubscribeCommitrevealLogStartCommitreveal}
+		onchain.SubscribeLogPublicKeyAccepted, onchain.SubscribeCommitrevealLogStartCommitReveal,
+		onchain.SubscribeCommitrevealLogReveal, onchain.SubscribeCommitrevealLogRandom, onchain.SubscribeCommitrevealLogRandomFailure}
 	randSeed, _ := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
 	d.chain.Start()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.funds.Run(ctx)
+	if d.guardian != nil {
+		go d.guardian.Run(ctx)
+	}
+	go d.cr.Run(ctx)
+	go d.keepGroupsWarm(ctx)
 	//TODO: Check to see if it is a valid stacking node first
 	_ = d.chain.RegisterNewNode(context.Background())
 	fmt.Println("(d *DosNode) listen()")
 S:
-	sink, errc := d.chain.SubscribeEvent(subescriptions)
+	sub, err := d.chain.SubscribeEvent(50, nil, subescriptions...)
+	if err != nil {
+		d.logger.Error(err)
+		return
+	}
+	sink, errc := sub.Events(), sub.Err()
 L:
 	for {
 		select {
-		case <-watchdog.C:
-			if isPendingNode, _ := d.chain.IsPendingNode(context.Background(), d.id); isPendingNode {
-				//Let pending node as a guardian
-				currentBlockNumber, err := d.chain.CurrentBlock(context.Background())
-				if err != nil {
-					d.logger.Error(err)
-					return
-				}
-				switch index := currentBlockNumber % 3; index {
-				case 0:
-					d.handleRandom(currentBlockNumber)
-				case 1:
-					d.handleGroupFormation(currentBlockNumber)
-				case 2:
-					d.handleGroupDissolve()
-				}
-			}
 		case event, ok := <-sink:
 			if ok {
-				switch content := event.(type) {
+				if event.Removed {
+					continue
+				}
+				switch content := event.Event.(type) {
 				case *onchain.LogGrouping:
 					groupID := fmt.Sprintf("%x", content.GroupId)
 					go d.handleGrouping(content.NodeId, groupID)
@@ -475,6 +401,7 @@ L:
 					if d.isMember(groupID) {
 						d.logger.Event("DGroupDismiss", map[string]interface{}{"GroupID": groupID})
 						d.dkg.GroupDissolve(groupID)
+						d.memberGroups.Delete(groupID)
 					}
 				case *onchain.LogPublicKeyAccepted:
 					groupID := fmt.Sprintf("%x", content.GroupId)
@@ -541,7 +468,15 @@ L:
 					}
 				case *onchain.LogStartCommitReveal:
 					fmt.Println("startBlock ", content.StartBlock.String(), " commitDur ", content.CommitDuration.String(), "revealDur", content.RevealDuration.String())
-					go d.handleCR(content, randSeed)
+					if err := d.cr.Start(content, randSeed); err != nil {
+						d.logger.Error(err)
+					}
+				case *onchain.LogReveal:
+					d.cr.OnReveal(content)
+				case *onchain.LogRandom:
+					d.cr.OnRandom(content)
+				case *onchain.LogRandomFailure:
+					d.cr.OnRandomFailure(content)
 				}
 
 			} else {
@@ -557,45 +492,123 @@ L:
 			if !ok {
 				return
 			}
-			peerSignMap[string(msg.Nonce)] = msg
-		case msg, ok := <-peerEvent:
-			if !ok {
-				return
-			}
-			switch content := msg.Msg.Message.(type) {
-			case *vss.Signature:
-				if peerSignMap[string(content.Nonce)] != nil {
-					fmt.Println("Got Sign ", peerSignMap[string(content.Nonce)].RequestId)
-				}
-				d.p.Reply(msg.Sender, msg.RequestNonce, peerSignMap[string(content.Nonce)])
-			}
+			d.peerSigns.Store(string(msg.Nonce), msg)
 		case <-d.done:
 			return
 		}
 	}
-	d.chain.End()
-	ips := d.p.MembersIP()
-	var urls = []string{}
-	urls = append(urls, "wss://rinkeby.infura.io/ws/v3/db19cf9028054762865cb9ce883c6ab8")
-	urls = append(urls, "wss://rinkeby.infura.io/ws/v3/3a3e5d776961418e93a8b33fef2f6642")
-	for _, ip := range ips {
-		urls = append(urls, "ws://"+ip.String()+":8546")
-		if len(urls) >= 5 {
+	for {
+		if d.askPeerEndpoints {
+			d.askEndpoints()
+		}
+		err := d.chain.Reconnect()
+		if err == nil {
 			break
 		}
+		d.logger.Error(err)
+		select {
+		case <-time.After(reconnectDelay):
+		case <-d.done:
+			return
+		}
 	}
-	d.chain.UpdateWsUrls(urls)
-	d.chain.Start()
 	goto S
 }
 
-func (d *DosNode) isMember(groupID string) bool {
-	return d.dkg.GetShareSecurity(groupID) != nil
+// handleSignature answers a submitter with the signature share of the node
+// for the nonce of its request
+func (d *DosNode) handleSignature(msg p2p.P2PMessage) (proto.Message, error) {
+	content, ok := msg.Msg.Message.(*vss.Signature)
+	if !ok {
+		return nil, errors.New("handleSignature cast error")
+	}
+	sign, ok := d.peerSigns.Load(string(content.Nonce))
+	if !ok {
+		return nil, fmt.Errorf("no signature share for nonce %x", content.Nonce)
+	}
+	fmt.Println("Got Sign ", sign.(*vss.Signature).RequestId)
+	return sign.(*vss.Signature), nil
+}
+
+// askEndpoints collects the RPC endpoints advertised by peers so that the
+// adaptor can fall back to them when reconnecting
+func (d *DosNode) askEndpoints() {
+	ask := &onchain.Endpoints{ChainId: d.chain.AdvertisedEndpoints().GetChainId()}
+	if ask.ChainId == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	asked := 0
+	for _, id := range d.p.MembersID() {
+		if asked == maxEndpointPeers {
+			break
+		}
+		if bytes.Equal(id, d.id) {
+			continue
+		}
+		asked++
+		wg.Add(1)
+		go func(id []byte) {
+			defer wg.Done()
+			msg, err := d.p.Request(id, ask)
+			if err != nil {
+				return
+			}
+			if advertised, ok := msg.Msg.Message.(*onchain.Endpoints); ok {
+				d.chain.AddPeerEndpoints(advertised)
+			}
+		}(id)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(reconnectDelay):
+	}
 }
 
-func byte32(s []byte) (a *[32]byte) {
-	if len(a) <= len(s) {
-		a = (*[len(a)]byte)(unsafe.Pointer(&s[0]))
+// keepGroupsWarm redials the members of the working groups of the node, whose
+// addresses resolve from their IDs, so that the connections are up when the
+// group is dispatched a request
+func (d *DosNode) keepGroupsWarm(ctx context.Context) {
+	ticker := time.NewTicker(warmInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.memberGroups.Range(func(key, value interface{}) bool {
+				groupID, ids := key.(string), value.([][]byte)
+				if d.isMember(groupID) {
+					go d.warmGroup(ctx, groupID, ids)
+				}
+				return true
+			})
+		case <-ctx.Done():
+			return
+		}
 	}
-	return a
+}
+
+func (d *DosNode) warmGroup(ctx context.Context, groupID string, ids [][]byte) {
+	ctx, cancel := context.WithTimeout(ctx, warmInterval)
+	defer cancel()
+	out, errc := d.p.ConnectToAll(ctx, ids, 0, groupID)
+	select {
+	case r, ok := <-out:
+		if ok && len(r.Unreachable) > 0 {
+			d.logger.Event("groupMembersUnreachable", map[string]interface{}{"GroupID": groupID, "Unreachable": len(r.Unreachable)})
+		}
+	case err, ok := <-errc:
+		if ok {
+			d.logger.Error(err)
+		}
+	case <-ctx.Done():
+	}
+}
+
+func (d *DosNode) isMember(groupID string) bool {
+	return d.dkg.GetShareSecurity(groupID) != nil
 }
@@ -0,0 +1,286 @@
+//Package explorer indexes the events of a DOS network into an embedded
+//database and answers queries about nodes, groups and requests from it. The
+//events are indexed from the block the indexer first ran at on, including
+//those emitted while it was stopped or disconnected.
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+)
+
+const (
+	// catchUpWindow is the number of blocks whose past events are fetched at
+	// once
+	catchUpWindow = 5000
+	callTimeout   = time.Minute
+)
+
+//Chain is the part of onchain.ProxyAdapter an indexer uses
+type Chain interface {
+	onchain.EventSource
+	CurrentBlock(ctx context.Context) (uint64, error)
+	PastEvents(ctx context.Context, from, to uint64, subscribeTypes ...int) ([]*onchain.LogCommon, error)
+}
+
+//Node is the summary of a node
+type Node struct {
+	Address      string
+	RegisteredAt uint64
+	Groups       []string
+	Rewards      uint64
+}
+
+//NodeEvent is an entry of the history of a node
+type NodeEvent struct {
+	Block   uint64
+	Tx      string
+	Event   string
+	GroupID string `json:",omitempty"`
+}
+
+//Group is a group and how well it served its requests
+type Group struct {
+	ID          string
+	Members     []string
+	FormedAt    uint64
+	AcceptedAt  uint64
+	DissolvedAt uint64
+	Requests    uint64
+	Fulfilled   uint64
+	// Rejected counts results that failed validation
+	Rejected uint64
+}
+
+//Reliability is the share of the requests dispatched to g it fulfilled
+func (g *Group) Reliability() float64 {
+	if g.Requests == 0 {
+		return 0
+	}
+	return float64(g.Fulfilled) / float64(g.Requests)
+}
+
+//Request is a request dispatched to a group. The times are those the indexer
+//saw the events at.
+type Request struct {
+	ID            string
+	Type          string
+	GroupID       string
+	RequestedAt   uint64
+	RequestedTime time.Time
+	FulfilledAt   uint64
+	FulfilledTime time.Time
+	Rejected      uint64
+}
+
+//Indexer writes the events of a chain to a store
+type Indexer struct {
+	chain  Chain
+	store  *Store
+	logger log.Logger
+	now    func() time.Time
+	// lock serializes the read-modify-write of records
+	lock sync.Mutex
+}
+
+//NewIndexer creates an indexer of the events of chain into store
+func NewIndexer(chain Chain, store *Store) *Indexer {
+	return &Indexer{
+		chain:  chain,
+		store:  store,
+		logger: log.New("module", "Explorer"),
+		now:    time.Now,
+	}
+}
+
+//Run indexes events until ctx is done, reconnecting when the subscription
+//fails and catching up on the events it missed
+func (x *Indexer) Run(ctx context.Context) {
+	onchain.Follow(ctx, x.chain, x.logger, func(event *onchain.LogCommon) {
+		if err := x.Index(event); err != nil {
+			x.logger.Error(err)
+		}
+	}, x.catchUp)
+}
+
+// catchUp indexes the past events from the block after the last one whose
+// events are all indexed up to the head. A new store starts at the head.
+func (x *Indexer) catchUp(ctx context.Context) error {
+	callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+	head, err := x.chain.CurrentBlock(callCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	last, err := x.store.lastBlock()
+	if err != nil {
+		return err
+	}
+	if last == 0 {
+		return x.store.setLastBlock(head)
+	}
+	for from := last + 1; from <= head; from += catchUpWindow {
+		to := from + catchUpWindow - 1
+		if to > head {
+			to = head
+		}
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		events, err := x.chain.PastEvents(callCtx, from, to, onchain.AllSubscribeTypes()...)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := x.Index(event); err != nil {
+				return err
+			}
+		}
+		if err := x.store.setLastBlock(to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Index writes one event to the store. An event that is already indexed, as
+//the subscription and a catch-up may both deliver it, is skipped.
+func (x *Indexer) Index(event *onchain.LogCommon) (err error) {
+	if event.Removed {
+		return nil
+	}
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	key := eventKey(event.BlockN, event.Index)
+	if ok, err := x.store.has(key); err != nil || ok {
+		return err
+	}
+	switch content := event.Event.(type) {
+	case *onchain.LogRegisteredNewPendingNode:
+		node := fmt.Sprintf("%x", content.Node)
+		err = x.updateNode(node, func(n *Node) { n.RegisteredAt = event.BlockN })
+		if err == nil {
+			err = x.record(event, node, "Registered", "")
+		}
+	case *onchain.LogGrouping:
+		g := &Group{ID: onchain.HexID(content.GroupId), FormedAt: event.BlockN}
+		for _, id := range content.NodeId {
+			g.Members = append(g.Members, fmt.Sprintf("%x", id))
+		}
+		if err = x.store.put(prefixGroup+g.ID, g); err != nil {
+			return
+		}
+		for _, member := range g.Members {
+			if err = x.updateNode(member, func(n *Node) { n.Groups = append(n.Groups, g.ID) }); err != nil {
+				return
+			}
+			if err = x.record(event, member, "Grouped", g.ID); err != nil {
+				return
+			}
+		}
+	case *onchain.LogPublicKeyAccepted:
+		err = x.updateGroup(event, content.GroupId, "GroupAccepted", func(g *Group) { g.AcceptedAt = event.BlockN })
+	case *onchain.LogGroupDissolve:
+		err = x.updateGroup(event, content.GroupId, "GroupDissolved", func(g *Group) { g.DissolvedAt = event.BlockN })
+	case *onchain.LogPendingGroupRemoved:
+		err = x.updateGroup(event, content.GroupId, "GroupRemoved", func(g *Group) { g.DissolvedAt = event.BlockN })
+	case *onchain.LogGuardianReward:
+		node := fmt.Sprintf("%x", content.Guardian)
+		err = x.updateNode(node, func(n *Node) { n.Rewards++ })
+		if err == nil {
+			err = x.record(event, node, "GuardianReward", "")
+		}
+	case *onchain.LogUpdateRandom:
+		err = x.request(event, onchain.TrafficSystemRandom, content.LastRandomness, content.DispatchedGroupId)
+	case *onchain.LogRequestUserRandom:
+		err = x.request(event, onchain.TrafficUserRandom, content.RequestId, content.DispatchedGroupId)
+	case *onchain.LogUrl:
+		err = x.request(event, onchain.TrafficUserQuery, content.QueryId, content.DispatchedGroupId)
+	case *onchain.LogValidationResult:
+		err = x.result(event, content)
+	}
+	if err != nil {
+		return
+	}
+	return x.store.put(key, true)
+}
+
+func (x *Indexer) updateNode(node string, update func(n *Node)) error {
+	n := &Node{Address: node}
+	if _, err := x.store.get(prefixNode+node, n); err != nil {
+		return err
+	}
+	update(n)
+	return x.store.put(prefixNode+node, n)
+}
+
+// updateGroup updates group id and, if name is set, adds event to the history
+// of its members
+func (x *Indexer) updateGroup(event *onchain.LogCommon, id *big.Int, name string, update func(g *Group)) error {
+	g := &Group{ID: onchain.HexID(id)}
+	if _, err := x.store.get(prefixGroup+g.ID, g); err != nil {
+		return err
+	}
+	update(g)
+	if err := x.store.put(prefixGroup+g.ID, g); err != nil {
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+	for _, member := range g.Members {
+		if err := x.record(event, member, name, g.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *Indexer) record(event *onchain.LogCommon, node, name, groupID string) error {
+	return x.store.put(historyKey(node, event.BlockN, event.Index), &NodeEvent{Block: event.BlockN, Tx: event.Tx, Event: name, GroupID: groupID})
+}
+
+func (x *Indexer) request(event *onchain.LogCommon, trafficType uint8, id, groupID *big.Int) error {
+	key := prefixRequest + onchain.RequestKey(trafficType, id)
+	r := &Request{}
+	if ok, err := x.store.get(key, r); err != nil || ok {
+		return err
+	}
+	r = &Request{ID: onchain.HexID(id), Type: onchain.TrafficNames[trafficType], GroupID: onchain.HexID(groupID), RequestedAt: event.BlockN, RequestedTime: x.now()}
+	if err := x.store.put(key, r); err != nil {
+		return err
+	}
+	return x.updateGroup(event, groupID, "", func(g *Group) { g.Requests++ })
+}
+
+func (x *Indexer) result(event *onchain.LogCommon, v *onchain.LogValidationResult) error {
+	key := prefixRequest + onchain.RequestKey(v.TrafficType, v.TrafficId)
+	r := &Request{}
+	if ok, err := x.store.get(key, r); err != nil || !ok || r.FulfilledAt != 0 {
+		return err
+	}
+	if v.Pass {
+		r.FulfilledAt = event.BlockN
+		r.FulfilledTime = x.now()
+	} else {
+		r.Rejected++
+	}
+	if err := x.store.put(key, r); err != nil {
+		return err
+	}
+	g := &Group{ID: r.GroupID}
+	if ok, err := x.store.get(prefixGroup+g.ID, g); err != nil || !ok {
+		return err
+	}
+	if v.Pass {
+		g.Fulfilled++
+	} else {
+		g.Rejected++
+	}
+	return x.store.put(prefixGroup+g.ID, g)
+}
@@ -0,0 +1,180 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func event(block uint64, index uint, e interface{}) *onchain.LogCommon {
+	return &onchain.LogCommon{BlockN: block, Index: index, Event: e}
+}
+
+func TestIndexer(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	dir, err := ioutil.TempDir("", "explorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := NewIndexer(nil, store)
+	now := time.Unix(1000, 0)
+	x.now = func() time.Time { return now }
+
+	events := []*onchain.LogCommon{
+		event(1, 0, &onchain.LogRegisteredNewPendingNode{Node: []byte{0xaa}}),
+		event(2, 0, &onchain.LogGrouping{GroupId: big.NewInt(10), NodeId: [][]byte{{0xaa}, {0xbb}}}),
+		event(3, 0, &onchain.LogPublicKeyAccepted{GroupId: big.NewInt(10)}),
+		event(3, 1, &onchain.LogGuardianReward{Guardian: []byte{0xaa}}),
+	}
+	// Ten requests fulfilled after 1 to 10 blocks and one left open
+	for i := uint64(1); i <= 11; i++ {
+		events = append(events, event(10, uint(i), &onchain.LogUrl{QueryId: new(big.Int).SetUint64(i), DispatchedGroupId: big.NewInt(10)}))
+	}
+	events = append(events, event(11, 0, &onchain.LogValidationResult{TrafficType: onchain.TrafficUserQuery, TrafficId: big.NewInt(1)}))
+	for i := uint64(1); i <= 10; i++ {
+		events = append(events, event(10+i, 1, &onchain.LogValidationResult{TrafficType: onchain.TrafficUserQuery, TrafficId: new(big.Int).SetUint64(i), Pass: true}))
+	}
+	// Every event is delivered twice, as by the subscription and a catch-up
+	for _, e := range append(events, events...) {
+		if err := x.Index(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, history, err := store.Node("0xAA")
+	if err != nil || n == nil {
+		t.Fatalf("node not found: %v", err)
+	}
+	if n.RegisteredAt != 1 || len(n.Groups) != 1 || n.Rewards != 1 {
+		t.Errorf("unexpected node %+v", n)
+	}
+	var names []string
+	for _, e := range history {
+		names = append(names, e.Event)
+	}
+	if len(names) != 4 || names[0] != "Registered" || names[1] != "Grouped" || names[2] != "GroupAccepted" || names[3] != "GuardianReward" {
+		t.Errorf("unexpected history %v", names)
+	}
+
+	g, err := store.Group("a")
+	if err != nil || g == nil {
+		t.Fatalf("group not found: %v", err)
+	}
+	if g.Requests != 11 || g.Fulfilled != 10 || g.Rejected != 1 {
+		t.Errorf("unexpected group %+v", g)
+	}
+	latency, err := store.Latency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latency.Count != 10 || latency.Blocks.P50 != 5 || latency.Blocks.P90 != 9 || latency.Blocks.P99 != 10 {
+		t.Errorf("unexpected latency %+v", latency)
+	}
+
+	// Dissolved groups are not working groups
+	if groups, err := store.WorkingGroups(); err != nil || len(groups) != 1 {
+		t.Fatalf("%d working groups, want 1: %v", len(groups), err)
+	}
+	x.Index(event(30, 0, &onchain.LogGroupDissolve{GroupId: big.NewInt(10)}))
+	if groups, err := store.WorkingGroups(); err != nil || len(groups) != 0 {
+		t.Errorf("%d working groups after dissolve, want 0: %v", len(groups), err)
+	}
+
+	server := httptest.NewServer(Handler(store))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/groups/0xa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var report groupReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.DissolvedAt != 30 || report.Reliability < 0.9 || report.Reliability > 0.91 {
+		t.Errorf("unexpected group report %+v", report)
+	}
+	if resp, err := server.Client().Get(server.URL + "/nodes/cc"); err != nil || resp.StatusCode != 404 {
+		t.Errorf("unknown node served: %v", err)
+	}
+}
+
+// pastChain serves the past events of a chain whose head is head
+type pastChain struct {
+	onchain.EventSource
+	head    uint64
+	events  []*onchain.LogCommon
+	windows [][2]uint64
+}
+
+func (c *pastChain) CurrentBlock(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *pastChain) PastEvents(ctx context.Context, from, to uint64, subscribeTypes ...int) (events []*onchain.LogCommon, err error) {
+	c.windows = append(c.windows, [2]uint64{from, to})
+	for _, e := range c.events {
+		if e.BlockN >= from && e.BlockN <= to {
+			events = append(events, e)
+		}
+	}
+	return
+}
+
+func TestCatchUp(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	dir, err := ioutil.TempDir("", "explorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &pastChain{head: 100, events: []*onchain.LogCommon{
+		event(50, 0, &onchain.LogGuardianReward{Guardian: []byte{0xaa}}),
+		event(101, 0, &onchain.LogGuardianReward{Guardian: []byte{0xaa}}),
+		event(6000, 3, &onchain.LogGuardianReward{Guardian: []byte{0xaa}}),
+	}}
+	x := NewIndexer(chain, store)
+
+	// A new store starts at the head, without the history before it
+	if err := x.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if last, _ := store.lastBlock(); last != 100 || len(chain.windows) != 0 {
+		t.Fatalf("new store at block %d after %d queries, want 100 after none", last, len(chain.windows))
+	}
+
+	// The subscription delivered the event at block 6000 before the indexer
+	// caught up on the blocks mined while it was stopped
+	x.Index(chain.events[2])
+	chain.head = 6000
+	if err := x.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if last, _ := store.lastBlock(); last != 6000 {
+		t.Errorf("caught up to block %d, want 6000", last)
+	}
+	if len(chain.windows) != 2 || chain.windows[0] != [2]uint64{101, 5100} || chain.windows[1] != [2]uint64{5101, 6000} {
+		t.Errorf("queried windows %v", chain.windows)
+	}
+	if n, _, err := store.Node("aa"); err != nil || n == nil || n.Rewards != 2 {
+		t.Errorf("node after catching up %+v, %v, want 2 rewards", n, err)
+	}
+}
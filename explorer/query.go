@@ -0,0 +1,103 @@
+package explorer
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+//Percentiles are the 50th, 90th and 99th percentiles of a sample
+type Percentiles struct {
+	P50 float64
+	P90 float64
+	P99 float64
+}
+
+//Latency is how long fulfilled requests waited for their results, in blocks
+//and in seconds
+type Latency struct {
+	Count   int
+	Blocks  Percentiles
+	Seconds Percentiles
+}
+
+//Node returns the summary and history of a node, nil if it was never seen
+func (s *Store) Node(address string) (*Node, []NodeEvent, error) {
+	address = normalize(address)
+	n := &Node{}
+	if ok, err := s.get(prefixNode+address, n); err != nil || !ok {
+		return nil, nil, err
+	}
+	var history []NodeEvent
+	err := s.each(prefixHistory+address+"/", func(data []byte) error {
+		var e NodeEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		history = append(history, e)
+		return nil
+	})
+	return n, history, err
+}
+
+//Group returns a group, nil if it was never seen
+func (s *Store) Group(id string) (*Group, error) {
+	g := &Group{}
+	if ok, err := s.get(prefixGroup+normalize(id), g); err != nil || !ok {
+		return nil, err
+	}
+	return g, nil
+}
+
+//WorkingGroups returns the groups whose public key was accepted and that were
+//not dissolved, oldest first
+func (s *Store) WorkingGroups() ([]Group, error) {
+	var groups []Group
+	err := s.each(prefixGroup, func(data []byte) error {
+		var g Group
+		if err := json.Unmarshal(data, &g); err != nil {
+			return err
+		}
+		if g.AcceptedAt != 0 && g.DissolvedAt == 0 {
+			groups = append(groups, g)
+		}
+		return nil
+	})
+	sort.Slice(groups, func(i, j int) bool { return groups[i].AcceptedAt < groups[j].AcceptedAt })
+	return groups, err
+}
+
+//Latency returns the latency percentiles of the fulfilled requests
+func (s *Store) Latency() (Latency, error) {
+	var blocks, seconds []float64
+	err := s.each(prefixRequest, func(data []byte) error {
+		var r Request
+		if err := json.Unmarshal(data, &r); err != nil {
+			return err
+		}
+		if r.FulfilledAt != 0 {
+			blocks = append(blocks, float64(r.FulfilledAt-r.RequestedAt))
+			seconds = append(seconds, r.FulfilledTime.Sub(r.RequestedTime).Seconds())
+		}
+		return nil
+	})
+	return Latency{Count: len(blocks), Blocks: percentiles(blocks), Seconds: percentiles(seconds)}, err
+}
+
+// percentiles uses the nearest rank method
+func percentiles(sample []float64) (p Percentiles) {
+	if len(sample) == 0 {
+		return
+	}
+	sort.Float64s(sample)
+	rank := func(q float64) float64 {
+		return sample[int(math.Ceil(q*float64(len(sample))))-1]
+	}
+	return Percentiles{P50: rank(0.5), P90: rank(0.9), P99: rank(0.99)}
+}
+
+// normalize turns an address or id into the lower case hex used in keys
+func normalize(id string) string {
+	return strings.TrimPrefix(strings.ToLower(id), "0x")
+}
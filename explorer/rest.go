@@ -0,0 +1,68 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type groupReport struct {
+	Group
+	Reliability float64
+}
+
+//Handler serves the queries of the explorer as JSON: the last indexed block
+//at /, the summary and history of a node at /nodes/<address>, the working
+//groups at /groups, a group and its reliability at /groups/<id> and the
+//request latency percentiles at /latency
+func Handler(s *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		last, err := s.lastBlock()
+		reply(w, map[string]uint64{"LastBlock": last}, err)
+	})
+	mux.HandleFunc("/nodes/", func(w http.ResponseWriter, r *http.Request) {
+		n, history, err := s.Node(strings.TrimPrefix(r.URL.Path, "/nodes/"))
+		if err == nil && n == nil {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, map[string]interface{}{"Node": n, "History": history}, err)
+	})
+	mux.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		groups, err := s.WorkingGroups()
+		reply(w, groups, err)
+	})
+	mux.HandleFunc("/groups/", func(w http.ResponseWriter, r *http.Request) {
+		g, err := s.Group(strings.TrimPrefix(r.URL.Path, "/groups/"))
+		if err == nil && g == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var report *groupReport
+		if g != nil {
+			report = &groupReport{Group: *g, Reliability: g.Reliability()}
+		}
+		reply(w, report, err)
+	})
+	mux.HandleFunc("/latency", func(w http.ResponseWriter, r *http.Request) {
+		latency, err := s.Latency()
+		reply(w, latency, err)
+	})
+	return mux
+}
+
+func reply(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
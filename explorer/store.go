@@ -0,0 +1,101 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Keys are prefixed by the kind of record they hold. Node history keys end
+// with the block and log index so that they iterate in chain order. Event keys
+// mark the events already indexed, and the last block is the one up to which
+// every event is.
+const (
+	prefixGroup   = "g/"
+	prefixNode    = "n/"
+	prefixHistory = "h/"
+	prefixRequest = "r/"
+	prefixEvent   = "e/"
+	keyLastBlock  = "m/lastBlock"
+)
+
+//Store is the embedded LevelDB database an indexer writes to
+type Store struct {
+	db *ethdb.LDBDatabase
+}
+
+//OpenStore opens or creates the database in dir
+func OpenStore(dir string) (*Store, error) {
+	db, err := ethdb.NewLDBDatabase(dir, 16, 16)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+//Close closes the database
+func (s *Store) Close() {
+	s.db.Close()
+}
+
+func (s *Store) get(key string, v interface{}) (bool, error) {
+	if ok, err := s.db.Has([]byte(key)); err != nil || !ok {
+		return false, err
+	}
+	data, err := s.db.Get([]byte(key))
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s *Store) has(key string) (bool, error) {
+	return s.db.Has([]byte(key))
+}
+
+func (s *Store) put(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Put([]byte(key), data)
+}
+
+// each decodes every record under prefix in key order with decode
+func (s *Store) each(prefix string, decode func(data []byte) error) error {
+	it := s.db.NewIteratorWithPrefix([]byte(prefix))
+	defer it.Release()
+	for it.Next() {
+		if err := decode(it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+func (s *Store) lastBlock() (uint64, error) {
+	if ok, err := s.db.Has([]byte(keyLastBlock)); err != nil || !ok {
+		return 0, err
+	}
+	data, err := s.db.Get([]byte(keyLastBlock))
+	if err != nil || len(data) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func (s *Store) setLastBlock(block uint64) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, block)
+	return s.db.Put([]byte(keyLastBlock), data)
+}
+
+func historyKey(node string, block uint64, index uint) string {
+	return fmt.Sprintf("%s%s/%016x%08x", prefixHistory, node, block, index)
+}
+
+func eventKey(block uint64, index uint) string {
+	return fmt.Sprintf("%s%016x%08x", prefixEvent, block, index)
+}
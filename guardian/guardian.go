@@ -0,0 +1,237 @@
+//Package guardian sends the signals that keep a DOS network going: new system
+//random numbers, group formation and dissolution of expired groups. Every
+//pending node may send them and is paid a GuardianReward for the first one.
+package guardian
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+)
+
+const (
+	defaultMaxBackoff   = 8
+	defaultPollInterval = 15 * time.Second
+	// retryBlocks is how long a sent signal gets to change the contract state
+	// before it is sent again
+	retryBlocks = 20
+	callTimeout = time.Minute
+
+	randomGas    = 200000
+	formationGas = 1000000
+	dissolveGas  = 300000
+)
+
+//Chain is the part of onchain.ProxyAdapter a guardian uses
+type Chain interface {
+	CurrentBlock(ctx context.Context) (uint64, error)
+	IsPendingNode(ctx context.Context, id []byte) (bool, error)
+	GasPrice(ctx context.Context) (*big.Int, error)
+	FeeReport() []onchain.FeeStat
+
+	GroupToPick(ctx context.Context) (uint64, error)
+	GetWorkingGroupSize(ctx context.Context) (uint64, error)
+	LastUpdatedBlock(ctx context.Context) (uint64, error)
+	RefreshSystemRandomHardLimit(ctx context.Context) (uint64, error)
+	GroupSize(ctx context.Context) (uint64, error)
+	NumPendingNodes(ctx context.Context) (uint64, error)
+	NumPendingGroups(ctx context.Context) (uint64, error)
+	GetExpiredWorkingGroupSize(ctx context.Context) (uint64, error)
+
+	SignalRandom(ctx context.Context) error
+	SignalGroupFormation(ctx context.Context) error
+	SignalGroupDissolve(ctx context.Context) error
+}
+
+type signal struct {
+	name string
+	// gas is the gas used before the node sent the signal itself
+	gas uint64
+	// essential signals are sent even when the balance is low
+	essential bool
+	due       func(ctx context.Context, block uint64) (bool, error)
+	send      func(ctx context.Context) error
+
+	// seen is the block the signal was first due at, 0 while it is not due
+	seen    uint64
+	backoff uint64
+	sent    uint64
+}
+
+//Guardian looks at every new block for signals that are due and sends them
+//after a random backoff, so that the pending nodes rarely send the same one
+type Guardian struct {
+	chain             Chain
+	id                []byte
+	reward            *big.Int
+	maxBackoff        uint64
+	interval          time.Duration
+	allowNonEssential func() bool
+	rand              *rand.Rand
+	signals           []*signal
+	lastBlock         uint64
+	logger            log.Logger
+}
+
+//New creates a guardian for the node id. allowNonEssential reports whether
+//the node can afford the signals other nodes can send as well.
+func New(chain Chain, id []byte, config configuration.GuardianConfig, allowNonEssential func() bool) *Guardian {
+	g := &Guardian{
+		chain:             chain,
+		id:                id,
+		maxBackoff:        config.MaxBackoff,
+		interval:          time.Duration(config.PollInterval) * time.Second,
+		allowNonEssential: allowNonEssential,
+		rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger:            log.New("module", "Guardian"),
+	}
+	if g.maxBackoff == 0 {
+		g.maxBackoff = defaultMaxBackoff
+	}
+	if g.interval == 0 {
+		g.interval = defaultPollInterval
+	}
+	if config.Reward != 0 {
+		g.reward, _ = new(big.Float).Mul(big.NewFloat(config.Reward), big.NewFloat(1e18)).Int(nil)
+	}
+	g.signals = []*signal{
+		{name: "SignalRandom", gas: randomGas, due: g.randomDue, send: chain.SignalRandom},
+		{name: "SignalGroupFormation", gas: formationGas, essential: true, due: g.formationDue, send: chain.SignalGroupFormation},
+		{name: "SignalGroupDissolve", gas: dissolveGas, due: g.dissolveDue, send: chain.SignalGroupDissolve},
+	}
+	return g
+}
+
+//Run looks for a new block every few seconds until ctx is done
+func (g *Guardian) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+			block, err := g.chain.CurrentBlock(callCtx)
+			if err != nil {
+				g.logger.Error(err)
+			} else if block > g.lastBlock {
+				g.lastBlock = block
+				g.OnBlock(callCtx, block)
+			}
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//OnBlock evaluates every signal at block and sends those whose backoff is over
+func (g *Guardian) OnBlock(ctx context.Context, block uint64) {
+	if pending, err := g.chain.IsPendingNode(ctx, g.id); err != nil || !pending {
+		return
+	}
+	for _, s := range g.signals {
+		due, err := s.due(ctx, block)
+		if err != nil {
+			g.logger.Error(err)
+			continue
+		}
+		if !due {
+			s.seen = 0
+			continue
+		}
+		if s.seen == 0 {
+			s.seen = block
+			s.backoff = uint64(g.rand.Int63n(int64(g.maxBackoff) + 1))
+		}
+		if block < s.seen+s.backoff || (s.sent != 0 && block < s.sent+retryBlocks) {
+			continue
+		}
+		if !s.essential && g.allowNonEssential != nil && !g.allowNonEssential() {
+			g.logger.Event("GuardianPaused", map[string]interface{}{"Signal": s.name, "Block": block})
+			continue
+		}
+		if cost, ok := g.worth(ctx, s); !ok {
+			g.logger.Event("GuardianUnprofitable", map[string]interface{}{"Signal": s.name, "Cost": cost.String(), "Block": block})
+			continue
+		}
+		s.sent = block
+		if err := s.send(ctx); err != nil {
+			g.logger.Event("GuardianSignalError", map[string]interface{}{"Signal": s.name, "Error": err.Error(), "Block": block})
+			continue
+		}
+		g.logger.Event("GuardianSignal", map[string]interface{}{"Signal": s.name, "Block": block, "Waited": block - s.seen})
+	}
+}
+
+// worth returns the expected gas cost of s in wei, at the price per gas the
+// chain pays with its fee policy, and whether the reward covers it
+func (g *Guardian) worth(ctx context.Context, s *signal) (*big.Int, bool) {
+	if g.reward == nil {
+		return new(big.Int), true
+	}
+	price, err := g.chain.GasPrice(ctx)
+	if err != nil {
+		g.logger.Error(err)
+		return new(big.Int), false
+	}
+	gas := s.gas
+	for _, fee := range g.chain.FeeReport() {
+		if mined := fee.Confirmed + fee.Failed; fee.Request == s.name && mined > 0 && fee.GasUsed > 0 {
+			gas = fee.GasUsed / mined
+		}
+	}
+	cost := new(big.Int).Mul(price, new(big.Int).SetUint64(gas))
+	return cost, cost.Cmp(g.reward) <= 0
+}
+
+func (g *Guardian) randomDue(ctx context.Context, block uint64) (bool, error) {
+	groupToPick, err := g.chain.GroupToPick(ctx)
+	if err != nil {
+		return false, err
+	}
+	workingGroup, err := g.chain.GetWorkingGroupSize(ctx)
+	if err != nil {
+		return false, err
+	}
+	if workingGroup < groupToPick {
+		return false, nil
+	}
+	lastUpdatedBlock, err := g.chain.LastUpdatedBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	sysrandInterval, err := g.chain.RefreshSystemRandomHardLimit(ctx)
+	if err != nil {
+		return false, err
+	}
+	return block > lastUpdatedBlock && block-lastUpdatedBlock > sysrandInterval, nil
+}
+
+func (g *Guardian) formationDue(ctx context.Context, block uint64) (bool, error) {
+	groupSize, err := g.chain.GroupSize(ctx)
+	if err != nil {
+		return false, err
+	}
+	pendingNodeSize, err := g.chain.NumPendingNodes(ctx)
+	if err != nil {
+		return false, err
+	}
+	return groupSize > 0 && pendingNodeSize >= groupSize+(groupSize/2), nil
+}
+
+func (g *Guardian) dissolveDue(ctx context.Context, block uint64) (bool, error) {
+	pendingGroupSize, err := g.chain.NumPendingGroups(ctx)
+	if err != nil {
+		return false, err
+	}
+	expiredWGSize, err := g.chain.GetExpiredWorkingGroupSize(ctx)
+	if err != nil {
+		return false, err
+	}
+	return expiredWGSize > 0 || pendingGroupSize > 0, nil
+}
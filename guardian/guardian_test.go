@@ -0,0 +1,164 @@
+package guardian
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeChain is a proxy contract whose state the test sets directly
+type fakeChain struct {
+	pending          bool
+	gasPrice         *big.Int
+	fees             []onchain.FeeStat
+	groupToPick      uint64
+	workingGroups    uint64
+	lastUpdated      uint64
+	randomLimit      uint64
+	groupSize        uint64
+	pendingNodes     uint64
+	pendingGroups    uint64
+	expiredGroups    uint64
+	sent             []string
+	failSignalRandom bool
+}
+
+func (c *fakeChain) CurrentBlock(ctx context.Context) (uint64, error) { return 0, nil }
+func (c *fakeChain) IsPendingNode(ctx context.Context, id []byte) (bool, error) {
+	return c.pending, nil
+}
+func (c *fakeChain) GasPrice(ctx context.Context) (*big.Int, error)  { return c.gasPrice, nil }
+func (c *fakeChain) FeeReport() []onchain.FeeStat                    { return c.fees }
+func (c *fakeChain) GroupToPick(ctx context.Context) (uint64, error) { return c.groupToPick, nil }
+func (c *fakeChain) GetWorkingGroupSize(ctx context.Context) (uint64, error) {
+	return c.workingGroups, nil
+}
+func (c *fakeChain) LastUpdatedBlock(ctx context.Context) (uint64, error) { return c.lastUpdated, nil }
+func (c *fakeChain) RefreshSystemRandomHardLimit(ctx context.Context) (uint64, error) {
+	return c.randomLimit, nil
+}
+func (c *fakeChain) GroupSize(ctx context.Context) (uint64, error)       { return c.groupSize, nil }
+func (c *fakeChain) NumPendingNodes(ctx context.Context) (uint64, error) { return c.pendingNodes, nil }
+func (c *fakeChain) NumPendingGroups(ctx context.Context) (uint64, error) {
+	return c.pendingGroups, nil
+}
+func (c *fakeChain) GetExpiredWorkingGroupSize(ctx context.Context) (uint64, error) {
+	return c.expiredGroups, nil
+}
+func (c *fakeChain) SignalRandom(ctx context.Context) error {
+	c.sent = append(c.sent, "SignalRandom")
+	if c.failSignalRandom {
+		return errors.New("reverted")
+	}
+	c.lastUpdated = 1 << 32
+	return nil
+}
+func (c *fakeChain) SignalGroupFormation(ctx context.Context) error {
+	c.sent = append(c.sent, "SignalGroupFormation")
+	c.pendingNodes = 0
+	return nil
+}
+func (c *fakeChain) SignalGroupDissolve(ctx context.Context) error {
+	c.sent = append(c.sent, "SignalGroupDissolve")
+	c.expiredGroups = 0
+	return nil
+}
+
+func (c *fakeChain) take() []string {
+	sent := c.sent
+	c.sent = nil
+	return sent
+}
+
+func TestGuardianSignals(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	ctx := context.Background()
+	chain := &fakeChain{pending: true, gasPrice: big.NewInt(1), groupToPick: 2, workingGroups: 2, lastUpdated: 100, randomLimit: 10, groupSize: 3}
+	lowBalance := false
+	g := New(chain, []byte{1}, configuration.GuardianConfig{MaxBackoff: 4}, func() bool { return !lowBalance })
+	g.rand = rand.New(rand.NewSource(1))
+
+	g.OnBlock(ctx, 105)
+	if sent := chain.take(); len(sent) != 0 {
+		t.Fatalf("sent %v with nothing due", sent)
+	}
+
+	// Every due signal is sent within the backoff window, each once
+	chain.pendingNodes, chain.expiredGroups = 5, 1
+	for block := uint64(111); block <= 111+4; block++ {
+		g.OnBlock(ctx, block)
+	}
+	sent := chain.take()
+	if len(sent) != 3 {
+		t.Fatalf("sent %v, want all three signals", sent)
+	}
+
+	// Nodes that are not pending are no guardians
+	chain.pending, chain.expiredGroups = false, 1
+	for block := uint64(200); block <= 210; block++ {
+		g.OnBlock(ctx, block)
+	}
+	if sent := chain.take(); len(sent) != 0 {
+		t.Errorf("node that is not pending sent %v", sent)
+	}
+
+	// A low balance only lets essential signals through
+	chain.pending, lowBalance = true, true
+	chain.pendingNodes = 5
+	for block := uint64(300); block <= 310; block++ {
+		g.OnBlock(ctx, block)
+	}
+	if sent := chain.take(); len(sent) != 1 || sent[0] != "SignalGroupFormation" {
+		t.Errorf("sent %v with a low balance, want SignalGroupFormation", sent)
+	}
+	lowBalance = false
+	chain.expiredGroups = 0
+
+	// A failed signal is retried after retryBlocks
+	chain.lastUpdated, chain.failSignalRandom = 400, true
+	block := uint64(420)
+	for ; len(chain.sent) == 0 && block <= 420+4; block++ {
+		g.OnBlock(ctx, block)
+	}
+	if sent := chain.take(); len(sent) != 1 {
+		t.Fatalf("sent %v, want SignalRandom", sent)
+	}
+	failed := block - 1
+	for ; block < failed+retryBlocks; block++ {
+		g.OnBlock(ctx, block)
+	}
+	if sent := chain.take(); len(sent) != 0 {
+		t.Errorf("failed signal sent again before retryBlocks: %v", sent)
+	}
+	g.OnBlock(ctx, failed+retryBlocks)
+	if sent := chain.take(); len(sent) != 1 {
+		t.Errorf("sent %v, want the retried SignalRandom", sent)
+	}
+}
+
+func TestGuardianReward(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	ctx := context.Background()
+	chain := &fakeChain{pending: true, gasPrice: big.NewInt(10000000000), groupToPick: 1, expiredGroups: 1}
+	// 300000 gas at 10 gwei costs 0.003 ether
+	g := New(chain, []byte{1}, configuration.GuardianConfig{Reward: 0.002, MaxBackoff: 1}, nil)
+	g.OnBlock(ctx, 1)
+	g.OnBlock(ctx, 2)
+	if sent := chain.take(); len(sent) != 0 {
+		t.Errorf("unprofitable signal sent: %v", sent)
+	}
+
+	// Cheaper signals seen in the fee report make it worth it
+	chain.fees = []onchain.FeeStat{{Request: "SignalGroupDissolve", Confirmed: 2, GasUsed: 300000}}
+	g.OnBlock(ctx, 3)
+	if sent := chain.take(); len(sent) != 1 || sent[0] != "SignalGroupDissolve" {
+		t.Errorf("sent %v, want SignalGroupDissolve", sent)
+	}
+}
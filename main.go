@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -11,10 +12,16 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/DOSNetwork/core/configuration"
 	"github.com/DOSNetwork/core/dosnode"
+	"github.com/DOSNetwork/core/explorer"
+	"github.com/DOSNetwork/core/observer"
 	"github.com/DOSNetwork/core/onchain"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -24,6 +31,12 @@ const pidFile string = "./vault/dosclient.pid"
 const logFile string = "./vault/doslog.txt"
 const walletPath string = "./vault"
 
+// An external signer holds the node key instead of the wallet when SIGNER is
+// set to its http(s) url or unix socket. SIGNERACCOUNT picks the account when
+// the signer holds several.
+const envSigner string = "SIGNER"
+const envSignerAccount string = "SIGNERACCOUNT"
+
 func savePID(pid int) {
 
 	file, err := os.Create(pidFile)
@@ -44,24 +57,45 @@ func savePID(pid int) {
 
 }
 
+// dialSigner connects to the external signer at SIGNER
+func dialSigner(endpoint string) (onchain.Signer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return onchain.DialSigner(ctx, endpoint, os.Getenv(envSignerAccount))
+}
+
 func runDos() {
 	defer os.Remove(pidFile)
-	// check if there is a account
-	n := onchain.NumOfAccounts(walletPath)
-	if n == 0 {
-		fmt.Println("Please run 'client wallet create' to create a new wallet for the node")
-		os.Exit(1)
-	}
-	// check if password is in env variable
-	password := os.Getenv("PASSPHRASE")
-	if len(password) == 0 {
-		fmt.Println("Please run 'client start' to start a client daemon and provide a password")
-		return
-	}
-	key, err := onchain.ReadEthKey(walletPath, password)
-	if err != nil {
-		fmt.Println("Error : ", err)
-		return
+	observe := isObserver()
+	var signer onchain.Signer
+	if endpoint := os.Getenv(envSigner); observe {
+		// An observer sends no transactions and needs no key
+	} else if endpoint != "" {
+		var err error
+		signer, err = dialSigner(endpoint)
+		if err != nil {
+			fmt.Println("Error : ", err)
+			return
+		}
+	} else {
+		// check if there is a account
+		n := onchain.NumOfAccounts(walletPath)
+		if n == 0 {
+			fmt.Println("Please run 'client wallet create' to create a new wallet for the node")
+			os.Exit(1)
+		}
+		// check if password is in env variable
+		password := os.Getenv("PASSPHRASE")
+		if len(password) == 0 {
+			fmt.Println("Please run 'client start' to start a client daemon and provide a password")
+			return
+		}
+		key, err := onchain.ReadEthKey(walletPath, password)
+		if err != nil {
+			fmt.Println("Error : ", err)
+			return
+		}
+		signer = onchain.NewKeySigner(key.PrivateKey)
 	}
 
 	// Make arrangement to remove PID file upon receiving the SIGTERM from kill command
@@ -84,7 +118,12 @@ func runDos() {
 	syscall.Dup2(int(fErr.Fd()), 1) /* -- stdout */
 	syscall.Dup2(int(fErr.Fd()), 2) /* -- stderr */
 
-	dosclient, err := dosnode.NewDosNode(key)
+	if observe {
+		runObserver()
+		return
+	}
+
+	dosclient, err := dosnode.NewDosNode(signer)
 	if err != nil {
 		fmt.Println(" err", err)
 		return
@@ -92,6 +131,45 @@ func runDos() {
 	dosclient.Start()
 }
 
+// isObserver reports whether NODEROLE or the config make the client an observer
+func isObserver() bool {
+	config := configuration.Config{}
+	return config.LoadConfig() == nil && config.NodeRole == configuration.NodeRoleObserver
+}
+
+// readOnlyChain connects to the chain of the config. The adaptor gets a
+// throwaway key as its callers never sign anything.
+func readOnlyChain() (onchain.ProxyAdapter, error) {
+	config := configuration.Config{}
+	if err := config.LoadConfig(); err != nil {
+		return nil, err
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	chain, err := onchain.NewProxyAdapter(config.GetCurrentType(), onchain.NewKeySigner(key), config.GetChainConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.Start(); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// runObserver follows the network and serves what it sees on the REST port
+func runObserver() {
+	chain, err := readOnlyChain()
+	if err != nil {
+		fmt.Println("Error : ", err)
+		return
+	}
+	o := observer.New(chain)
+	go http.ListenAndServe(":8080", o.Handler())
+	o.Run(context.Background())
+}
+
 func makeRequest(f string) ([]byte, error) {
 
 	tServer := "http://localhost:8080/" + f
@@ -149,17 +227,27 @@ func actionStart(c *cli.Context) error {
 		fmt.Println("Already running or ${PWD}/dosclient.pid file exist.")
 		os.Exit(1)
 	}
-	// check if there is a account
-	n := onchain.NumOfAccounts(walletPath)
-	if n == 0 {
-		fmt.Println("Please run 'client wallet create' to create a new wallet for the node")
-		os.Exit(1)
-	}
+	if isObserver() {
+		// an observer needs neither a wallet nor a signer
+	} else if endpoint := os.Getenv(envSigner); endpoint != "" {
+		// check if the signer is reachable before starting the daemon
+		if _, err := dialSigner(endpoint); err != nil {
+			fmt.Println("Signer error : ", err)
+			os.Exit(1)
+		}
+	} else {
+		// check if there is a account
+		n := onchain.NumOfAccounts(walletPath)
+		if n == 0 {
+			fmt.Println("Please run 'client wallet create' to create a new wallet for the node")
+			os.Exit(1)
+		}
 
-	// check if password is in env variable
-	password := os.Getenv("PASSPHRASE")
-	if len(password) == 0 {
-		os.Setenv("PASSPHRASE", getPassword("Enter Password: "))
+		// check if password is in env variable
+		password := os.Getenv("PASSPHRASE")
+		if len(password) == 0 {
+			os.Setenv("PASSPHRASE", getPassword("Enter Password: "))
+		}
 	}
 
 	cmd := exec.Command(os.Args[0], "run")
@@ -253,30 +341,177 @@ func actionRnadom(c *cli.Context) error {
 	return err
 }
 
-func actionCreateWallet(c *cli.Context) error {
-
-	first := getPassword("Generating node wallet...\nEnter passphrase (empty is not allowed): ")
+// getNewPassword asks for a new passphrase twice
+func getNewPassword(s string) (p string, err error) {
+	first := getPassword(s)
 	second := getPassword("Confirm passphrase again: ")
 	if first != second {
 		fmt.Println("Unmatched Password")
-		return errors.New("Unmatched Password\n")
+		return "", errors.New("Unmatched Password\n")
 	}
-	err := onchain.GenEthkey(walletPath, first)
+	return first, nil
+}
+
+func actionCreateWallet(c *cli.Context) error {
+	first, err := getNewPassword("Generating node wallet...\nEnter passphrase (empty is not allowed): ")
+	if err != nil {
+		return err
+	}
+	addr, err := onchain.GenEthkey(walletPath, first)
 	if err != nil {
 		fmt.Println("GenEthkey error : ", err)
-	} else {
-		key, err := onchain.ReadEthKey(walletPath, first)
-		if err != nil {
+		return err
+	}
+	fmt.Println("wallet keystore file has been saved under", walletPath)
+	printNewAccount(addr)
+	return nil
+}
+
+// printNewAccount tells whether a key just added to the wallet is the node key
+func printNewAccount(addr common.Address) {
+	accounts := onchain.WalletAccounts(walletPath)
+	if len(accounts) == 0 || accounts[0].Address == addr {
+		fmt.Println("Your node wallet address is:", addr.Hex())
+		return
+	}
+	fmt.Println("Added wallet address", addr.Hex(), "; the node keeps using", accounts[0].Address.Hex())
+}
+
+func actionImportWallet(c *cli.Context) (err error) {
+	var key []byte
+	passphrase := ""
+	if path := c.String("file"); path != "" {
+		if key, err = ioutil.ReadFile(path); err != nil {
 			fmt.Println("Error :", err)
-			return err
+			return
+		}
+		if strings.HasPrefix(strings.TrimSpace(string(key)), "{") {
+			passphrase = getPassword("Enter passphrase of the keystore file: ")
+		}
+	} else {
+		key = []byte(getPassword("Enter private key (hex): "))
+	}
+	newPassphrase, err := getNewPassword("Enter passphrase for the wallet (empty is not allowed): ")
+	if err != nil {
+		return
+	}
+	addr, err := onchain.ImportEthKey(walletPath, key, passphrase, newPassphrase)
+	if err != nil {
+		fmt.Println("Import error : ", err)
+		return
+	}
+	fmt.Println("wallet keystore file has been saved under", walletPath)
+	printNewAccount(addr)
+	return nil
+}
+
+func actionExportWallet(c *cli.Context) error {
+	passphrase := getPassword("Enter passphrase: ")
+	newPassphrase, err := getNewPassword("Enter passphrase for the exported keystore file (empty is not allowed): ")
+	if err != nil {
+		return err
+	}
+	keyJSON, err := onchain.ExportEthKey(walletPath, c.String("address"), passphrase, newPassphrase)
+	if err != nil {
+		fmt.Println("Export error : ", err)
+		return err
+	}
+	out := c.String("out")
+	if out == "" {
+		fmt.Println(string(keyJSON))
+		return nil
+	}
+	if err := ioutil.WriteFile(out, keyJSON, 0600); err != nil {
+		fmt.Println("Export error : ", err)
+		return err
+	}
+	fmt.Println("keystore file has been saved to", out)
+	return nil
+}
+
+func actionListWallet(c *cli.Context) error {
+	accounts := onchain.WalletAccounts(walletPath)
+	if len(accounts) == 0 {
+		fmt.Println("Please run 'client wallet create' or 'client wallet import' to add a wallet for the node")
+		return nil
+	}
+	for i, account := range accounts {
+		role := ""
+		if i == 0 {
+			role = " (node)"
 		}
-		fmt.Println("wallet keystore file has been saved under", walletPath)
-		fmt.Println("Your node wallet address is:", fmt.Sprintf("0x%x", key.Address))
+		fmt.Printf("%d: %s%s %s\n", i, account.Address.Hex(), role, account.URL.Path)
+	}
+	return nil
+}
+
+func actionPasswdWallet(c *cli.Context) error {
+	passphrase := getPassword("Enter current passphrase: ")
+	newPassphrase, err := getNewPassword("Enter new passphrase (empty is not allowed): ")
+	if err != nil {
+		return err
 	}
+	if err := onchain.ChangeEthKeyPassphrase(walletPath, c.String("address"), passphrase, newPassphrase); err != nil {
+		fmt.Println("Error : ", err)
+		return err
+	}
+	fmt.Println("passphrase has been changed")
 	return nil
 }
 
+// walletAddress returns the address given with --address, or the node
+// account of the external signer or of the wallet
+func walletAddress(c *cli.Context) (addr common.Address, err error) {
+	if address := c.String("address"); address != "" {
+		if !common.IsHexAddress(address) {
+			return addr, fmt.Errorf("invalid address %s", address)
+		}
+		return common.HexToAddress(address), nil
+	}
+	if endpoint := os.Getenv(envSigner); endpoint != "" {
+		signer, err := dialSigner(endpoint)
+		if err != nil {
+			return addr, err
+		}
+		return signer.Address(), nil
+	}
+	accounts := onchain.WalletAccounts(walletPath)
+	if len(accounts) == 0 {
+		return addr, errors.New("No Account")
+	}
+	return accounts[0].Address, nil
+}
+
+// accountStatus reads the status of the account from the chain, without
+// the client daemon
+func accountStatus(c *cli.Context) (*onchain.AccountStatus, error) {
+	addr, err := walletAddress(c)
+	if err != nil {
+		return nil, err
+	}
+	config := configuration.Config{}
+	if err := config.LoadConfig(); err != nil {
+		return nil, err
+	}
+	if config.GetCurrentType() != onchain.ETH {
+		return nil, fmt.Errorf("wallet info is not supported on %s chains", config.GetCurrentType())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return onchain.GetAccountStatus(ctx, config.GetChainConfig(), addr)
+}
+
 func actionWalletInfo(c *cli.Context) error {
+	status, err := accountStatus(c)
+	if err != nil {
+		fmt.Println("Error : ", err)
+		return err
+	}
+	fmt.Println("Address      :", status.Address.Hex())
+	fmt.Println("ETH balance  :", status.Balance.Text('f', 6))
+	fmt.Println("DOS balance  :", status.TokenBalance.Text('f', 6))
+	fmt.Println("Staking      :", status.Staking, "( minimum stake", status.MinStake, "DOS )")
+	fmt.Println("Pending node :", status.PendingNode)
 	return nil
 }
 
@@ -286,7 +521,47 @@ func actionWalletBalance(c *cli.Context) error {
 		fmt.Println("show balance: ", string(r))
 		return nil
 	}
-	return err
+	// The daemon is not running, ask the chain
+	status, err := accountStatus(c)
+	if err != nil {
+		fmt.Println("Error : ", err)
+		return err
+	}
+	fmt.Println("show balance: ", status.Balance.Text('f', 6))
+	return nil
+}
+
+// actionExplorer indexes the network events into the database and serves
+// queries on them until it is interrupted
+func actionExplorer(c *cli.Context) error {
+	store, err := explorer.OpenStore(c.String("db"))
+	if err != nil {
+		fmt.Println("Error : ", err)
+		return err
+	}
+	defer store.Close()
+	chain, err := readOnlyChain()
+	if err != nil {
+		fmt.Println("Error : ", err)
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ch
+		cancel()
+	}()
+	server := &http.Server{Addr: c.String("addr"), Handler: explorer.Handler(store)}
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			fmt.Println("Error : ", err)
+			cancel()
+		}
+	}()
+	explorer.NewIndexer(chain, store).Run(ctx)
+	server.Close()
+	return nil
 }
 
 // main
@@ -346,24 +621,69 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "explorer",
+			Usage:  "index network events and serve queries on them",
+			Action: actionExplorer,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "db", Value: "./explorer", Usage: "directory of the index database"},
+				cli.StringFlag{Name: "addr", Value: ":8090", Usage: "address to serve the query API on"},
+			},
+		},
 		{
 			Name:  "wallet",
 			Usage: "Manage Node Wallet",
 			Subcommands: []cli.Command{
 				{
 					Name:   "create",
-					Usage:  "show wallet status",
+					Usage:  "create a new wallet key",
 					Action: actionCreateWallet,
 				},
+				{
+					Name:   "import",
+					Usage:  "import a private key or a keystore file",
+					Action: actionImportWallet,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file", Usage: "file with a hex private key or keystore JSON, the private key is asked for if omitted"},
+					},
+				},
+				{
+					Name:   "export",
+					Usage:  "export a wallet key as a keystore file",
+					Action: actionExportWallet,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "address", Usage: "account to export, the node account if omitted"},
+						cli.StringFlag{Name: "out", Usage: "file to write, stdout if omitted"},
+					},
+				},
+				{
+					Name:   "list",
+					Usage:  "list wallet keys",
+					Action: actionListWallet,
+				},
+				{
+					Name:   "passwd",
+					Usage:  "change the passphrase of a wallet key",
+					Action: actionPasswdWallet,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "address", Usage: "account to change, the node account if omitted"},
+					},
+				},
 				{
 					Name:   "balance",
 					Usage:  "show wallet balance",
 					Action: actionWalletBalance,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "address", Usage: "account to show when the client is not running, the node account if omitted"},
+					},
 				},
 				{
 					Name:   "info",
-					Usage:  "show wallet info",
+					Usage:  "show address, balances, stake and pending node status",
 					Action: actionWalletInfo,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "address", Usage: "account to show, the node account if omitted"},
+					},
 				},
 			},
 		},
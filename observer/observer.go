@@ -0,0 +1,304 @@
+//Package observer follows a DOS network from its contract events without
+//taking part in it. It never registers a node, joins a group or sends a
+//transaction, so it needs neither a funded account nor peers.
+package observer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+)
+
+// maxRecentRequests is the number of fulfilled requests kept
+const maxRecentRequests = 1000
+
+//Chain is the part of onchain.ProxyAdapter an observer uses
+type Chain interface {
+	onchain.EventSource
+	CurrentBlock(ctx context.Context) (uint64, error)
+	GetWorkingGroupSize(ctx context.Context) (uint64, error)
+	NumPendingGroups(ctx context.Context) (uint64, error)
+	NumPendingNodes(ctx context.Context) (uint64, error)
+}
+
+//Group is a group as far as the observer has seen it
+type Group struct {
+	ID      string
+	Members []string
+	// FormedAt is the block of its LogGrouping
+	FormedAt uint64
+	// AcceptedAt is the block its public key was accepted at, 0 while the
+	// group is pending
+	AcceptedAt uint64
+	// DissolvedAt is the block the group was dissolved or removed at
+	DissolvedAt uint64
+	Requests    uint64
+}
+
+//Request is a request dispatched to a group
+type Request struct {
+	ID          string
+	Type        string
+	GroupID     string
+	RequestedAt uint64
+	FulfilledAt uint64
+	// Latency is the time between the request and its result
+	Latency time.Duration
+	// Rejected counts results that failed validation
+	Rejected  uint64
+	requested time.Time
+}
+
+//CommitReveal is a commit-reveal process
+type CommitReveal struct {
+	Cid        string
+	StartBlock uint64
+	Commits    uint64
+	Reveals    uint64
+	Random     string
+	Failed     bool
+}
+
+//Stats sums up the network as seen from the events
+type Stats struct {
+	LastBlock     uint64
+	WorkingGroups int
+	PendingGroups int
+	PendingNodes  int
+	Requests      uint64
+	Fulfilled     uint64
+	Open          int
+	// AvgLatency and AvgBlocks are the mean time and blocks to fulfill a
+	// request
+	AvgLatency time.Duration
+	AvgBlocks  float64
+}
+
+//Observer keeps the state of a network up to date from its events
+type Observer struct {
+	chain  Chain
+	logger log.Logger
+	now    func() time.Time
+
+	lock          sync.Mutex
+	groups        map[string]*Group
+	pendingNodes  map[string]uint64
+	requests      map[string]*Request
+	recent        []*Request
+	commitReveals map[string]*CommitReveal
+	lastBlock     uint64
+	total         uint64
+	fulfilled     uint64
+	latency       time.Duration
+	blocks        uint64
+}
+
+//New creates an observer of the events of chain
+func New(chain Chain) *Observer {
+	return &Observer{
+		chain:         chain,
+		logger:        log.New("module", "Observer"),
+		now:           time.Now,
+		groups:        make(map[string]*Group),
+		pendingNodes:  make(map[string]uint64),
+		requests:      make(map[string]*Request),
+		commitReveals: make(map[string]*CommitReveal),
+	}
+}
+
+//Run follows the events of every DOSProxy and CommitReveal type until ctx is
+//done, reconnecting when the subscription fails
+func (o *Observer) Run(ctx context.Context) {
+	onchain.Follow(ctx, o.chain, o.logger, o.Handle, nil)
+}
+
+//Handle updates the state with one event
+func (o *Observer) Handle(event *onchain.LogCommon) {
+	if event.Removed {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if event.BlockN > o.lastBlock {
+		o.lastBlock = event.BlockN
+	}
+	block := event.BlockN
+	switch content := event.Event.(type) {
+	case *onchain.LogRegisteredNewPendingNode:
+		o.pendingNodes[fmt.Sprintf("%x", content.Node)] = block
+	case *onchain.LogGrouping:
+		g := &Group{ID: onchain.HexID(content.GroupId), FormedAt: block}
+		for _, id := range content.NodeId {
+			member := fmt.Sprintf("%x", id)
+			g.Members = append(g.Members, member)
+			delete(o.pendingNodes, member)
+		}
+		o.groups[g.ID] = g
+	case *onchain.LogPublicKeyAccepted:
+		if g, ok := o.groups[onchain.HexID(content.GroupId)]; ok {
+			g.AcceptedAt = block
+		} else {
+			o.groups[onchain.HexID(content.GroupId)] = &Group{ID: onchain.HexID(content.GroupId), AcceptedAt: block}
+		}
+	case *onchain.LogGroupDissolve:
+		o.dissolve(onchain.HexID(content.GroupId), block)
+	case *onchain.LogPendingGroupRemoved:
+		o.dissolve(onchain.HexID(content.GroupId), block)
+	case *onchain.LogUpdateRandom:
+		o.request(onchain.TrafficSystemRandom, content.LastRandomness, content.DispatchedGroupId, block)
+	case *onchain.LogRequestUserRandom:
+		o.request(onchain.TrafficUserRandom, content.RequestId, content.DispatchedGroupId, block)
+	case *onchain.LogUrl:
+		o.request(onchain.TrafficUserQuery, content.QueryId, content.DispatchedGroupId, block)
+	case *onchain.LogValidationResult:
+		o.result(content, block)
+	case *onchain.LogStartCommitReveal:
+		o.commitReveals[onchain.HexID(content.Cid)] = &CommitReveal{Cid: onchain.HexID(content.Cid), StartBlock: content.StartBlock.Uint64()}
+	case *onchain.LogCommit:
+		if cr, ok := o.commitReveals[onchain.HexID(content.Cid)]; ok {
+			cr.Commits++
+		}
+	case *onchain.LogReveal:
+		if cr, ok := o.commitReveals[onchain.HexID(content.Cid)]; ok {
+			cr.Reveals++
+		}
+	case *onchain.LogRandom:
+		if cr, ok := o.commitReveals[onchain.HexID(content.Cid)]; ok {
+			cr.Random = onchain.HexID(content.Random)
+		}
+	case *onchain.LogRandomFailure:
+		if cr, ok := o.commitReveals[onchain.HexID(content.Cid)]; ok {
+			cr.Failed = true
+		}
+	}
+}
+
+// dissolve marks a group dissolved and returns its members to the pending
+// nodes, as the contract does
+func (o *Observer) dissolve(id string, block uint64) {
+	g, ok := o.groups[id]
+	if !ok {
+		return
+	}
+	g.DissolvedAt = block
+	for _, member := range g.Members {
+		o.pendingNodes[member] = block
+	}
+}
+
+func (o *Observer) request(trafficType uint8, id, groupID *big.Int, block uint64) {
+	r := &Request{ID: onchain.HexID(id), Type: onchain.TrafficNames[trafficType], GroupID: onchain.HexID(groupID), RequestedAt: block, requested: o.now()}
+	key := onchain.RequestKey(trafficType, id)
+	if _, ok := o.requests[key]; ok {
+		return
+	}
+	o.requests[key] = r
+	o.total++
+	if g, ok := o.groups[r.GroupID]; ok {
+		g.Requests++
+	}
+}
+
+func (o *Observer) result(v *onchain.LogValidationResult, block uint64) {
+	key := onchain.RequestKey(v.TrafficType, v.TrafficId)
+	r, ok := o.requests[key]
+	if !ok {
+		return
+	}
+	if !v.Pass {
+		r.Rejected++
+		return
+	}
+	delete(o.requests, key)
+	r.FulfilledAt = block
+	r.Latency = o.now().Sub(r.requested)
+	o.fulfilled++
+	o.latency += r.Latency
+	if block > r.RequestedAt {
+		o.blocks += block - r.RequestedAt
+	}
+	o.recent = append(o.recent, r)
+	if len(o.recent) > maxRecentRequests {
+		o.recent = o.recent[len(o.recent)-maxRecentRequests:]
+	}
+	o.logger.Event("ObservedFulfillment", map[string]interface{}{"RequestId": r.ID, "Type": r.Type, "GroupID": r.GroupID, "Blocks": block - r.RequestedAt, "Latency": r.Latency.Seconds()})
+}
+
+//Stats returns the summary of the observed network
+func (o *Observer) Stats() Stats {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	s := Stats{LastBlock: o.lastBlock, PendingNodes: len(o.pendingNodes), Requests: o.total, Fulfilled: o.fulfilled, Open: len(o.requests)}
+	for _, g := range o.groups {
+		switch {
+		case g.DissolvedAt != 0:
+		case g.AcceptedAt != 0:
+			s.WorkingGroups++
+		default:
+			s.PendingGroups++
+		}
+	}
+	if o.fulfilled > 0 {
+		s.AvgLatency = o.latency / time.Duration(o.fulfilled)
+		s.AvgBlocks = float64(o.blocks) / float64(o.fulfilled)
+	}
+	return s
+}
+
+//Groups returns the groups that were not dissolved, oldest first
+func (o *Observer) Groups() []Group {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	var groups []Group
+	for _, g := range o.groups {
+		if g.DissolvedAt == 0 {
+			groups = append(groups, *g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].FormedAt < groups[j].FormedAt })
+	return groups
+}
+
+//PendingNodes returns the nodes waiting for a group
+func (o *Observer) PendingNodes() []string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	nodes := make([]string, 0, len(o.pendingNodes))
+	for node := range o.pendingNodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+//Requests returns the open requests and the fulfilled ones kept, oldest first
+func (o *Observer) Requests() (open, fulfilled []Request) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for _, r := range o.requests {
+		open = append(open, *r)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].RequestedAt < open[j].RequestedAt })
+	for _, r := range o.recent {
+		fulfilled = append(fulfilled, *r)
+	}
+	return
+}
+
+//CommitReveals returns the commit-reveal processes, oldest first
+func (o *Observer) CommitReveals() []CommitReveal {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	var crs []CommitReveal
+	for _, cr := range o.commitReveals {
+		crs = append(crs, *cr)
+	}
+	sort.Slice(crs, func(i, j int) bool { return crs[i].StartBlock < crs[j].StartBlock })
+	return crs
+}
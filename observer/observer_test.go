@@ -0,0 +1,99 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeChain struct{}
+
+func (fakeChain) SubscribeEvent(chanBuffer int, filter onchain.EventFilter, subscribeTypes ...int) (*onchain.Subscription, error) {
+	return nil, context.Canceled
+}
+func (fakeChain) Reconnect() error                                        { return nil }
+func (fakeChain) CurrentBlock(ctx context.Context) (uint64, error)        { return 42, nil }
+func (fakeChain) GetWorkingGroupSize(ctx context.Context) (uint64, error) { return 1, nil }
+func (fakeChain) NumPendingGroups(ctx context.Context) (uint64, error)    { return 0, nil }
+func (fakeChain) NumPendingNodes(ctx context.Context) (uint64, error)     { return 1, nil }
+
+func event(block uint64, e interface{}) *onchain.LogCommon {
+	return &onchain.LogCommon{BlockN: block, Event: e}
+}
+
+func TestObserver(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	o := New(fakeChain{})
+	now := time.Unix(1000, 0)
+	o.now = func() time.Time { return now }
+
+	for _, node := range []byte{1, 2, 3} {
+		o.Handle(event(1, &onchain.LogRegisteredNewPendingNode{Node: []byte{node}}))
+	}
+	o.Handle(event(2, &onchain.LogGrouping{GroupId: big.NewInt(10), NodeId: [][]byte{{1}, {2}}}))
+	if s := o.Stats(); s.PendingGroups != 1 || s.PendingNodes != 1 {
+		t.Fatalf("%d pending groups and %d pending nodes, want 1 and 1", s.PendingGroups, s.PendingNodes)
+	}
+	o.Handle(event(3, &onchain.LogPublicKeyAccepted{GroupId: big.NewInt(10)}))
+
+	o.Handle(event(4, &onchain.LogUrl{QueryId: big.NewInt(5), DispatchedGroupId: big.NewInt(10)}))
+	o.Handle(event(4, &onchain.LogRequestUserRandom{RequestId: big.NewInt(5), DispatchedGroupId: big.NewInt(10)}))
+	now = now.Add(6 * time.Second)
+	// A result that fails validation leaves the request open
+	o.Handle(event(5, &onchain.LogValidationResult{TrafficType: onchain.TrafficUserQuery, TrafficId: big.NewInt(5)}))
+	o.Handle(event(6, &onchain.LogValidationResult{TrafficType: onchain.TrafficUserQuery, TrafficId: big.NewInt(5), Pass: true}))
+
+	s := o.Stats()
+	if s.WorkingGroups != 1 || s.Requests != 2 || s.Fulfilled != 1 || s.Open != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.AvgLatency != 6*time.Second || s.AvgBlocks != 2 {
+		t.Errorf("latency %v over %.1f blocks, want 6s over 2", s.AvgLatency, s.AvgBlocks)
+	}
+	open, fulfilled := o.Requests()
+	if len(open) != 1 || open[0].Type != "UserRandom" || len(fulfilled) != 1 || fulfilled[0].Rejected != 1 {
+		t.Errorf("unexpected requests open %+v fulfilled %+v", open, fulfilled)
+	}
+	if groups := o.Groups(); len(groups) != 1 || groups[0].Requests != 2 {
+		t.Errorf("unexpected groups %+v", groups)
+	}
+
+	// The members of a dissolved group are pending again
+	o.Handle(event(7, &onchain.LogGroupDissolve{GroupId: big.NewInt(10)}))
+	if s := o.Stats(); s.WorkingGroups != 0 || s.PendingNodes != 3 {
+		t.Errorf("%d working groups and %d pending nodes after dissolve, want 0 and 3", s.WorkingGroups, s.PendingNodes)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	o := New(fakeChain{})
+	o.Handle(event(1, &onchain.LogStartCommitReveal{Cid: big.NewInt(1), StartBlock: big.NewInt(5)}))
+	o.Handle(event(2, &onchain.LogCommit{Cid: big.NewInt(1)}))
+	o.Handle(event(3, &onchain.LogRandom{Cid: big.NewInt(1), Random: big.NewInt(255)}))
+
+	server := httptest.NewServer(o.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/commitreveal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var crs []CommitReveal
+	if err := json.NewDecoder(resp.Body).Decode(&crs); err != nil {
+		t.Fatal(err)
+	}
+	if len(crs) != 1 || crs[0].Commits != 1 || crs[0].Random != "ff" {
+		t.Errorf("unexpected commit-reveals %+v", crs)
+	}
+	if resp, err := server.Client().Get(server.URL + "/missing"); err != nil || resp.StatusCode != 404 {
+		t.Errorf("unknown path served: %v", err)
+	}
+}
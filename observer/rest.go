@@ -0,0 +1,80 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//Handler serves the observed state: a status page at / and JSON at /groups,
+///nodes, /requests and /commitreveal
+func (o *Observer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", o.status)
+	mux.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, o.Groups())
+	})
+	mux.HandleFunc("/nodes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, o.PendingNodes())
+	})
+	mux.HandleFunc("/requests", func(w http.ResponseWriter, r *http.Request) {
+		open, fulfilled := o.Requests()
+		writeJSON(w, map[string]interface{}{"Open": open, "Fulfilled": fulfilled})
+	})
+	mux.HandleFunc("/commitreveal", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, o.CommitReveals())
+	})
+	return mux
+}
+
+func (o *Observer) status(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(3*time.Second))
+	defer cancelFunc()
+	s := o.Stats()
+	html := "=================================================" + "\n|"
+	html = html + "Role              : observer" + "\n|"
+	html = html + "LastEventBlock    : " + strconv.FormatUint(s.LastBlock, 10) + "\n|"
+	html = html + "WorkingGroups     : " + strconv.Itoa(s.WorkingGroups) + "\n|"
+	html = html + "PendingGroups     : " + strconv.Itoa(s.PendingGroups) + "\n|"
+	html = html + "PendingNodes      : " + strconv.Itoa(s.PendingNodes) + "\n|"
+	html = html + "Requests          : " + strconv.FormatUint(s.Requests, 10) + "\n|"
+	html = html + "Fulfilled / Open  : " + strconv.FormatUint(s.Fulfilled, 10) + " / " + strconv.Itoa(s.Open) + "\n|"
+	html = html + "AvgLatency        : " + s.AvgLatency.String() + " (" + strconv.FormatFloat(s.AvgBlocks, 'f', 1, 64) + " blocks)" + "\n"
+	html = html + "=================================================" + "\n|"
+	// The contract counts include what happened before the observer started
+	if n, err := o.chain.GetWorkingGroupSize(ctx); err != nil {
+		html = html + "WorkingGroupSize  : " + err.Error() + "\n|"
+	} else {
+		html = html + "WorkingGroupSize  : " + strconv.FormatUint(n, 10) + "\n|"
+	}
+	if n, err := o.chain.NumPendingGroups(ctx); err != nil {
+		html = html + "PendingGroupSize  : " + err.Error() + "\n|"
+	} else {
+		html = html + "PendingGroupSize  : " + strconv.FormatUint(n, 10) + "\n|"
+	}
+	if n, err := o.chain.NumPendingNodes(ctx); err != nil {
+		html = html + "PendingNodeSize   : " + err.Error() + "\n|"
+	} else {
+		html = html + "PendingNodeSize   : " + strconv.FormatUint(n, 10) + "\n|"
+	}
+	if n, err := o.chain.CurrentBlock(ctx); err != nil {
+		html = html + "CurrentBlock      : " + err.Error() + "\n"
+	} else {
+		html = html + "CurrentBlock      : " + strconv.FormatUint(n, 10) + "\n"
+	}
+	html = html + "=================================================" + "\n"
+	w.Write([]byte(html))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
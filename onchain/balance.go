@@ -0,0 +1,251 @@
+package onchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+)
+
+const (
+	//REPLENISHTHRESHOLD is the default balance in ether below which an account is topped up
+	REPLENISHTHRESHOLD = 0.5
+	//REPLENISHAMOUNT is the default amount in wei an account is topped up with
+	REPLENISHAMOUNT = 1000000000000000000
+
+	defaultCriticalBalance = 0.1
+	balanceCheckInterval   = 5 * time.Minute
+	// topUpRetryInterval is how long a low node waits for a top-up before
+	// asking again
+	topUpRetryInterval = time.Hour
+	topUpTimeout       = 30 * time.Second
+)
+
+//BalanceLevel is how much gas money a node has left
+type BalanceLevel int
+
+const (
+	//BalanceUnknown is the level before the balance was first read
+	BalanceUnknown BalanceLevel = iota
+	//BalanceOK is enough for every transaction
+	BalanceOK
+	//BalanceLow pauses non-essential guardian transactions and asks for a top-up
+	BalanceLow
+	//BalanceCritical may soon fail the transactions of requests
+	BalanceCritical
+)
+
+func (l BalanceLevel) String() string {
+	switch l {
+	case BalanceOK:
+		return "ok"
+	case BalanceLow:
+		return "low"
+	case BalanceCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+//TopUpRequest asks the funding account to send Amount wei to the node account
+type TopUpRequest struct {
+	From    string
+	To      string
+	Balance string
+	Amount  string
+}
+
+//TopUpHook passes top-up requests on to whatever funds the node
+type TopUpHook interface {
+	RequestTopUp(ctx context.Context, req *TopUpRequest) error
+}
+
+//WebhookTopUp posts top-up requests as JSON to URL
+type WebhookTopUp struct {
+	URL string
+}
+
+//RequestTopUp posts req to the webhook
+func (h *WebhookTopUp) RequestTopUp(ctx context.Context, req *TopUpRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("top-up webhook replied %s", resp.Status)
+	}
+	return nil
+}
+
+type balanceReader interface {
+	Balance(ctx context.Context) (*big.Float, error)
+}
+
+//BalanceMonitor watches the balance of the node account, raises events at
+//the configured thresholds and asks for a top-up when it runs low
+type BalanceMonitor struct {
+	chain    balanceReader
+	account  string
+	low      *big.Float
+	critical *big.Float
+	funding  string
+	amount   *big.Int
+	interval time.Duration
+	logger   log.Logger
+
+	lock      sync.Mutex
+	hook      TopUpHook
+	balance   *big.Float
+	level     BalanceLevel
+	lastTopUp time.Time
+}
+
+//NewBalanceMonitor creates a monitor of the balance of account on chain. A
+//top-up webhook is set up if config has a TopUpURL.
+func NewBalanceMonitor(chain balanceReader, account []byte, config configuration.ChainConfig) (*BalanceMonitor, error) {
+	low, critical := config.LowBalance, config.CriticalBalance
+	if low == 0 {
+		low = REPLENISHTHRESHOLD
+	}
+	if critical == 0 {
+		critical = defaultCriticalBalance
+	}
+	if critical > low {
+		return nil, errors.New("CriticalBalance is above LowBalance")
+	}
+	amount := big.NewInt(REPLENISHAMOUNT)
+	if config.TopUpAmount != 0 {
+		amount, _ = new(big.Float).Mul(big.NewFloat(config.TopUpAmount), big.NewFloat(1e18)).Int(nil)
+	}
+	m := &BalanceMonitor{
+		chain:    chain,
+		account:  fmt.Sprintf("0x%x", account),
+		low:      big.NewFloat(low),
+		critical: big.NewFloat(critical),
+		funding:  config.FundingAccount,
+		amount:   amount,
+		interval: balanceCheckInterval,
+		logger:   log.New("module", "Balance"),
+	}
+	if config.TopUpURL != "" {
+		m.hook = &WebhookTopUp{URL: config.TopUpURL}
+	}
+	return m, nil
+}
+
+//SetTopUpHook replaces the hook top-up requests go to, nil to ask nobody
+func (m *BalanceMonitor) SetTopUpHook(hook TopUpHook) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.hook = hook
+}
+
+//Run checks the balance every few minutes until ctx is done
+func (m *BalanceMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//Check reads the balance and reports changes of its level
+func (m *BalanceMonitor) Check(ctx context.Context) (BalanceLevel, error) {
+	balance, err := m.chain.Balance(ctx)
+	if err == nil && balance == nil {
+		err = errors.New("no balance")
+	}
+	if err != nil {
+		m.logger.Error(err)
+		return m.Level(), err
+	}
+	level := BalanceOK
+	if balance.Cmp(m.critical) < 0 {
+		level = BalanceCritical
+	} else if balance.Cmp(m.low) < 0 {
+		level = BalanceLow
+	}
+	f, _ := balance.Float64()
+	m.nodeBalance(f)
+
+	m.lock.Lock()
+	prev := m.level
+	m.balance, m.level = balance, level
+	hook := m.hook
+	askTopUp := hook != nil && level >= BalanceLow && time.Since(m.lastTopUp) >= topUpRetryInterval
+	if askTopUp {
+		m.lastTopUp = time.Now()
+	}
+	m.lock.Unlock()
+
+	if level != prev {
+		info := map[string]interface{}{"Level": level.String(), "Previous": prev.String(), "Balance": balance.String()}
+		m.logger.Event("BalanceLevel", info)
+		if level >= BalanceLow {
+			m.logger.Warn(fmt.Sprintf("balance of %s is %s, %s", m.account, balance.String(), level))
+		}
+	}
+	if askTopUp {
+		m.requestTopUp(ctx, hook, balance)
+	}
+	return level, nil
+}
+
+// nodeBalance records the balance as a metric
+func (m *BalanceMonitor) nodeBalance(balance float64) {
+	m.logger.Metrics(balance)
+}
+
+func (m *BalanceMonitor) requestTopUp(ctx context.Context, hook TopUpHook, balance *big.Float) {
+	ctx, cancel := context.WithTimeout(ctx, topUpTimeout)
+	defer cancel()
+	req := &TopUpRequest{From: m.funding, To: m.account, Balance: balance.String(), Amount: m.amount.String()}
+	if err := hook.RequestTopUp(ctx, req); err != nil {
+		m.logger.Event("TopUpRequestError", map[string]interface{}{"Error": err.Error()})
+		return
+	}
+	m.logger.Event("TopUpRequested", map[string]interface{}{"Balance": req.Balance, "Amount": req.Amount, "From": req.From})
+}
+
+//Level returns the level of the last balance read
+func (m *BalanceMonitor) Level() BalanceLevel {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.level
+}
+
+//Balance returns the last balance read, nil before the first one
+func (m *BalanceMonitor) Balance() *big.Float {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.balance
+}
+
+//AllowNonEssential reports whether the node may spend gas on guardian
+//transactions that other nodes can send as well
+func (m *BalanceMonitor) AllowNonEssential() bool {
+	return m.Level() < BalanceLow
+}
@@ -0,0 +1,127 @@
+package onchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeBalance struct {
+	lock    sync.Mutex
+	balance *big.Float
+	err     error
+}
+
+func (f *fakeBalance) set(balance float64, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.balance, f.err = big.NewFloat(balance), err
+}
+
+func (f *fakeBalance) Balance(ctx context.Context) (*big.Float, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.balance, f.err
+}
+
+type recordTopUp struct {
+	requests []*TopUpRequest
+}
+
+func (r *recordTopUp) RequestTopUp(ctx context.Context, req *TopUpRequest) error {
+	r.requests = append(r.requests, req)
+	return nil
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	if _, err := NewBalanceMonitor(&fakeBalance{}, []byte{1}, configuration.ChainConfig{LowBalance: 0.1, CriticalBalance: 0.2}); err == nil {
+		t.Error("critical threshold above the low one accepted")
+	}
+	chain := &fakeBalance{}
+	m, err := NewBalanceMonitor(chain, []byte{1}, configuration.ChainConfig{LowBalance: 1, CriticalBalance: 0.2, FundingAccount: "0xfund", TopUpAmount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook := &recordTopUp{}
+	m.SetTopUpHook(hook)
+	ctx := context.Background()
+
+	if !m.AllowNonEssential() || m.Level() != BalanceUnknown {
+		t.Error("guardian paused before the balance was read")
+	}
+	chain.set(3, nil)
+	if level, err := m.Check(ctx); err != nil || level != BalanceOK || !m.AllowNonEssential() {
+		t.Errorf("level, got: %s, %v, want: ok.", level, err)
+	}
+	chain.set(0.5, nil)
+	if level, _ := m.Check(ctx); level != BalanceLow || m.AllowNonEssential() {
+		t.Errorf("level, got: %s, want: low.", level)
+	}
+	if len(hook.requests) != 1 {
+		t.Fatalf("%d top-up requests, want 1", len(hook.requests))
+	}
+	if req := hook.requests[0]; req.From != "0xfund" || req.To != "0x01" || req.Amount != "2000000000000000000" {
+		t.Errorf("top-up request %+v", req)
+	}
+	// A pending top-up is not asked for again until topUpRetryInterval passed
+	chain.set(0.1, nil)
+	if level, _ := m.Check(ctx); level != BalanceCritical || len(hook.requests) != 1 {
+		t.Errorf("level %s with %d top-up requests, want critical with 1", level, len(hook.requests))
+	}
+	m.lastTopUp = time.Now().Add(-topUpRetryInterval)
+	m.Check(ctx)
+	if len(hook.requests) != 2 {
+		t.Errorf("%d top-up requests, want 2", len(hook.requests))
+	}
+	// A failed read keeps the last level
+	chain.set(0, errors.New("no endpoint"))
+	if level, err := m.Check(ctx); err == nil || level != BalanceCritical {
+		t.Errorf("failed read, got: %s, %v.", level, err)
+	}
+	chain.lock.Lock()
+	chain.balance, chain.err = nil, nil
+	chain.lock.Unlock()
+	if _, err := m.Check(ctx); err == nil {
+		t.Error("missing balance accepted")
+	}
+	chain.set(5, nil)
+	if level, _ := m.Check(ctx); level != BalanceOK || !m.AllowNonEssential() || m.Balance().String() != "5" {
+		t.Errorf("level, got: %s, want: ok.", level)
+	}
+}
+
+func TestWebhookTopUp(t *testing.T) {
+	requests := make(chan TopUpRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TopUpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- req
+	}))
+	defer server.Close()
+
+	hook := &WebhookTopUp{URL: server.URL}
+	if err := hook.RequestTopUp(context.Background(), &TopUpRequest{To: "0x01", Amount: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-requests; req.To != "0x01" || req.Amount != "1" {
+		t.Errorf("posted %+v", req)
+	}
+	hook.URL = server.URL + "/%zz"
+	if err := hook.RequestTopUp(context.Background(), &TopUpRequest{}); err == nil {
+		t.Error("invalid webhook URL accepted")
+	}
+}
@@ -6,31 +6,36 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/share/dkg/pedersen"
 	"github.com/DOSNetwork/core/share/vss/pedersen"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
-	"github.com/ethereum/go-ethereum/common"
 )
 
 const (
 	//ETH represents the type of blockchain
 	ETH = "ETH"
+	//TENDERMINT represents a Tendermint chain running the DOS application
+	TENDERMINT = "TENDERMINT"
 )
 
 //ProxyAdapter represents an unified adapter interface for different blockchain
 type ProxyAdapter interface {
 	Start() error
 	End()
-	UpdateWsUrls(urls []string)
+	Reconnect() error
+	AdvertisedEndpoints() *Endpoints
+	AddPeerEndpoints(advertised *Endpoints)
 	SetRandomNum(ctx context.Context, signatures chan *vss.Signature) (errc chan error)
 	DataReturn(ctx context.Context, signatures chan *vss.Signature) (errc chan error)
-	RegisterGroupPubKey(ctx context.Context, IdWithPubKeys chan [5]*big.Int) (errc chan error)
-	SubscribeEvent(subscribeTypes []int) (chan interface{}, chan error)
+	RegisterGroupPubKey(ctx context.Context, pubKeys chan *dkg.GroupPubKey) (errc chan error)
+	SubscribeEvent(chanBuffer int, filter EventFilter, subscribeTypes ...int) (*Subscription, error)
+	PastEvents(ctx context.Context, from, to uint64, subscribeTypes ...int) ([]*LogCommon, error)
 	GetTimeoutCtx(t time.Duration) (context.Context, context.CancelFunc)
 	SetGroupingThreshold(ctx context.Context, threshold uint64) (errc error)
 	SetGroupToPick(ctx context.Context, groupToPick uint64) (errc error)
 	SetGroupSize(ctx context.Context, size uint64) (errc error)
 	SetGroupMaturityPeriod(ctx context.Context, size uint64) (errc error)
-	AddToWhitelist(ctx context.Context, addr common.Address) (err error)
+	AddToWhitelist(ctx context.Context, addr []byte) (err error)
 	StartCommitReveal(ctx context.Context, startBlock int64, commitDuration int64, revealDuration int64, revealThreshold int64) (err error)
 	Commit(ctx context.Context, cid *big.Int, commitment [32]byte) (errc error)
 	Reveal(ctx context.Context, cid *big.Int, secret *big.Int) (errc error)
@@ -49,19 +54,25 @@ type ProxyAdapter interface {
 	NumPendingGroups(ctx context.Context) (r uint64, err error)
 	NumPendingNodes(ctx context.Context) (r uint64, err error)
 	Balance(ctx context.Context) (balance *big.Float, err error)
-	Address() (addr common.Address)
+	GasPrice(ctx context.Context) (price *big.Int, err error)
+	Address() (addr []byte)
 	CurrentBlock(ctx context.Context) (r uint64, err error)
 	PendingNonce(ctx context.Context) (r uint64, err error)
 	RefreshSystemRandomHardLimit(ctx context.Context) (limit uint64, err error)
-	GroupPubKey(ctx context.Context, idx int) (groupPubKeys [4]*big.Int, err error)
+	GroupPubKey(ctx context.Context, idx int) (pubKey []byte, err error)
 	IsPendingNode(ctx context.Context, id []byte) (bool, error)
+	EndpointStatus() []EndpointStatus
+	FeeReport() []FeeStat
 }
 
-//NewProxyAdapter constructs a new ProxyAdapter with the given type of blockchain and contract addresses
-func NewProxyAdapter(ChainType string, key *keystore.Key, proxyAddr, crAddress string, urls []string) (ProxyAdapter, error) {
+//NewProxyAdapter constructs a new ProxyAdapter with the given type of blockchain, signer of the node account and chain configuration
+func NewProxyAdapter(ChainType string, signer Signer, config configuration.ChainConfig) (ProxyAdapter, error) {
 	switch ChainType {
 	case ETH:
-		adaptor, err := NewEthAdaptor(key, proxyAddr, crAddress, urls)
+		adaptor, err := NewEthAdaptor(signer, config)
+		return adaptor, err
+	case TENDERMINT:
+		adaptor, err := NewTmAdaptor(signer, config)
 		return adaptor, err
 	default:
 		err := fmt.Errorf("Chain %s not supported error\n", ChainType)
@@ -0,0 +1,225 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package dospayment
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ERC20IABI is the input ABI used to generate the binding from.
+const ERC20IABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"who\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// ERC20I is an auto generated Go binding around an Ethereum contract.
+type ERC20I struct {
+	ERC20ICaller     // Read-only binding to the contract
+	ERC20ITransactor // Write-only binding to the contract
+	ERC20IFilterer   // Log filterer for contract events
+}
+
+// ERC20ICaller is an auto generated read-only Go binding around an Ethereum contract.
+type ERC20ICaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20ITransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ERC20ITransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20IFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ERC20IFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20ISession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ERC20ISession struct {
+	Contract     *ERC20I           // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ERC20ICallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ERC20ICallerSession struct {
+	Contract *ERC20ICaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// ERC20ITransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ERC20ITransactorSession struct {
+	Contract     *ERC20ITransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ERC20IRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ERC20IRaw struct {
+	Contract *ERC20I // Generic contract binding to access the raw methods on
+}
+
+// ERC20ICallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ERC20ICallerRaw struct {
+	Contract *ERC20ICaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ERC20ITransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ERC20ITransactorRaw struct {
+	Contract *ERC20ITransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewERC20I creates a new instance of ERC20I, bound to a specific deployed contract.
+func NewERC20I(address common.Address, backend bind.ContractBackend) (*ERC20I, error) {
+	contract, err := bindERC20I(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20I{ERC20ICaller: ERC20ICaller{contract: contract}, ERC20ITransactor: ERC20ITransactor{contract: contract}, ERC20IFilterer: ERC20IFilterer{contract: contract}}, nil
+}
+
+// NewERC20ICaller creates a new read-only instance of ERC20I, bound to a specific deployed contract.
+func NewERC20ICaller(address common.Address, caller bind.ContractCaller) (*ERC20ICaller, error) {
+	contract, err := bindERC20I(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20ICaller{contract: contract}, nil
+}
+
+// NewERC20ITransactor creates a new write-only instance of ERC20I, bound to a specific deployed contract.
+func NewERC20ITransactor(address common.Address, transactor bind.ContractTransactor) (*ERC20ITransactor, error) {
+	contract, err := bindERC20I(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20ITransactor{contract: contract}, nil
+}
+
+// NewERC20IFilterer creates a new log filterer instance of ERC20I, bound to a specific deployed contract.
+func NewERC20IFilterer(address common.Address, filterer bind.ContractFilterer) (*ERC20IFilterer, error) {
+	contract, err := bindERC20I(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20IFilterer{contract: contract}, nil
+}
+
+// bindERC20I binds a generic wrapper to an already deployed contract.
+func bindERC20I(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(ERC20IABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ERC20I *ERC20IRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _ERC20I.Contract.ERC20ICaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ERC20I *ERC20IRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ERC20I.Contract.ERC20ITransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ERC20I *ERC20IRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ERC20I.Contract.ERC20ITransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ERC20I *ERC20ICallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _ERC20I.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ERC20I *ERC20ITransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ERC20I.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ERC20I *ERC20ITransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ERC20I.Contract.contract.Transact(opts, method, params...)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(who address) constant returns(uint256)
+func (_ERC20I *ERC20ICaller) BalanceOf(opts *bind.CallOpts, who common.Address) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _ERC20I.contract.Call(opts, out, "balanceOf", who)
+	return *ret0, err
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(who address) constant returns(uint256)
+func (_ERC20I *ERC20ISession) BalanceOf(who common.Address) (*big.Int, error) {
+	return _ERC20I.Contract.BalanceOf(&_ERC20I.CallOpts, who)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(who address) constant returns(uint256)
+func (_ERC20I *ERC20ICallerSession) BalanceOf(who common.Address) (*big.Int, error) {
+	return _ERC20I.Contract.BalanceOf(&_ERC20I.CallOpts, who)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() constant returns(uint256)
+func (_ERC20I *ERC20ICaller) Decimals(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _ERC20I.contract.Call(opts, out, "decimals")
+	return *ret0, err
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() constant returns(uint256)
+func (_ERC20I *ERC20ISession) Decimals() (*big.Int, error) {
+	return _ERC20I.Contract.Decimals(&_ERC20I.CallOpts)
+}
+
+// Decimals is a free data retrieval call binding the contract method 0x313ce567.
+//
+// Solidity: function decimals() constant returns(uint256)
+func (_ERC20I *ERC20ICallerSession) Decimals() (*big.Int, error) {
+	return _ERC20I.Contract.Decimals(&_ERC20I.CallOpts)
+}
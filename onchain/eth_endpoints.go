@@ -0,0 +1,85 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// maxPeerEndpoints is the number of peer advertised endpoints kept
+const maxPeerEndpoints = 5
+
+//AdvertisedEndpoints returns the endpoints this node lets its peers use
+func (e *ethAdaptor) AdvertisedEndpoints() *Endpoints {
+	e.endpointLock.Lock()
+	defer e.endpointLock.Unlock()
+	return &Endpoints{ChainId: e.chainID, Urls: e.advertisedUrls}
+}
+
+//AddPeerEndpoints remembers the endpoints advertised by a peer for the next
+//reconnect. They are ignored unless peer endpoints are allowed and the chain
+//ID matches ours.
+func (e *ethAdaptor) AddPeerEndpoints(advertised *Endpoints) {
+	e.endpointLock.Lock()
+	defer e.endpointLock.Unlock()
+	if !e.allowPeerUrls || advertised.GetChainId() != e.chainID {
+		return
+	}
+	known := make(map[string]bool)
+	for _, url := range e.peerUrls {
+		known[url] = true
+	}
+	for _, url := range advertised.GetUrls() {
+		if !known[url] {
+			known[url] = true
+			e.peerUrls = append(e.peerUrls, url)
+		}
+	}
+	if len(e.peerUrls) > maxPeerEndpoints {
+		e.peerUrls = e.peerUrls[len(e.peerUrls)-maxPeerEndpoints:]
+	}
+}
+
+func (e *ethAdaptor) peerEndpoints() []string {
+	e.endpointLock.Lock()
+	defer e.endpointLock.Unlock()
+	if !e.allowPeerUrls {
+		return nil
+	}
+	return append([]string(nil), e.peerUrls...)
+}
+
+// verifyChain reports whether conn serves the configured chain
+func (e *ethAdaptor) verifyChain(conn *ethConn) bool {
+	if conn.chainID != e.chainID {
+		fmt.Println(conn.url, "serves chain ", conn.chainID, " want ", e.chainID)
+		e.logger.Event("ChainIDMismatch", map[string]interface{}{"URL": conn.url, "ChainID": conn.chainID, "Want": e.chainID})
+		return false
+	}
+	return true
+}
+
+// syncedClients dials urlPool and returns the connections to our chain that
+// are in sync with mClient
+func (e *ethAdaptor) syncedClients(ctx context.Context, mClient *ethclient.Client, urlPool []string) (conns []*ethConn) {
+	// CheckSync drains clients before it returns, so dialed is complete by
+	// the time synced clients are read.
+	dialed := make(map[*ethclient.Client]*ethConn)
+	clients := make(chan *ethclient.Client)
+	go func() {
+		defer close(clients)
+		for conn := range dialToEth(ctx, urlPool) {
+			if !e.verifyChain(conn) {
+				conn.client.Close()
+				continue
+			}
+			dialed[conn.client] = conn
+			clients <- conn.client
+		}
+	}()
+	for client := range CheckSync(ctx, mClient, clients) {
+		conns = append(conns, dialed[client])
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package onchain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// rpcServer answers eth_chainId and net_version like a node of the given
+// chain. Nodes without eth_chainId answer it with method not found.
+func rpcServer(chainID string, hasChainID bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch {
+		case req.Method == "eth_chainId" && hasChainID:
+			resp["result"] = chainID
+		case req.Method == "net_version":
+			resp["result"] = "4"
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "the method " + req.Method + " does not exist/is not available"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestDialChainID(t *testing.T) {
+	modern := rpcServer("0x2a", true)
+	defer modern.Close()
+	legacy := rpcServer("", false)
+	defer legacy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(map[string]uint64)
+	for conn := range dialToEth(ctx, []string{modern.URL, legacy.URL}) {
+		got[conn.url] = conn.chainID
+		conn.client.Close()
+	}
+	want := map[string]uint64{modern.URL: 42, legacy.URL: 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chain IDs, got: %v, want: %v.", got, want)
+	}
+}
+
+func TestPeerEndpoints(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	e := &ethAdaptor{chainID: 4, advertisedUrls: []string{"ws://10.0.0.1:8546"}, logger: log.New("module", "EndpointTest")}
+	e.AddPeerEndpoints(&Endpoints{ChainId: 4, Urls: []string{"ws://10.0.0.2:8546"}})
+	if urls := e.peerEndpoints(); len(urls) != 0 {
+		t.Errorf("peer endpoints used without opting in: %v", urls)
+	}
+
+	e.allowPeerUrls = true
+	if advertised := e.AdvertisedEndpoints(); advertised.ChainId != 4 || len(advertised.Urls) != 1 {
+		t.Errorf("advertised %v", advertised)
+	}
+	e.AddPeerEndpoints(&Endpoints{ChainId: 1, Urls: []string{"ws://mainnet:8546"}})
+	for _, url := range []string{"ws://10.0.0.2:8546", "ws://10.0.0.2:8546", "a", "b", "c", "d"} {
+		e.AddPeerEndpoints(&Endpoints{ChainId: 4, Urls: []string{url}})
+	}
+	want := []string{"ws://10.0.0.2:8546", "a", "b", "c", "d"}
+	if urls := e.peerEndpoints(); !reflect.DeepEqual(urls, want) {
+		t.Errorf("peer endpoints, got: %v, want: %v.", urls, want)
+	}
+	e.AddPeerEndpoints(&Endpoints{ChainId: 4, Urls: []string{"e"}})
+	if urls := e.peerEndpoints(); len(urls) != maxPeerEndpoints || urls[maxPeerEndpoints-1] != "e" {
+		t.Errorf("peer endpoints, got: %v, want the latest %d.", urls, maxPeerEndpoints)
+	}
+
+	// A peer endpoint is dialed and dropped if it serves another chain.
+	if e.verifyChain(&ethConn{url: "ws://10.0.0.2:8546", chainID: 1}) {
+		t.Error("peer endpoint of another chain accepted")
+	}
+}
+
+func TestConfiguredChainID(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	e := &ethAdaptor{chainID: 1, logger: log.New("module", "EndpointTest")}
+	if e.verifyChain(&ethConn{url: "rinkeby", chainID: 4}) {
+		t.Error("endpoint of another chain accepted")
+	}
+	if e.chainID != 1 {
+		t.Errorf("configured chain ID overwritten with %d", e.chainID)
+	}
+	if !e.verifyChain(&ethConn{url: "mainnet", chainID: 1}) {
+		t.Error("endpoint of the configured chain rejected")
+	}
+
+	// The first endpoint does not get to pick the chain
+	if _, err := NewEthAdaptor(nil, configuration.ChainConfig{RemoteNodeAddressPool: []string{"ws://10.0.0.1:8546"}}); err == nil {
+		t.Error("adaptor made without a ChainID")
+	}
+}
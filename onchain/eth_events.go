@@ -0,0 +1,3154 @@
+// Code generated by gen_events.go - DO NOT EDIT.
+
+package onchain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/DOSNetwork/core/onchain/commitreveal"
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const (
+	//SubscribeDosproxyGuardianReward is a log type to subscribe the event GuardianReward
+	SubscribeDosproxyGuardianReward = iota
+	//SubscribeLogCallbackTriggeredFor is a log type to subscribe the event LogCallbackTriggeredFor
+	SubscribeLogCallbackTriggeredFor
+	//SubscribeLogError is a log type to subscribe the event LogError
+	SubscribeLogError
+	//SubscribeLogGroupDissolve is a log type to subscribe the event LogGroupDissolve
+	SubscribeLogGroupDissolve
+	//SubscribeLogGrouping is a log type to subscribe the event LogGrouping
+	SubscribeLogGrouping
+	//SubscribeLogGroupingInitiated is a log type to subscribe the event LogGroupingInitiated
+	SubscribeLogGroupingInitiated
+	//SubscribeLogInsufficientPendingNode is a log type to subscribe the event LogInsufficientPendingNode
+	SubscribeLogInsufficientPendingNode
+	//SubscribeLogInsufficientWorkingGroup is a log type to subscribe the event LogInsufficientWorkingGroup
+	SubscribeLogInsufficientWorkingGroup
+	//SubscribeLogNoPendingGroup is a log type to subscribe the event LogNoPendingGroup
+	SubscribeLogNoPendingGroup
+	//SubscribeLogNonContractCall is a log type to subscribe the event LogNonContractCall
+	SubscribeLogNonContractCall
+	//SubscribeLogNonSupportedType is a log type to subscribe the event LogNonSupportedType
+	SubscribeLogNonSupportedType
+	//SubscribeLogPendingGroupRemoved is a log type to subscribe the event LogPendingGroupRemoved
+	SubscribeLogPendingGroupRemoved
+	//SubscribeLogPublicKeyAccepted is a log type to subscribe the event LogPublicKeyAccepted
+	SubscribeLogPublicKeyAccepted
+	//SubscribeLogPublicKeySuggested is a log type to subscribe the event LogPublicKeySuggested
+	SubscribeLogPublicKeySuggested
+	//SubscribeLogRegisteredNewPendingNode is a log type to subscribe the event LogRegisteredNewPendingNode
+	SubscribeLogRegisteredNewPendingNode
+	//SubscribeLogRequestFromNonExistentUC is a log type to subscribe the event LogRequestFromNonExistentUC
+	SubscribeLogRequestFromNonExistentUC
+	//SubscribeLogRequestUserRandom is a log type to subscribe the event LogRequestUserRandom
+	SubscribeLogRequestUserRandom
+	//SubscribeLogUpdateRandom is a log type to subscribe the event LogUpdateRandom
+	SubscribeLogUpdateRandom
+	//SubscribeLogUrl is a log type to subscribe the event LogUrl
+	SubscribeLogUrl
+	//SubscribeLogValidationResult is a log type to subscribe the event LogValidationResult
+	SubscribeLogValidationResult
+	//SubscribeDosproxyOwnershipRenounced is a log type to subscribe the event OwnershipRenounced
+	SubscribeDosproxyOwnershipRenounced
+	//SubscribeDosproxyOwnershipTransferred is a log type to subscribe the event OwnershipTransferred
+	SubscribeDosproxyOwnershipTransferred
+	//SubscribeDosproxyUpdateBootstrapCommitDuration is a log type to subscribe the event UpdateBootstrapCommitDuration
+	SubscribeDosproxyUpdateBootstrapCommitDuration
+	//SubscribeDosproxyUpdateBootstrapRevealDuration is a log type to subscribe the event UpdateBootstrapRevealDuration
+	SubscribeDosproxyUpdateBootstrapRevealDuration
+	//SubscribeDosproxyUpdateGroupMaturityPeriod is a log type to subscribe the event UpdateGroupMaturityPeriod
+	SubscribeDosproxyUpdateGroupMaturityPeriod
+	//SubscribeDosproxyUpdateGroupSize is a log type to subscribe the event UpdateGroupSize
+	SubscribeDosproxyUpdateGroupSize
+	//SubscribeDosproxyUpdateGroupToPick is a log type to subscribe the event UpdateGroupToPick
+	SubscribeDosproxyUpdateGroupToPick
+	//SubscribeDosproxyUpdateGroupingThreshold is a log type to subscribe the event UpdateGroupingThreshold
+	SubscribeDosproxyUpdateGroupingThreshold
+	//SubscribeDosproxyUpdatePendingGroupMaxLife is a log type to subscribe the event UpdatePendingGroupMaxLife
+	SubscribeDosproxyUpdatePendingGroupMaxLife
+	//SubscribeDosproxyUpdatebootstrapStartThreshold is a log type to subscribe the event UpdatebootstrapStartThreshold
+	SubscribeDosproxyUpdatebootstrapStartThreshold
+	//SubscribeCommitrevealLogCommit is a log type to subscribe the event LogCommit
+	SubscribeCommitrevealLogCommit
+	//SubscribeCommitrevealLogRandom is a log type to subscribe the event LogRandom
+	SubscribeCommitrevealLogRandom
+	//SubscribeCommitrevealLogRandomFailure is a log type to subscribe the event LogRandomFailure
+	SubscribeCommitrevealLogRandomFailure
+	//SubscribeCommitrevealLogReveal is a log type to subscribe the event LogReveal
+	SubscribeCommitrevealLogReveal
+	//SubscribeCommitrevealLogStartCommitReveal is a log type to subscribe the event LogStartCommitReveal
+	SubscribeCommitrevealLogStartCommitReveal
+	//SubscribeCommitrevealOwnershipRenounced is a log type to subscribe the event OwnershipRenounced
+	SubscribeCommitrevealOwnershipRenounced
+	//SubscribeCommitrevealOwnershipTransferred is a log type to subscribe the event OwnershipTransferred
+	SubscribeCommitrevealOwnershipTransferred
+	numSubscribeTypes
+)
+
+// AllSubscribeTypes returns the log types of every DOSProxy and CommitReveal event
+func AllSubscribeTypes() []int {
+	types := make([]int, numSubscribeTypes)
+	for i := range types {
+		types[i] = i
+	}
+	return types
+}
+
+// subscriptionEvents maps each subscription type to the contract event it watches.
+var subscriptionEvents = map[int]string{
+	SubscribeDosproxyGuardianReward:                "GuardianReward",
+	SubscribeLogCallbackTriggeredFor:               "LogCallbackTriggeredFor",
+	SubscribeLogError:                              "LogError",
+	SubscribeLogGroupDissolve:                      "LogGroupDissolve",
+	SubscribeLogGrouping:                           "LogGrouping",
+	SubscribeLogGroupingInitiated:                  "LogGroupingInitiated",
+	SubscribeLogInsufficientPendingNode:            "LogInsufficientPendingNode",
+	SubscribeLogInsufficientWorkingGroup:           "LogInsufficientWorkingGroup",
+	SubscribeLogNoPendingGroup:                     "LogNoPendingGroup",
+	SubscribeLogNonContractCall:                    "LogNonContractCall",
+	SubscribeLogNonSupportedType:                   "LogNonSupportedType",
+	SubscribeLogPendingGroupRemoved:                "LogPendingGroupRemoved",
+	SubscribeLogPublicKeyAccepted:                  "LogPublicKeyAccepted",
+	SubscribeLogPublicKeySuggested:                 "LogPublicKeySuggested",
+	SubscribeLogRegisteredNewPendingNode:           "LogRegisteredNewPendingNode",
+	SubscribeLogRequestFromNonExistentUC:           "LogRequestFromNonExistentUC",
+	SubscribeLogRequestUserRandom:                  "LogRequestUserRandom",
+	SubscribeLogUpdateRandom:                       "LogUpdateRandom",
+	SubscribeLogUrl:                                "LogUrl",
+	SubscribeLogValidationResult:                   "LogValidationResult",
+	SubscribeDosproxyOwnershipRenounced:            "OwnershipRenounced",
+	SubscribeDosproxyOwnershipTransferred:          "OwnershipTransferred",
+	SubscribeDosproxyUpdateBootstrapCommitDuration: "UpdateBootstrapCommitDuration",
+	SubscribeDosproxyUpdateBootstrapRevealDuration: "UpdateBootstrapRevealDuration",
+	SubscribeDosproxyUpdateGroupMaturityPeriod:     "UpdateGroupMaturityPeriod",
+	SubscribeDosproxyUpdateGroupSize:               "UpdateGroupSize",
+	SubscribeDosproxyUpdateGroupToPick:             "UpdateGroupToPick",
+	SubscribeDosproxyUpdateGroupingThreshold:       "UpdateGroupingThreshold",
+	SubscribeDosproxyUpdatePendingGroupMaxLife:     "UpdatePendingGroupMaxLife",
+	SubscribeDosproxyUpdatebootstrapStartThreshold: "UpdatebootstrapStartThreshold",
+	SubscribeCommitrevealLogCommit:                 "LogCommit",
+	SubscribeCommitrevealLogRandom:                 "LogRandom",
+	SubscribeCommitrevealLogRandomFailure:          "LogRandomFailure",
+	SubscribeCommitrevealLogReveal:                 "LogReveal",
+	SubscribeCommitrevealLogStartCommitReveal:      "LogStartCommitReveal",
+	SubscribeCommitrevealOwnershipRenounced:        "OwnershipRenounced",
+	SubscribeCommitrevealOwnershipTransferred:      "OwnershipTransferred",
+}
+
+// subscriptionStructs creates the event struct of each subscription type.
+var subscriptionStructs = map[int]func() interface{}{
+	SubscribeDosproxyGuardianReward:                func() interface{} { return new(LogGuardianReward) },
+	SubscribeLogCallbackTriggeredFor:               func() interface{} { return new(LogCallbackTriggeredFor) },
+	SubscribeLogError:                              func() interface{} { return new(LogError) },
+	SubscribeLogGroupDissolve:                      func() interface{} { return new(LogGroupDissolve) },
+	SubscribeLogGrouping:                           func() interface{} { return new(LogGrouping) },
+	SubscribeLogGroupingInitiated:                  func() interface{} { return new(LogGroupingInitiated) },
+	SubscribeLogInsufficientPendingNode:            func() interface{} { return new(LogInsufficientPendingNode) },
+	SubscribeLogInsufficientWorkingGroup:           func() interface{} { return new(LogInsufficientWorkingGroup) },
+	SubscribeLogNoPendingGroup:                     func() interface{} { return new(LogNoPendingGroup) },
+	SubscribeLogNonContractCall:                    func() interface{} { return new(LogNonContractCall) },
+	SubscribeLogNonSupportedType:                   func() interface{} { return new(LogNonSupportedType) },
+	SubscribeLogPendingGroupRemoved:                func() interface{} { return new(LogPendingGroupRemoved) },
+	SubscribeLogPublicKeyAccepted:                  func() interface{} { return new(LogPublicKeyAccepted) },
+	SubscribeLogPublicKeySuggested:                 func() interface{} { return new(LogPublicKeySuggested) },
+	SubscribeLogRegisteredNewPendingNode:           func() interface{} { return new(LogRegisteredNewPendingNode) },
+	SubscribeLogRequestFromNonExistentUC:           func() interface{} { return new(LogRequestFromNonExistentUC) },
+	SubscribeLogRequestUserRandom:                  func() interface{} { return new(LogRequestUserRandom) },
+	SubscribeLogUpdateRandom:                       func() interface{} { return new(LogUpdateRandom) },
+	SubscribeLogUrl:                                func() interface{} { return new(LogUrl) },
+	SubscribeLogValidationResult:                   func() interface{} { return new(LogValidationResult) },
+	SubscribeDosproxyOwnershipRenounced:            func() interface{} { return new(LogOwnershipRenounced) },
+	SubscribeDosproxyOwnershipTransferred:          func() interface{} { return new(LogOwnershipTransferred) },
+	SubscribeDosproxyUpdateBootstrapCommitDuration: func() interface{} { return new(LogUpdateBootstrapCommitDuration) },
+	SubscribeDosproxyUpdateBootstrapRevealDuration: func() interface{} { return new(LogUpdateBootstrapRevealDuration) },
+	SubscribeDosproxyUpdateGroupMaturityPeriod:     func() interface{} { return new(LogUpdateGroupMaturityPeriod) },
+	SubscribeDosproxyUpdateGroupSize:               func() interface{} { return new(LogUpdateGroupSize) },
+	SubscribeDosproxyUpdateGroupToPick:             func() interface{} { return new(LogUpdateGroupToPick) },
+	SubscribeDosproxyUpdateGroupingThreshold:       func() interface{} { return new(LogUpdateGroupingThreshold) },
+	SubscribeDosproxyUpdatePendingGroupMaxLife:     func() interface{} { return new(LogUpdatePendingGroupMaxLife) },
+	SubscribeDosproxyUpdatebootstrapStartThreshold: func() interface{} { return new(LogUpdatebootstrapStartThreshold) },
+	SubscribeCommitrevealLogCommit:                 func() interface{} { return new(LogCommit) },
+	SubscribeCommitrevealLogRandom:                 func() interface{} { return new(LogRandom) },
+	SubscribeCommitrevealLogRandomFailure:          func() interface{} { return new(LogRandomFailure) },
+	SubscribeCommitrevealLogReveal:                 func() interface{} { return new(LogReveal) },
+	SubscribeCommitrevealLogStartCommitReveal:      func() interface{} { return new(LogStartCommitReveal) },
+	SubscribeCommitrevealOwnershipRenounced:        func() interface{} { return new(LogOwnershipRenounced) },
+	SubscribeCommitrevealOwnershipTransferred:      func() interface{} { return new(LogOwnershipTransferred) },
+}
+
+var proxyTable = map[int]func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}){
+	SubscribeDosproxyGuardianReward: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyGuardianReward)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchGuardianReward(opt, transitChan, nil)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogGuardianReward{
+						BlkNum:   i.BlkNum,
+						Guardian: i.Guardian.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogCallbackTriggeredFor: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogCallbackTriggeredFor)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogCallbackTriggeredFor(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogCallbackTriggeredFor{
+						CallbackAddr: i.CallbackAddr.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogError: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogError)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogError(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogError{
+						Err: i.Err,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogGroupDissolve: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogGroupDissolve)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogGroupDissolve(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogGroupDissolve{
+						GroupId: i.GroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogGrouping: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogGrouping)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogGrouping(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogGrouping{
+						GroupId: i.GroupId,
+						NodeId:  addressesToBytes(i.NodeId),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogGroupingInitiated: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogGroupingInitiated)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogGroupingInitiated(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogGroupingInitiated{
+						NumPendingNodes:   i.PendingNodePool,
+						GroupSize:         i.Groupsize,
+						GroupingThreshold: i.Groupingthreshold,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogInsufficientPendingNode: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogInsufficientPendingNode)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogInsufficientPendingNode(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogInsufficientPendingNode{
+						NumPendingNodes: i.NumPendingNodes,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogInsufficientWorkingGroup: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogInsufficientWorkingGroup)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogInsufficientWorkingGroup(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogInsufficientWorkingGroup{
+						NumWorkingGroups: i.NumWorkingGroups,
+						NumPendingGroups: i.NumPendingGroups,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogNoPendingGroup: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogNoPendingGroup)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogNoPendingGroup(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogNoPendingGroup{
+						GroupId: i.GroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogNonContractCall: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogNonContractCall)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogNonContractCall(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogNonContractCall{
+						From: i.From.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogNonSupportedType: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogNonSupportedType)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogNonSupportedType(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogNonSupportedType{
+						InvalidSelector: i.InvalidSelector,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogPendingGroupRemoved: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogPendingGroupRemoved)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogPendingGroupRemoved(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogPendingGroupRemoved{
+						GroupId: i.GroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogPublicKeyAccepted: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogPublicKeyAccepted)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogPublicKeyAccepted(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogPublicKeyAccepted{
+						GroupId:          i.GroupId,
+						PubKey:           pubKeyFromBig(i.PubKey),
+						WorkingGroupSize: i.NumWorkingGroups,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogPublicKeySuggested: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogPublicKeySuggested)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogPublicKeySuggested(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogPublicKeySuggested{
+						GroupId: i.GroupId,
+						Count:   i.PubKeyCount,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogRegisteredNewPendingNode: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogRegisteredNewPendingNode)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogRegisteredNewPendingNode(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogRegisteredNewPendingNode{
+						Node: i.Node.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogRequestFromNonExistentUC: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogRequestFromNonExistentUC)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogRequestFromNonExistentUC(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogRequestFromNonExistentUC{}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogRequestUserRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogRequestUserRandom)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogRequestUserRandom(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogRequestUserRandom{
+						RequestId:            i.RequestId,
+						LastSystemRandomness: i.LastSystemRandomness,
+						UserSeed:             i.UserSeed,
+						DispatchedGroupId:    i.DispatchedGroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogUpdateRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogUpdateRandom)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogUpdateRandom(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateRandom{
+						LastRandomness:    i.LastRandomness,
+						DispatchedGroupId: i.DispatchedGroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogUrl: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogUrl)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogUrl(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUrl{
+						QueryId:           i.QueryId,
+						Timeout:           i.Timeout,
+						DataSource:        i.DataSource,
+						Selector:          i.Selector,
+						Randomness:        i.Randomness,
+						DispatchedGroupId: i.DispatchedGroupId,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeLogValidationResult: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyLogValidationResult)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchLogValidationResult(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogValidationResult{
+						TrafficType: i.TrafficType,
+						TrafficId:   i.TrafficId,
+						Message:     i.Message,
+						Signature:   signatureFromBig(i.Signature),
+						PubKey:      pubKeyFromBig(i.PubKey),
+						Pass:        i.Pass,
+						Version:     i.Version,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyOwnershipRenounced: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyOwnershipRenounced)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchOwnershipRenounced(opt, transitChan, nil)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogOwnershipRenounced{
+						PreviousOwner: i.PreviousOwner.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyOwnershipTransferred: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyOwnershipTransferred)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchOwnershipTransferred(opt, transitChan, nil, nil)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogOwnershipTransferred{
+						PreviousOwner: i.PreviousOwner.Bytes(),
+						NewOwner:      i.NewOwner.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateBootstrapCommitDuration: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateBootstrapCommitDuration)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateBootstrapCommitDuration(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateBootstrapCommitDuration{
+						OldDuration: i.OldDuration,
+						NewDuration: i.NewDuration,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateBootstrapRevealDuration: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateBootstrapRevealDuration)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateBootstrapRevealDuration(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateBootstrapRevealDuration{
+						OldDuration: i.OldDuration,
+						NewDuration: i.NewDuration,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateGroupMaturityPeriod: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateGroupMaturityPeriod)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateGroupMaturityPeriod(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateGroupMaturityPeriod{
+						OldPeriod: i.OldPeriod,
+						NewPeriod: i.NewPeriod,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateGroupSize: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateGroupSize)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateGroupSize(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateGroupSize{
+						OldSize: i.OldSize,
+						NewSize: i.NewSize,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateGroupToPick: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateGroupToPick)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateGroupToPick(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateGroupToPick{
+						OldNum: i.OldNum,
+						NewNum: i.NewNum,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdateGroupingThreshold: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdateGroupingThreshold)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdateGroupingThreshold(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdateGroupingThreshold{
+						OldThreshold: i.OldThreshold,
+						NewThreshold: i.NewThreshold,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdatePendingGroupMaxLife: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdatePendingGroupMaxLife)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdatePendingGroupMaxLife(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdatePendingGroupMaxLife{
+						OldLifeBlocks: i.OldLifeBlocks,
+						NewLifeBlocks: i.NewLifeBlocks,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeDosproxyUpdatebootstrapStartThreshold: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *dosproxy.DosproxyUpdatebootstrapStartThreshold)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := proxy.Contract.WatchUpdatebootstrapStartThreshold(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogUpdatebootstrapStartThreshold{
+						OldThreshold: i.OldThreshold,
+						NewThreshold: i.NewThreshold,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+}
+
+var proxyFilterTable = map[int]func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error){
+	SubscribeDosproxyGuardianReward: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterGuardianReward(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogGuardianReward{
+				BlkNum:   i.BlkNum,
+				Guardian: i.Guardian.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogCallbackTriggeredFor: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogCallbackTriggeredFor(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogCallbackTriggeredFor{
+				CallbackAddr: i.CallbackAddr.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogError: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogError(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogError{
+				Err: i.Err,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogGroupDissolve: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogGroupDissolve(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogGroupDissolve{
+				GroupId: i.GroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogGrouping: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogGrouping(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogGrouping{
+				GroupId: i.GroupId,
+				NodeId:  addressesToBytes(i.NodeId),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogGroupingInitiated: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogGroupingInitiated(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogGroupingInitiated{
+				NumPendingNodes:   i.PendingNodePool,
+				GroupSize:         i.Groupsize,
+				GroupingThreshold: i.Groupingthreshold,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogInsufficientPendingNode: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogInsufficientPendingNode(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogInsufficientPendingNode{
+				NumPendingNodes: i.NumPendingNodes,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogInsufficientWorkingGroup: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogInsufficientWorkingGroup(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogInsufficientWorkingGroup{
+				NumWorkingGroups: i.NumWorkingGroups,
+				NumPendingGroups: i.NumPendingGroups,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogNoPendingGroup: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogNoPendingGroup(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogNoPendingGroup{
+				GroupId: i.GroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogNonContractCall: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogNonContractCall(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogNonContractCall{
+				From: i.From.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogNonSupportedType: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogNonSupportedType(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogNonSupportedType{
+				InvalidSelector: i.InvalidSelector,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogPendingGroupRemoved: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogPendingGroupRemoved(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogPendingGroupRemoved{
+				GroupId: i.GroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogPublicKeyAccepted: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogPublicKeyAccepted(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogPublicKeyAccepted{
+				GroupId:          i.GroupId,
+				PubKey:           pubKeyFromBig(i.PubKey),
+				WorkingGroupSize: i.NumWorkingGroups,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogPublicKeySuggested: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogPublicKeySuggested(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogPublicKeySuggested{
+				GroupId: i.GroupId,
+				Count:   i.PubKeyCount,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogRegisteredNewPendingNode: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogRegisteredNewPendingNode(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogRegisteredNewPendingNode{
+				Node: i.Node.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogRequestFromNonExistentUC: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogRequestFromNonExistentUC(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogRequestFromNonExistentUC{}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogRequestUserRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogRequestUserRandom(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogRequestUserRandom{
+				RequestId:            i.RequestId,
+				LastSystemRandomness: i.LastSystemRandomness,
+				UserSeed:             i.UserSeed,
+				DispatchedGroupId:    i.DispatchedGroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogUpdateRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogUpdateRandom(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateRandom{
+				LastRandomness:    i.LastRandomness,
+				DispatchedGroupId: i.DispatchedGroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogUrl: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogUrl(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUrl{
+				QueryId:           i.QueryId,
+				Timeout:           i.Timeout,
+				DataSource:        i.DataSource,
+				Selector:          i.Selector,
+				Randomness:        i.Randomness,
+				DispatchedGroupId: i.DispatchedGroupId,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeLogValidationResult: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterLogValidationResult(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogValidationResult{
+				TrafficType: i.TrafficType,
+				TrafficId:   i.TrafficId,
+				Message:     i.Message,
+				Signature:   signatureFromBig(i.Signature),
+				PubKey:      pubKeyFromBig(i.PubKey),
+				Pass:        i.Pass,
+				Version:     i.Version,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyOwnershipRenounced: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterOwnershipRenounced(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogOwnershipRenounced{
+				PreviousOwner: i.PreviousOwner.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyOwnershipTransferred: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterOwnershipTransferred(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogOwnershipTransferred{
+				PreviousOwner: i.PreviousOwner.Bytes(),
+				NewOwner:      i.NewOwner.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateBootstrapCommitDuration: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateBootstrapCommitDuration(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateBootstrapCommitDuration{
+				OldDuration: i.OldDuration,
+				NewDuration: i.NewDuration,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateBootstrapRevealDuration: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateBootstrapRevealDuration(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateBootstrapRevealDuration{
+				OldDuration: i.OldDuration,
+				NewDuration: i.NewDuration,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateGroupMaturityPeriod: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateGroupMaturityPeriod(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateGroupMaturityPeriod{
+				OldPeriod: i.OldPeriod,
+				NewPeriod: i.NewPeriod,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateGroupSize: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateGroupSize(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateGroupSize{
+				OldSize: i.OldSize,
+				NewSize: i.NewSize,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateGroupToPick: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateGroupToPick(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateGroupToPick{
+				OldNum: i.OldNum,
+				NewNum: i.NewNum,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdateGroupingThreshold: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdateGroupingThreshold(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdateGroupingThreshold{
+				OldThreshold: i.OldThreshold,
+				NewThreshold: i.NewThreshold,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdatePendingGroupMaxLife: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdatePendingGroupMaxLife(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdatePendingGroupMaxLife{
+				OldLifeBlocks: i.OldLifeBlocks,
+				NewLifeBlocks: i.NewLifeBlocks,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeDosproxyUpdatebootstrapStartThreshold: func(ctx context.Context, proxy *dosproxy.DosproxySession, from, to uint64) ([]*LogCommon, error) {
+		it, err := proxy.Contract.FilterUpdatebootstrapStartThreshold(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogUpdatebootstrapStartThreshold{
+				OldThreshold: i.OldThreshold,
+				NewThreshold: i.NewThreshold,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+}
+
+var crTable = map[int]func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}){
+	SubscribeCommitrevealLogCommit: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealLogCommit)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchLogCommit(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogCommit{
+						Cid:        i.Cid,
+						From:       i.From.Bytes(),
+						Commitment: i.Commitment,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealLogRandom: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealLogRandom)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchLogRandom(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogRandom{
+						Cid:    i.Cid,
+						Random: i.Random,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealLogRandomFailure: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealLogRandomFailure)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchLogRandomFailure(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogRandomFailure{
+						Cid:             i.Cid,
+						CommitNum:       i.CommitNum,
+						RevealNum:       i.RevealNum,
+						RevealThreshold: i.RevealThreshold,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealLogReveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealLogReveal)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchLogReveal(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogReveal{
+						Cid:    i.Cid,
+						From:   i.From.Bytes(),
+						Secret: i.Secret,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealLogStartCommitReveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealLogStartCommitReveal)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchLogStartCommitReveal(opt, transitChan)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogStartCommitReveal{
+						Cid:             i.Cid,
+						StartBlock:      i.StartBlock,
+						CommitDuration:  i.CommitDuration,
+						RevealDuration:  i.RevealDuration,
+						RevealThreshold: i.RevealThreshold,
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealOwnershipRenounced: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealOwnershipRenounced)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchOwnershipRenounced(opt, transitChan, nil)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogOwnershipRenounced{
+						PreviousOwner: i.PreviousOwner.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+	SubscribeCommitrevealOwnershipTransferred: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *commitreveal.CommitrevealOwnershipTransferred)
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := cr.Contract.WatchOwnershipTransferred(opt, transitChan, nil, nil)
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &LogOwnershipTransferred{
+						PreviousOwner: i.PreviousOwner.Bytes(),
+						NewOwner:      i.NewOwner.Bytes(),
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+}
+
+var crFilterTable = map[int]func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error){
+	SubscribeCommitrevealLogCommit: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterLogCommit(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogCommit{
+				Cid:        i.Cid,
+				From:       i.From.Bytes(),
+				Commitment: i.Commitment,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealLogRandom: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterLogRandom(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogRandom{
+				Cid:    i.Cid,
+				Random: i.Random,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealLogRandomFailure: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterLogRandomFailure(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogRandomFailure{
+				Cid:             i.Cid,
+				CommitNum:       i.CommitNum,
+				RevealNum:       i.RevealNum,
+				RevealThreshold: i.RevealThreshold,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealLogReveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterLogReveal(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogReveal{
+				Cid:    i.Cid,
+				From:   i.From.Bytes(),
+				Secret: i.Secret,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealLogStartCommitReveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterLogStartCommitReveal(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogStartCommitReveal{
+				Cid:             i.Cid,
+				StartBlock:      i.StartBlock,
+				CommitDuration:  i.CommitDuration,
+				RevealDuration:  i.RevealDuration,
+				RevealThreshold: i.RevealThreshold,
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealOwnershipRenounced: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterOwnershipRenounced(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogOwnershipRenounced{
+				PreviousOwner: i.PreviousOwner.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+	SubscribeCommitrevealOwnershipTransferred: func(ctx context.Context, cr *commitreveal.CommitrevealSession, from, to uint64) ([]*LogCommon, error) {
+		it, err := cr.Contract.FilterOwnershipTransferred(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &LogOwnershipTransferred{
+				PreviousOwner: i.PreviousOwner.Bytes(),
+				NewOwner:      i.NewOwner.Bytes(),
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+}
+
+// LogGuardianReward is an onchain event that DOSProxy emits as GuardianReward
+type LogGuardianReward struct {
+	BlkNum   *big.Int
+	Guardian []byte
+}
+
+// LogCallbackTriggeredFor is an onchain event that DOSProxy emits as LogCallbackTriggeredFor
+type LogCallbackTriggeredFor struct {
+	CallbackAddr []byte
+}
+
+// LogError is an onchain event that DOSProxy emits as LogError
+type LogError struct {
+	Err string
+}
+
+// LogNoPendingGroup is an onchain event that DOSProxy emits as LogNoPendingGroup
+type LogNoPendingGroup struct {
+	GroupId *big.Int
+}
+
+// LogNonContractCall is an onchain event that DOSProxy emits as LogNonContractCall
+type LogNonContractCall struct {
+	From []byte
+}
+
+// LogNonSupportedType is an onchain event that DOSProxy emits as LogNonSupportedType
+type LogNonSupportedType struct {
+	InvalidSelector string
+}
+
+// LogPendingGroupRemoved is an onchain event that DOSProxy emits as LogPendingGroupRemoved
+type LogPendingGroupRemoved struct {
+	GroupId *big.Int
+}
+
+// LogRegisteredNewPendingNode is an onchain event that DOSProxy emits as LogRegisteredNewPendingNode
+type LogRegisteredNewPendingNode struct {
+	Node []byte
+}
+
+// LogRequestFromNonExistentUC is an onchain event that DOSProxy emits as LogRequestFromNonExistentUC
+type LogRequestFromNonExistentUC struct {
+}
+
+// LogOwnershipRenounced is an onchain event that DOSProxy emits as OwnershipRenounced
+type LogOwnershipRenounced struct {
+	PreviousOwner []byte
+}
+
+// LogOwnershipTransferred is an onchain event that DOSProxy emits as OwnershipTransferred
+type LogOwnershipTransferred struct {
+	PreviousOwner []byte
+	NewOwner      []byte
+}
+
+// LogUpdateBootstrapCommitDuration is an onchain event that DOSProxy emits as UpdateBootstrapCommitDuration
+type LogUpdateBootstrapCommitDuration struct {
+	OldDuration *big.Int
+	NewDuration *big.Int
+}
+
+// LogUpdateBootstrapRevealDuration is an onchain event that DOSProxy emits as UpdateBootstrapRevealDuration
+type LogUpdateBootstrapRevealDuration struct {
+	OldDuration *big.Int
+	NewDuration *big.Int
+}
+
+// LogUpdateGroupMaturityPeriod is an onchain event that DOSProxy emits as UpdateGroupMaturityPeriod
+type LogUpdateGroupMaturityPeriod struct {
+	OldPeriod *big.Int
+	NewPeriod *big.Int
+}
+
+// LogUpdateGroupingThreshold is an onchain event that DOSProxy emits as UpdateGroupingThreshold
+type LogUpdateGroupingThreshold struct {
+	OldThreshold *big.Int
+	NewThreshold *big.Int
+}
+
+// LogUpdatePendingGroupMaxLife is an onchain event that DOSProxy emits as UpdatePendingGroupMaxLife
+type LogUpdatePendingGroupMaxLife struct {
+	OldLifeBlocks *big.Int
+	NewLifeBlocks *big.Int
+}
+
+// LogUpdatebootstrapStartThreshold is an onchain event that DOSProxy emits as UpdatebootstrapStartThreshold
+type LogUpdatebootstrapStartThreshold struct {
+	OldThreshold *big.Int
+	NewThreshold *big.Int
+}
+
+// LogRandomFailure is an onchain event that CommitReveal emits as LogRandomFailure
+type LogRandomFailure struct {
+	Cid             *big.Int
+	CommitNum       *big.Int
+	RevealNum       *big.Int
+	RevealThreshold *big.Int
+}
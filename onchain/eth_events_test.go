@@ -0,0 +1,169 @@
+package onchain
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/onchain/commitreveal"
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// logFeeder is a contract backend that answers every log subscription and
+// log query with a single log. Only the filterer methods are implemented.
+type logFeeder struct {
+	bind.ContractBackend
+	log   types.Log
+	query ethereum.FilterQuery
+}
+
+func (f *logFeeder) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.query = q
+	return []types.Log{f.log}, nil
+}
+
+func (f *logFeeder) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.query = q
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case ch <- f.log:
+		case <-quit:
+			return nil
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+// zeroValue builds a value of t that abi.Arguments.Pack accepts.
+func zeroValue(t abi.Type) reflect.Value {
+	switch {
+	case t.T == abi.SliceTy:
+		return reflect.MakeSlice(t.Type, 0, 0)
+	case t.T == abi.ArrayTy:
+		v := reflect.New(t.Type).Elem()
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(zeroValue(*t.Elem))
+		}
+		return v
+	case t.Type.Kind() == reflect.Ptr:
+		return reflect.New(t.Type.Elem())
+	default:
+		return reflect.Zero(t.Type)
+	}
+}
+
+// fakeLog returns a log of ev that the abigen bindings are able to unpack.
+func fakeLog(t *testing.T, ev abi.Event) types.Log {
+	topics := []common.Hash{ev.Id()}
+	var values []interface{}
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			topics = append(topics, common.Hash{})
+		} else {
+			values = append(values, zeroValue(arg.Type).Interface())
+		}
+	}
+	data, err := ev.Inputs.NonIndexed().Pack(values...)
+	if err != nil {
+		t.Fatalf("pack %s: %v", ev.Name, err)
+	}
+	return types.Log{
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: 7,
+		TxHash:      common.HexToHash("0x1234"),
+	}
+}
+
+func TestSubscriptionTableCoverage(t *testing.T) {
+	proxyABI, err := abi.JSON(strings.NewReader(dosproxy.DosproxyABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crABI, err := abi.JSON(strings.NewReader(commitreveal.CommitrevealABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(proxyABI.Events) + len(crABI.Events); numSubscribeTypes != want {
+		t.Errorf("subscription types, got: %d, want: %d.", numSubscribeTypes, want)
+	}
+
+	for subscribeType := 0; subscribeType < numSubscribeTypes; subscribeType++ {
+		name, ok := subscriptionEvents[subscribeType]
+		if !ok {
+			t.Errorf("subscription type %d has no event", subscribeType)
+			continue
+		}
+		proxyWatch, inProxy := proxyTable[subscribeType]
+		crWatch, inCR := crTable[subscribeType]
+		if inProxy == inCR {
+			t.Errorf("%s: expected exactly one handler, proxy %t commit-reveal %t", name, inProxy, inCR)
+			continue
+		}
+		proxyFilter, crFilter := proxyFilterTable[subscribeType], crFilterTable[subscribeType]
+		if (proxyFilter == nil) == (crFilter == nil) || inProxy != (proxyFilter != nil) {
+			t.Errorf("%s: expected exactly one filter, next to its handler", name)
+			continue
+		}
+
+		backend := &logFeeder{}
+		var out, errc chan interface{}
+		var past []*LogCommon
+		var pastErr error
+		var pastQuery ethereum.FilterQuery
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if inProxy {
+			backend.log = fakeLog(t, proxyABI.Events[name])
+			p, err := dosproxy.NewDosproxy(common.Address{}, backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			past, pastErr = proxyFilter(ctx, &dosproxy.DosproxySession{Contract: p}, 5, 9)
+			pastQuery = backend.query
+			out, errc = proxyWatch(ctx, &dosproxy.DosproxySession{Contract: p})
+		} else {
+			backend.log = fakeLog(t, crABI.Events[name])
+			c, err := commitreveal.NewCommitreveal(common.Address{}, backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			past, pastErr = crFilter(ctx, &commitreveal.CommitrevealSession{Contract: c}, 5, 9)
+			pastQuery = backend.query
+			out, errc = crWatch(ctx, &commitreveal.CommitrevealSession{Contract: c})
+		}
+		if pastErr != nil || len(past) != 1 || past[0].Event == nil || past[0].BlockN != backend.log.BlockNumber {
+			t.Errorf("%s: filter returned %v, %v", name, past, pastErr)
+		} else if pastQuery.FromBlock.Uint64() != 5 || pastQuery.ToBlock.Uint64() != 9 {
+			t.Errorf("%s: filtered blocks %v to %v", name, pastQuery.FromBlock, pastQuery.ToBlock)
+		}
+
+		select {
+		case v := <-out:
+			content, ok := v.(*LogCommon)
+			if !ok || content.Event == nil {
+				t.Errorf("%s: got %T without an event", name, v)
+				break
+			}
+			if content.BlockN != backend.log.BlockNumber || content.Tx != backend.log.TxHash.Hex() {
+				t.Errorf("%s: LogCommon metadata not copied from the raw log", name)
+			}
+			if topic := backend.query.Topics[0][0]; topic != backend.log.Topics[0] {
+				t.Errorf("%s: subscribed to topic %x", name, topic)
+			}
+		case err := <-errc:
+			t.Errorf("%s: handler failed: %v", name, err)
+		case <-ctx.Done():
+			t.Errorf("%s: handler delivered nothing", name)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,372 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	//TxTypeAuto sends dynamic-fee transactions on chains with a base fee and legacy ones otherwise
+	TxTypeAuto = "auto"
+	//TxTypeLegacy always sends legacy gas price transactions
+	TxTypeLegacy = "legacy"
+	//TxTypeDynamic always sends EIP-1559 dynamic-fee transactions, and fails on chains without a base fee
+	TxTypeDynamic = "dynamic"
+
+	dynamicFeeTxType = 0x02
+	// baseFeeMultiplier leaves room for the base fee to rise for a few
+	// blocks before a transaction is priced out
+	baseFeeMultiplier = 2
+	receiptInterval   = 5 * time.Second
+	receiptTimeout    = 30 * time.Minute
+)
+
+// errNoBaseFee is the error of a dynamic-fee transaction on a chain before
+// London
+var errNoBaseFee = errors.New("chain has no base fee for dynamic-fee transactions")
+
+var (
+	gwei               = big.NewInt(1000000000)
+	defaultPriorityFee = new(big.Int).Mul(big.NewInt(2), gwei)
+)
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// feePolicy decides the type and the fees of a transaction
+type feePolicy struct {
+	txType string
+	// maxFee caps the fee per gas of dynamic-fee transactions, nil for no cap
+	maxFee *big.Int
+	// priorityFee is the tip per gas, nil to use the one the node suggests
+	priorityFee *big.Int
+}
+
+func newFeePolicy(txType string, maxFeeGwei, priorityFeeGwei uint64) (policy feePolicy, err error) {
+	switch txType {
+	case "":
+		txType = TxTypeAuto
+	case TxTypeAuto, TxTypeLegacy, TxTypeDynamic:
+	default:
+		return policy, fmt.Errorf("unknown transaction type %s", txType)
+	}
+	policy.txType = txType
+	if maxFeeGwei != 0 {
+		policy.maxFee = new(big.Int).Mul(new(big.Int).SetUint64(maxFeeGwei), gwei)
+	}
+	if priorityFeeGwei != 0 {
+		policy.priorityFee = new(big.Int).Mul(new(big.Int).SetUint64(priorityFeeGwei), gwei)
+	}
+	return
+}
+
+// fees returns the tip and the fee cap per gas for the given base fee
+func (p feePolicy) fees(ctx context.Context, client rpcCaller, baseFee *big.Int) (tip, maxFee *big.Int) {
+	tip = p.priorityFee
+	if tip == nil {
+		var suggested hexutil.Big
+		if err := client.CallContext(ctx, &suggested, "eth_maxPriorityFeePerGas"); err == nil {
+			tip = suggested.ToInt()
+		} else {
+			tip = defaultPriorityFee
+		}
+	}
+	maxFee = new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier))
+	maxFee.Add(maxFee, tip)
+	if p.maxFee != nil && maxFee.Cmp(p.maxFee) > 0 {
+		maxFee = new(big.Int).Set(p.maxFee)
+	}
+	if tip.Cmp(maxFee) > 0 {
+		tip = new(big.Int).Set(maxFee)
+	}
+	return
+}
+
+// gasPrice returns what a dynamic-fee transaction pays per gas at the given
+// base fee: the base fee and the tip, up to the fee cap
+func (p feePolicy) gasPrice(ctx context.Context, client rpcCaller, baseFee *big.Int) *big.Int {
+	tip, maxFee := p.fees(ctx, client, baseFee)
+	price := new(big.Int).Add(baseFee, tip)
+	if price.Cmp(maxFee) > 0 {
+		price.Set(maxFee)
+	}
+	return price
+}
+
+// headBaseFee returns the base fee of the latest block, nil before London
+func headBaseFee(ctx context.Context, client rpcCaller) (*big.Int, error) {
+	var head struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+	}
+	if err := client.CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(head.BaseFee), nil
+}
+
+type accessTuple struct {
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+//DynamicFeeTx is the payload of an EIP-1559 transaction
+type DynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList []accessTuple
+}
+
+// sign returns the EIP-2718 encoding of the signed transaction and its hash
+func (tx *DynamicFeeTx) sign(key *ecdsa.PrivateKey) (raw []byte, hash common.Hash, err error) {
+	payload, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(append([]byte{dynamicFeeTxType}, payload...)), key)
+	if err != nil {
+		return
+	}
+	v, r, s := uint64(sig[64]), new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	payload, err = rlp.EncodeToBytes([]interface{}{
+		tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas,
+		tx.To, tx.Value, tx.Data, tx.AccessList, v, r, s,
+	})
+	if err != nil {
+		return
+	}
+	raw = append([]byte{dynamicFeeTxType}, payload...)
+	hash = common.BytesToHash(crypto.Keccak256(raw))
+	return
+}
+
+// feeBackend is a contract backend that signs the transactions built by the
+// bindings, as dynamic-fee transactions when the chain supports them, and
+// reports every transaction it sends to a feeTracker
+type feeBackend struct {
+	bind.ContractBackend
+	rpc     rpcCaller
+	signer  Signer
+	chainID *big.Int
+	policy  feePolicy
+	fees    *feeTracker
+}
+
+func newFeeBackend(backend bind.ContractBackend, client rpcCaller, signer Signer, chainID *big.Int, policy feePolicy, fees *feeTracker) *feeBackend {
+	return &feeBackend{backend, client, signer, chainID, policy, fees}
+}
+
+// SendTransaction signs and sends the unsigned tx, or a dynamic-fee
+// transaction with the same nonce, gas, recipient, value and data
+func (b *feeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	var baseFee *big.Int
+	if b.policy.txType != TxTypeLegacy {
+		var err error
+		if baseFee, err = headBaseFee(ctx, b.rpc); err != nil {
+			return err
+		}
+	}
+	if baseFee == nil && b.policy.txType == TxTypeDynamic {
+		return errNoBaseFee
+	}
+	if baseFee == nil {
+		signed, err := b.signer.SignTx(ctx, tx, b.chainID)
+		if err != nil {
+			return err
+		}
+		if err := b.ContractBackend.SendTransaction(ctx, signed); err != nil {
+			return err
+		}
+		b.fees.sent(tx.Hash(), signed.Hash(), tx.GasPrice())
+		return nil
+	}
+
+	tip, maxFee := b.policy.fees(ctx, b.rpc, baseFee)
+	dtx := &DynamicFeeTx{
+		ChainID:   b.chainID,
+		Nonce:     tx.Nonce(),
+		GasTipCap: tip,
+		GasFeeCap: maxFee,
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	raw, hash, err := b.signer.SignDynamicFeeTx(ctx, dtx)
+	if err != nil {
+		return err
+	}
+	if err := b.rpc.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(raw)); err != nil {
+		return err
+	}
+	b.fees.sent(tx.Hash(), hash, nil)
+	return nil
+}
+
+//FeeStat is what the transactions of one request type cost
+type FeeStat struct {
+	Request   string
+	Sent      uint64
+	Confirmed uint64
+	Failed    uint64
+	GasUsed   uint64
+	// Fee is the total fee in wei of the confirmed and failed transactions
+	Fee *big.Int
+}
+
+type sentTx struct {
+	hash     common.Hash
+	gasPrice *big.Int
+}
+
+// feeTracker follows the transactions sent for every request type until
+// they are mined and sums up their fees. It outlives the connections: the
+// receipts are asked to whatever client is current, nil while there is none.
+type feeTracker struct {
+	lock     sync.Mutex
+	txs      map[common.Hash]sentTx
+	stats    map[string]*FeeStat
+	ctx      context.Context
+	client   func() rpcCaller
+	interval time.Duration
+	logger   log.Logger
+}
+
+func newFeeTracker(client func() rpcCaller, logger log.Logger) *feeTracker {
+	return &feeTracker{
+		txs:      make(map[common.Hash]sentTx),
+		stats:    make(map[string]*FeeStat),
+		ctx:      context.Background(),
+		client:   client,
+		interval: receiptInterval,
+		logger:   logger,
+	}
+}
+
+// sent records that the transaction built by the bindings as unsigned was
+// sent as the transaction hash
+func (f *feeTracker) sent(unsigned, hash common.Hash, gasPrice *big.Int) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.txs[unsigned] = sentTx{hash, gasPrice}
+}
+
+// track accounts the transaction sent for tx to request and returns its hash
+func (f *feeTracker) track(request string, tx *types.Transaction) common.Hash {
+	if f == nil {
+		return tx.Hash()
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	s, ok := f.txs[tx.Hash()]
+	if !ok {
+		return tx.Hash()
+	}
+	delete(f.txs, tx.Hash())
+	f.stat(request).Sent++
+	go f.watch(request, s)
+	return s.hash
+}
+
+func (f *feeTracker) stat(request string) *FeeStat {
+	stat, ok := f.stats[request]
+	if !ok {
+		stat = &FeeStat{Request: request, Fee: new(big.Int)}
+		f.stats[request] = stat
+	}
+	return stat
+}
+
+// watch waits for the receipt of tx and adds its fee to request
+func (f *feeTracker) watch(request string, tx sentTx) {
+	ctx, cancel := context.WithTimeout(f.ctx, receiptTimeout)
+	defer cancel()
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		client := f.client()
+		if client == nil {
+			continue
+		}
+		var receipt *struct {
+			Status            hexutil.Uint64 `json:"status"`
+			GasUsed           hexutil.Uint64 `json:"gasUsed"`
+			EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+		}
+		if err := client.CallContext(ctx, &receipt, "eth_getTransactionReceipt", tx.hash); err != nil || receipt == nil {
+			continue
+		}
+		gasPrice := tx.gasPrice
+		if receipt.EffectiveGasPrice != nil {
+			gasPrice = receipt.EffectiveGasPrice.ToInt()
+		}
+		fee := new(big.Int)
+		if gasPrice != nil {
+			fee.Mul(gasPrice, new(big.Int).SetUint64(uint64(receipt.GasUsed)))
+		}
+
+		f.lock.Lock()
+		stat := f.stat(request)
+		if receipt.Status == hexutil.Uint64(types.ReceiptStatusSuccessful) {
+			stat.Confirmed++
+		} else {
+			stat.Failed++
+		}
+		stat.GasUsed += uint64(receipt.GasUsed)
+		stat.Fee.Add(stat.Fee, fee)
+		f.lock.Unlock()
+
+		f.logger.Event("TxFee", map[string]interface{}{
+			"Request": request,
+			"Tx":      fmt.Sprintf("%x", tx.hash),
+			"GasUsed": uint64(receipt.GasUsed),
+			"Fee":     fee.String(),
+			"Status":  uint64(receipt.Status),
+		})
+		return
+	}
+}
+
+// report returns the fees of every request type sorted by request type
+func (f *feeTracker) report() (stats []FeeStat) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, stat := range f.stats {
+		s := *stat
+		s.Fee = new(big.Int).Set(stat.Fee)
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Request < stats[j].Request })
+	return
+}
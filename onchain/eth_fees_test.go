@@ -0,0 +1,271 @@
+package onchain
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// feeNode is a node that records the raw transactions sent to it and mines
+// each of them with the given gas used and effective gas price
+type feeNode struct {
+	lock     sync.Mutex
+	baseFee  string
+	raw      []hexutil.Bytes
+	gasUsed  string
+	gasPrice string
+}
+
+func (n *feeNode) serve() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			head := map[string]interface{}{"number": "0x10"}
+			if n.baseFee != "" {
+				head["baseFeePerGas"] = n.baseFee
+			}
+			resp["result"] = head
+		case "eth_maxPriorityFeePerGas":
+			resp["result"] = "0x3b9aca00"
+		case "eth_sendRawTransaction":
+			var raw hexutil.Bytes
+			json.Unmarshal(req.Params[0], &raw)
+			n.raw = append(n.raw, raw)
+			resp["result"] = hexutil.Encode(crypto.Keccak256(raw))
+		case "eth_getTransactionReceipt":
+			receipt := map[string]interface{}{"status": "0x1", "gasUsed": n.gasUsed}
+			if n.gasPrice != "" {
+				receipt["effectiveGasPrice"] = n.gasPrice
+			}
+			resp["result"] = receipt
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "the method " + req.Method + " does not exist/is not available"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func (n *feeNode) sent() []hexutil.Bytes {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.raw
+}
+
+func TestDynamicFeeTxSign(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x1")
+	tx := &DynamicFeeTx{
+		ChainID:   big.NewInt(4),
+		Nonce:     7,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(30),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      []byte{1, 2, 3},
+	}
+	raw, hash, err := tx.sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != dynamicFeeTxType {
+		t.Fatalf("transaction type, got: %d, want: %d.", raw[0], dynamicFeeTxType)
+	}
+	if hash != common.BytesToHash(crypto.Keccak256(raw)) {
+		t.Error("hash is not the hash of the encoding")
+	}
+
+	var decoded struct {
+		ChainID    *big.Int
+		Nonce      uint64
+		GasTipCap  *big.Int
+		GasFeeCap  *big.Int
+		Gas        uint64
+		To         *common.Address
+		Value      *big.Int
+		Data       []byte
+		AccessList []accessTuple
+		V, R, S    *big.Int
+	}
+	if err := rlp.DecodeBytes(raw[1:], &decoded); err != nil {
+		t.Fatal(err)
+	}
+	unsigned := DynamicFeeTx{decoded.ChainID, decoded.Nonce, decoded.GasTipCap, decoded.GasFeeCap, decoded.Gas, decoded.To, decoded.Value, decoded.Data, decoded.AccessList}
+	if unsigned.Nonce != 7 || unsigned.GasFeeCap.Int64() != 30 || *unsigned.To != to || len(unsigned.AccessList) != 0 {
+		t.Errorf("decoded %+v", unsigned)
+	}
+	payload, _ := rlp.EncodeToBytes(&unsigned)
+	sig := make([]byte, 65)
+	copy(sig[32-len(decoded.R.Bytes()):32], decoded.R.Bytes())
+	copy(sig[64-len(decoded.S.Bytes()):64], decoded.S.Bytes())
+	sig[64] = byte(decoded.V.Uint64())
+	pub, err := crypto.SigToPub(crypto.Keccak256(append([]byte{dynamicFeeTxType}, payload...)), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Error("signature does not recover the sender")
+	}
+}
+
+func TestFeePolicy(t *testing.T) {
+	policy, err := newFeePolicy("", 50, 0)
+	if err != nil || policy.txType != TxTypeAuto {
+		t.Fatalf("default policy %+v, %v", policy, err)
+	}
+	if _, err := newFeePolicy("type3", 0, 0); err == nil {
+		t.Error("unknown transaction type accepted")
+	}
+	node := &feeNode{}
+	server := node.serve()
+	defer server.Close()
+	client, _ := rpc.Dial(server.URL)
+
+	gwei10 := new(big.Int).Mul(big.NewInt(10), gwei)
+	tip, maxFee := policy.fees(context.Background(), client, gwei10)
+	if tip.Cmp(gwei) != 0 || maxFee.Cmp(new(big.Int).Mul(big.NewInt(21), gwei)) != 0 {
+		t.Errorf("fees, got: %v / %v, want: suggested tip / 2 * base fee + tip.", tip, maxFee)
+	}
+	tip, maxFee = policy.fees(context.Background(), client, new(big.Int).Mul(big.NewInt(100), gwei))
+	if maxFee.Cmp(policy.maxFee) != 0 || tip.Cmp(gwei) != 0 {
+		t.Errorf("fees, got: %v / %v, want capped at %v.", tip, maxFee, policy.maxFee)
+	}
+	policy, _ = newFeePolicy(TxTypeDynamic, 1, 3)
+	if tip, maxFee = policy.fees(context.Background(), client, gwei10); tip.Cmp(maxFee) != 0 {
+		t.Errorf("tip %v above fee cap %v", tip, maxFee)
+	}
+	if price := policy.gasPrice(context.Background(), client, gwei10); price.Cmp(gwei) != 0 {
+		t.Errorf("gas price, got: %v, want: the fee cap %v.", price, gwei)
+	}
+	policy, _ = newFeePolicy(TxTypeDynamic, 0, 0)
+	if price := policy.gasPrice(context.Background(), client, gwei10); price.Cmp(new(big.Int).Mul(big.NewInt(11), gwei)) != 0 {
+		t.Errorf("gas price, got: %v, want: base fee + tip.", price)
+	}
+}
+
+func TestFeeBackend(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	node := &feeNode{gasUsed: "0x5208", gasPrice: "0x2"}
+	server := node.serve()
+	defer server.Close()
+	rpcClient, _ := rpc.Dial(server.URL)
+	client := ethclient.NewClient(rpcClient)
+	key, _ := crypto.GenerateKey()
+	fees := newFeeTracker(func() rpcCaller { return rpcClient }, log.New("module", "FeeTest"))
+	fees.interval = 10 * time.Millisecond
+	send := func(txType string, nonce uint64) common.Hash {
+		policy, _ := newFeePolicy(txType, 0, 0)
+		backend := newFeeBackend(client, rpcClient, NewKeySigner(key), big.NewInt(4), policy, fees)
+		tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(3), nil)
+		if err := backend.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+		return fees.track("DataReturn", tx)
+	}
+
+	// Before London every transaction is a legacy one, and dynamic-fee ones
+	// are refused.
+	policy, _ := newFeePolicy(TxTypeDynamic, 0, 0)
+	backend := newFeeBackend(client, rpcClient, NewKeySigner(key), big.NewInt(4), policy, fees)
+	if err := backend.SendTransaction(context.Background(), types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(3), nil)); err != errNoBaseFee {
+		t.Errorf("dynamic-fee transaction before London, got: %v, want: %v.", err, errNoBaseFee)
+	}
+	send(TxTypeAuto, 0)
+	node.lock.Lock()
+	node.baseFee = "0x10"
+	node.lock.Unlock()
+	send(TxTypeLegacy, 1)
+	hash := send(TxTypeAuto, 2)
+
+	raw := node.sent()
+	if len(raw) != 3 {
+		t.Fatalf("sent %d transactions, want 3", len(raw))
+	}
+	for i, dynamic := range []bool{false, false, true} {
+		if (raw[i][0] == dynamicFeeTxType) != dynamic {
+			t.Errorf("transaction %d dynamic, got: %t, want: %t.", i, !dynamic, dynamic)
+		}
+	}
+	if hash != common.BytesToHash(crypto.Keccak256(raw[2])) {
+		t.Error("tracked hash is not the hash of the dynamic-fee transaction")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := fees.report()
+		if len(stats) == 1 && stats[0].Confirmed == 3 {
+			if stats[0].Sent != 3 || stats[0].GasUsed != 3*21000 || stats[0].Fee.Int64() != 3*21000*2 {
+				t.Errorf("fee report %+v", stats[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fee report %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFeeTrackerReconnect(t *testing.T) {
+	log.Init(common.Address{}.Bytes())
+	node := &feeNode{gasUsed: "0x5208", gasPrice: "0x2"}
+	server := node.serve()
+	defer server.Close()
+	rpcClient, _ := rpc.Dial(server.URL)
+
+	// The connection the transaction was sent on is gone, and the tracker
+	// keeps asking for the receipt until a new one is current.
+	var lock sync.Mutex
+	var current rpcCaller
+	fees := newFeeTracker(func() rpcCaller {
+		lock.Lock()
+		defer lock.Unlock()
+		return current
+	}, log.New("module", "FeeTest"))
+	fees.interval = 10 * time.Millisecond
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(3), nil)
+	fees.sent(tx.Hash(), tx.Hash(), tx.GasPrice())
+	fees.track("DataReturn", tx)
+	time.Sleep(50 * time.Millisecond)
+	if stats := fees.report(); len(stats) != 1 || stats[0].Confirmed != 0 {
+		t.Fatalf("fee report without a connection %+v", stats)
+	}
+	lock.Lock()
+	current = rpcClient
+	lock.Unlock()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if stats := fees.report(); stats[0].Confirmed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fee report %+v", fees.report())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,297 @@
+package onchain
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	healthCheckInterval = 15 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	// maxErrorRate quarantines an endpoint whose recent calls mostly fail
+	maxErrorRate = 0.5
+	// maxLatency quarantines an endpoint that answers slower than this on average
+	maxLatency = 3 * time.Second
+	// quarantinePeriod is the minimum time an endpoint stays out of rotation
+	quarantinePeriod = time.Minute
+	// healthWeight is the weight of a new sample in the moving averages
+	healthWeight = 0.2
+	// minHealthSamples is the number of samples needed before judging the error rate
+	minHealthSamples = 3
+	// readHedgeDelay is how long a read waits before also asking the next endpoint
+	readHedgeDelay = time.Second
+)
+
+//EndpointStatus is a snapshot of the health of a connected RPC endpoint
+type EndpointStatus struct {
+	URL         string
+	Head        uint64
+	Lag         uint64
+	Latency     time.Duration
+	ErrorRate   float64
+	Score       float64
+	Quarantined bool
+}
+
+type endpointHealth struct {
+	url         string
+	head        uint64
+	latency     float64
+	errRate     float64
+	samples     int
+	quarantined bool
+	since       time.Time
+}
+
+// healthMonitor scores the endpoints of an adaptor by head lag, error rate and
+// latency. Endpoints are referred to by their index in the adaptor's clients.
+// A nil monitor has no endpoints.
+type healthMonitor struct {
+	lock      sync.Mutex
+	endpoints []*endpointHealth
+	best      uint64
+	rand      *rand.Rand
+	now       func() time.Time
+	logger    log.Logger
+}
+
+func newHealthMonitor(urls []string, logger log.Logger) *healthMonitor {
+	h := &healthMonitor{
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+		logger: logger,
+	}
+	for _, url := range urls {
+		h.endpoints = append(h.endpoints, &endpointHealth{url: url})
+	}
+	return h
+}
+
+// record adds the outcome of a call to an endpoint
+func (h *healthMonitor) record(idx int, latency time.Duration, err error) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	ep := h.endpoints[idx]
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	if ep.samples == 0 {
+		ep.errRate = failed
+		ep.latency = float64(latency)
+	} else {
+		ep.errRate += healthWeight * (failed - ep.errRate)
+		if err == nil {
+			ep.latency += healthWeight * (float64(latency) - ep.latency)
+		}
+	}
+	ep.samples++
+}
+
+// updateHead records the latest block number reported by an endpoint
+func (h *healthMonitor) updateHead(idx int, head uint64) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.endpoints[idx].head = head
+	if head > h.best {
+		h.best = head
+	}
+}
+
+// evaluate quarantines unhealthy endpoints and re-admits the ones that have
+// been healthy again after their quarantine period
+func (h *healthMonitor) evaluate() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	now := h.now()
+	for _, ep := range h.endpoints {
+		reason := h.problem(ep)
+		switch {
+		case !ep.quarantined && reason != "":
+			ep.quarantined = true
+			ep.since = now
+			h.logger.Event("EndpointQuarantined", map[string]interface{}{"URL": ep.url, "Reason": reason})
+		case ep.quarantined && reason == "" && now.Sub(ep.since) >= quarantinePeriod:
+			ep.quarantined = false
+			h.logger.Event("EndpointReadmitted", map[string]interface{}{"URL": ep.url})
+		}
+	}
+}
+
+// problem must be called with the lock held
+func (h *healthMonitor) problem(ep *endpointHealth) string {
+	switch {
+	case h.best-ep.head > syncBlockDiff:
+		return "head lag"
+	case ep.samples >= minHealthSamples && ep.errRate > maxErrorRate:
+		return "error rate"
+	case time.Duration(ep.latency) > maxLatency:
+		return "latency"
+	}
+	return ""
+}
+
+// score must be called with the lock held. Faster and more reliable
+// endpoints score higher.
+func (h *healthMonitor) score(ep *endpointHealth) float64 {
+	latency := time.Duration(ep.latency)
+	if latency < time.Millisecond {
+		latency = time.Millisecond
+	}
+	return (1 - ep.errRate) / latency.Seconds()
+}
+
+// readOrder returns the endpoints to try for a read. Healthy endpoints are
+// drawn at random weighted by their score; quarantined ones are only used when
+// no endpoint is healthy.
+func (h *healthMonitor) readOrder() []int {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var candidates []int
+	var weights []float64
+	for i, ep := range h.endpoints {
+		if !ep.quarantined {
+			candidates = append(candidates, i)
+			weights = append(weights, h.score(ep))
+		}
+	}
+	if len(candidates) == 0 {
+		return h.ranked()
+	}
+
+	var order []int
+	for len(candidates) > 0 {
+		total := 0.0
+		for _, w := range weights {
+			total += w
+		}
+		pick := len(candidates) - 1
+		x := h.rand.Float64() * total
+		for i, w := range weights {
+			if x < w {
+				pick = i
+				break
+			}
+			x -= w
+		}
+		order = append(order, candidates[pick])
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+		weights = append(weights[:pick], weights[pick+1:]...)
+	}
+	return order
+}
+
+// writeOrder returns all endpoints, healthy ones first, best score first
+func (h *healthMonitor) writeOrder() []int {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.ranked()
+}
+
+// ranked must be called with the lock held
+func (h *healthMonitor) ranked() []int {
+	order := make([]int, len(h.endpoints))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := h.endpoints[order[i]], h.endpoints[order[j]]
+		if a.quarantined != b.quarantined {
+			return !a.quarantined
+		}
+		return h.score(a) > h.score(b)
+	})
+	return order
+}
+
+func (h *healthMonitor) status() (status []EndpointStatus) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, ep := range h.endpoints {
+		status = append(status, EndpointStatus{
+			URL:         ep.url,
+			Head:        ep.head,
+			Lag:         h.best - ep.head,
+			Latency:     time.Duration(ep.latency),
+			ErrorRate:   ep.errRate,
+			Score:       h.score(ep),
+			Quarantined: ep.quarantined,
+		})
+	}
+	return
+}
+
+// headFunc returns the latest block an endpoint knows of
+type headFunc func(ctx context.Context) (uint64, error)
+
+// ethHeads returns the head of every client
+func ethHeads(clients []*ethclient.Client) (heads []headFunc) {
+	for _, client := range clients {
+		client := client
+		heads = append(heads, func(ctx context.Context) (uint64, error) {
+			header, err := client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return 0, err
+			}
+			return header.Number.Uint64(), nil
+		})
+	}
+	return
+}
+
+// checkHealth probes the head of every endpoint each healthCheckInterval
+func checkHealth(ctx context.Context, health *healthMonitor, heads []headFunc) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		var wg sync.WaitGroup
+		wg.Add(len(heads))
+		for i, head := range heads {
+			go func(i int, head headFunc) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+				defer cancel()
+				start := time.Now()
+				number, err := head(ctx)
+				if ctx.Err() == context.Canceled {
+					return
+				}
+				health.record(i, time.Since(start), err)
+				if err == nil {
+					health.updateHead(i, number)
+				}
+			}(i, head)
+		}
+		wg.Wait()
+		health.evaluate()
+	}
+}
@@ -0,0 +1,159 @@
+package onchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func newTestMonitor(urls ...string) (*healthMonitor, *time.Time) {
+	log.Init(common.Address{}.Bytes())
+	h := newHealthMonitor(urls, log.New("module", "HealthTest"))
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	return h, &now
+}
+
+func TestHealthQuarantine(t *testing.T) {
+	h, now := newTestMonitor("lagging", "failing", "slow", "good")
+	for i := 0; i < minHealthSamples; i++ {
+		h.record(0, 10*time.Millisecond, nil)
+		h.record(1, 10*time.Millisecond, errors.New("connection refused"))
+		h.record(2, 2*maxLatency, nil)
+		h.record(3, 10*time.Millisecond, nil)
+	}
+	h.updateHead(0, 100)
+	h.updateHead(1, 110)
+	h.updateHead(2, 110)
+	h.updateHead(3, 110)
+	h.evaluate()
+
+	status := h.status()
+	for i, want := range []bool{true, true, true, false} {
+		if status[i].Quarantined != want {
+			t.Errorf("%s quarantined, got: %t, want: %t.", status[i].URL, status[i].Quarantined, want)
+		}
+	}
+	if status[0].Lag != 10 {
+		t.Errorf("lag, got: %d, want: 10.", status[0].Lag)
+	}
+	for i := 0; i < 10; i++ {
+		if order := h.readOrder(); len(order) != 1 || order[0] != 3 {
+			t.Fatalf("read order, got: %v, want: [3].", order)
+		}
+	}
+	if order := h.writeOrder(); len(order) != 4 || order[0] != 3 {
+		t.Errorf("write order, got: %v, want healthy endpoint first.", order)
+	}
+
+	// A recovered endpoint stays out until its quarantine period is over.
+	h.updateHead(0, 110)
+	h.evaluate()
+	if !h.status()[0].Quarantined {
+		t.Error("endpoint re-admitted before its quarantine period")
+	}
+	*now = now.Add(quarantinePeriod)
+	h.evaluate()
+	if h.status()[0].Quarantined {
+		t.Error("recovered endpoint not re-admitted")
+	}
+	if !h.status()[1].Quarantined {
+		t.Error("failing endpoint re-admitted")
+	}
+}
+
+func TestHealthReadOrderWeighted(t *testing.T) {
+	h, _ := newTestMonitor("fast", "slow")
+	h.record(0, 10*time.Millisecond, nil)
+	h.record(1, 100*time.Millisecond, nil)
+	firsts := make([]int, 2)
+	for i := 0; i < 1000; i++ {
+		order := h.readOrder()
+		if len(order) != 2 {
+			t.Fatalf("read order, got: %v, want both endpoints.", order)
+		}
+		firsts[order[0]]++
+	}
+	if firsts[0] < 800 {
+		t.Errorf("fast endpoint picked first %d of 1000 times", firsts[0])
+	}
+	if firsts[1] == 0 {
+		t.Error("slow endpoint never picked first")
+	}
+
+	// Without healthy endpoints reads still go to the best quarantined one.
+	for i := 0; i < minHealthSamples*2; i++ {
+		h.record(0, 10*time.Millisecond, errors.New("timeout"))
+		h.record(1, 10*time.Millisecond, errors.New("timeout"))
+	}
+	h.evaluate()
+	if order := h.readOrder(); len(order) != 2 {
+		t.Errorf("read order, got: %v, want both endpoints.", order)
+	}
+}
+
+func TestGetFailover(t *testing.T) {
+	h, _ := newTestMonitor("broken", "hanging", "working")
+	h.record(0, time.Millisecond, nil)
+	h.record(1, 2*time.Millisecond, nil)
+	h.record(2, 1000*time.Millisecond, nil)
+	e := &ethAdaptor{
+		clients: make([]*ethclient.Client, 3),
+		proxies: []*dosproxy.DosproxySession{{}, {}, {}},
+		health:  h,
+		logger:  h.logger,
+	}
+	var proxies = map[*dosproxy.DosproxySession]int{e.proxies[0]: 0, e.proxies[1]: 1, e.proxies[2]: 2}
+	f := func(ctx context.Context, client *ethclient.Client, proxy *dosproxy.DosproxySession, p interface{}) (chan interface{}, chan interface{}) {
+		outc := make(chan interface{})
+		errc := make(chan interface{})
+		go func() {
+			defer close(outc)
+			defer close(errc)
+			switch proxies[proxy] {
+			case 0:
+				select {
+				case <-ctx.Done():
+				case errc <- errors.New("connection refused"):
+				}
+			case 1:
+				<-ctx.Done()
+			case 2:
+				select {
+				case <-ctx.Done():
+				case outc <- big.NewInt(42):
+				}
+			}
+		}()
+		return outc, errc
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	val, err := e.get(ctx, f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := val.(*big.Int); !ok || v.Int64() != 42 {
+		t.Errorf("got %v, want 42", val)
+	}
+	if elapsed := time.Since(start); elapsed > 3*readHedgeDelay {
+		t.Errorf("read took %v", elapsed)
+	}
+	if rate := h.status()[2].ErrorRate; rate != 0 {
+		t.Errorf("working endpoint error rate, got: %f, want: 0.", rate)
+	}
+
+	e.health = nil
+	if _, err := e.get(ctx, f, nil); err == nil {
+		t.Error("read without endpoints succeeded")
+	}
+}
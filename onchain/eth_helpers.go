@@ -2,19 +2,26 @@ package onchain
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"math/big"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -22,28 +29,6 @@ const (
 	syncBlockDiff     = 3
 )
 
-func first(ctx context.Context, source <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
-	go func() {
-		defer close(out)
-		first := true
-		for val := range source {
-			if first {
-				if val != nil {
-					first = false
-					select {
-					case <-ctx.Done():
-						return
-					case out <- val:
-					}
-					return
-				}
-			}
-		}
-	}()
-	return out
-}
-
 func merge(ctx context.Context, cs ...chan interface{}) chan interface{} {
 	var wg sync.WaitGroup
 	out := make(chan interface{})
@@ -74,18 +59,75 @@ func merge(ctx context.Context, cs ...chan interface{}) chan interface{} {
 	return out
 }
 
-func GenEthkey(credentialPath, passPhrase string) (err error) {
-	newKeyStore := keystore.NewKeyStore(credentialPath, keystore.StandardScryptN, keystore.StandardScryptP)
-	if len(newKeyStore.Accounts()) >= 1 {
-		fmt.Println("", newKeyStore.Accounts()[0].URL.Path)
-		err = errors.New("Found an existing key in " + credentialPath)
-		return
+//NewKeyedTransactor creates a transactor that signs transactions from key
+//with EIP-155 replay protection for the given chain
+func NewKeyedTransactor(key *ecdsa.PrivateKey, chainID *big.Int) *bind.TransactOpts {
+	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.NewEIP155Signer(chainID)
+	return &bind.TransactOpts{
+		From: keyAddr,
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != keyAddr {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return types.SignTx(tx, signer, key)
+		},
+	}
+}
+
+// addressesToBytes converts node addresses to the byte IDs used by p2p and dkg.
+func addressesToBytes(addrs []common.Address) (ids [][]byte) {
+	for _, addr := range addrs {
+		ids = append(ids, addr.Bytes())
 	}
-	_, err = newKeyStore.NewAccount(passPhrase)
+	return
+}
 
+// pubKeyToBig converts a marshalled bn256 G2 public key to the coordinates
+// the contracts take.
+func pubKeyToBig(pubKey []byte) (coords [4]*big.Int, err error) {
+	if len(pubKey) != 1+4*32 {
+		return coords, fmt.Errorf("public key of %d bytes", len(pubKey))
+	}
+	for i := 0; i < 4; i++ {
+		coords[i] = new(big.Int).SetBytes(pubKey[32*i+1 : 32*i+33])
+	}
 	return
 }
 
+// pubKeyFromBig converts public key coordinates of the contracts back to a
+// marshalled bn256 G2 point.
+func pubKeyFromBig(coords [4]*big.Int) []byte {
+	pubKey := make([]byte, 1+4*32)
+	pubKey[0] = 0x01
+	for i, c := range coords {
+		if c != nil {
+			copy(pubKey[32*i+1:32*i+33], common.LeftPadBytes(c.Bytes(), 32))
+		}
+	}
+	return pubKey
+}
+
+// signatureFromBig converts signature coordinates of the contracts back to a
+// marshalled bn256 G1 point.
+func signatureFromBig(coords [2]*big.Int) []byte {
+	sig := make([]byte, 2*32)
+	for i, c := range coords {
+		if c != nil {
+			copy(sig[32*i:32*i+32], common.LeftPadBytes(c.Bytes(), 32))
+		}
+	}
+	return sig
+}
+
+//GenEthkey adds a new key to the keystore at credentialPath. Only the first
+//key is the node key.
+func GenEthkey(credentialPath, passPhrase string) (addr common.Address, err error) {
+	newKeyStore := openKeyStore(credentialPath)
+	account, err := newKeyStore.NewAccount(passPhrase)
+	return account.Address, err
+}
+
 func NumOfAccounts(credentialPath string) (n int) {
 	newKeyStore := keystore.NewKeyStore(credentialPath, keystore.StandardScryptN, keystore.StandardScryptP)
 	if newKeyStore != nil {
@@ -94,7 +136,7 @@ func NumOfAccounts(credentialPath string) (n int) {
 	return
 }
 
-//ReadEthKey is a utility function to read a keystore file
+//ReadEthKey is a utility function to read the node key, the first key of a keystore
 func ReadEthKey(credentialPath, passphrase string) (key *keystore.Key, err error) {
 	newKeyStore := keystore.NewKeyStore(credentialPath, keystore.StandardScryptN, keystore.StandardScryptP)
 	if len(newKeyStore.Accounts()) < 1 {
@@ -116,24 +158,60 @@ func ReadEthKey(credentialPath, passphrase string) (key *keystore.Key, err error
 //DialToEth is a utility function to dial to Ethereum
 func DialToEth(ctx context.Context, urlPool []string) (out chan *ethclient.Client) {
 	out = make(chan *ethclient.Client)
+	go func() {
+		defer close(out)
+		for conn := range dialToEth(ctx, urlPool) {
+			select {
+			case <-ctx.Done():
+				conn.client.Close()
+			case out <- conn.client:
+			}
+		}
+	}()
+	return
+}
+
+// ethConn is a client together with the url and chain it is connected to
+type ethConn struct {
+	url     string
+	client  *ethclient.Client
+	rpc     *rpc.Client
+	chainID uint64
+}
+
+// getChainID asks for eth_chainId and falls back to net_version on nodes
+// that do not support it yet
+func getChainID(ctx context.Context, client *rpc.Client) (uint64, error) {
+	var id hexutil.Uint64
+	if err := client.CallContext(ctx, &id, "eth_chainId"); err == nil {
+		return uint64(id), nil
+	}
+	var version string
+	if err := client.CallContext(ctx, &version, "net_version"); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(version, 10, 64)
+}
+
+func dialToEth(ctx context.Context, urlPool []string) (out chan *ethConn) {
+	out = make(chan *ethConn)
 	var wg sync.WaitGroup
 
 	multiplex := func(url string) {
-		var e error
-		var client *ethclient.Client
 		defer wg.Done()
 
-		client, e = ethclient.Dial(url)
+		rpcClient, e := rpc.Dial(url)
 		if e != nil {
 			//ws connect: connection timed out
 			fmt.Println(url, ":DialToEth err ", e)
 			return
 		}
+		client := ethclient.NewClient(rpcClient)
 
-		_, err := client.NetworkID(ctx)
+		chainID, err := getChainID(ctx, rpcClient)
 		if err != nil {
 			//Post http i/o timeout
-			fmt.Println(url, "NetworkID err ", err)
+			fmt.Println(url, "ChainID err ", err)
 			client.Close()
 			return
 		}
@@ -142,7 +220,7 @@ func DialToEth(ctx context.Context, urlPool []string) (out chan *ethclient.Clien
 		case <-ctx.Done():
 			client.Close()
 			return
-		case out <- client:
+		case out <- &ethConn{url, client, rpcClient, chainID}:
 		}
 	}
 
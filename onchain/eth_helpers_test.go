@@ -3,9 +3,13 @@ package onchain
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -104,3 +108,28 @@ func TestDialToEthErrHandling(t *testing.T) {
 		t.Errorf("Dial success count, got: %d, want: %d.", count, 1)
 	}
 }
+
+func TestNewKeyedTransactor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chainID := range []int64{1, 5, 1337} {
+		auth := NewKeyedTransactor(key, big.NewInt(chainID))
+		tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		signed, err := auth.Signer(types.HomesteadSigner{}, auth.From, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !signed.Protected() || signed.ChainId().Int64() != chainID {
+			t.Errorf("chain %d: protected %t chain ID %s", chainID, signed.Protected(), signed.ChainId())
+		}
+		sender, err := types.Sender(types.NewEIP155Signer(big.NewInt(chainID)), signed)
+		if err != nil || sender != auth.From {
+			t.Errorf("chain %d: sender %x, %v", chainID, sender, err)
+		}
+		if _, err := auth.Signer(types.HomesteadSigner{}, common.Address{1}, tx); err == nil {
+			t.Errorf("chain %d: signed for another account", chainID)
+		}
+	}
+}
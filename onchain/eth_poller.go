@@ -0,0 +1,127 @@
+package onchain
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const (
+	//EventSourceWebsocket watches contract events through websocket subscriptions
+	EventSourceWebsocket = "websocket"
+	//EventSourcePolling watches contract events by polling eth_getLogs over http
+	EventSourcePolling = "polling"
+
+	defaultPollInterval      = 15 * time.Second
+	defaultPollBlockWindow   = 100
+	defaultPollConfirmations = 6
+	// maxPollFailures is the number of failed polls in a row that ends a subscription
+	maxPollFailures = 3
+)
+
+type pollClient interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// logPoller is a contract backend that serves log subscriptions by polling
+// FilterLogs, so contracts bound to it can be watched over plain http. Logs
+// are delivered from the block after the subscription is made, once
+// confirmations blocks are mined on top of theirs: a poll cannot retract a
+// log the way a websocket subscription does, so it only reports logs that
+// are past the reach of a reorg.
+type logPoller struct {
+	pollClient
+	interval      time.Duration
+	window        uint64
+	confirmations uint64
+}
+
+func newLogPoller(client pollClient, interval time.Duration, window, confirmations uint64) *logPoller {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	if window == 0 {
+		window = defaultPollBlockWindow
+	}
+	if confirmations == 0 {
+		confirmations = defaultPollConfirmations
+	}
+	return &logPoller{client, interval, window, confirmations}
+}
+
+// SubscribeFilterLogs polls for logs matching q every interval and asks for
+// at most window blocks per request
+func (p *logPoller) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	head, err := p.head()
+	if err != nil {
+		return nil, err
+	}
+	next := head + 1
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		failures := 0
+		for {
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return nil
+			}
+			logs, last, err := p.poll(q, next)
+			if err != nil {
+				failures++
+				if failures >= maxPollFailures {
+					return err
+				}
+				continue
+			}
+			failures = 0
+			for _, l := range logs {
+				select {
+				case ch <- l:
+				case <-quit:
+					return nil
+				}
+			}
+			next = last + 1
+		}
+	}), nil
+}
+
+// poll returns the logs from block from up to the last confirmed block or
+// the end of the window, and the last block that was searched
+func (p *logPoller) poll(q ethereum.FilterQuery, from uint64) (logs []types.Log, last uint64, err error) {
+	head, err := p.head()
+	if err != nil {
+		return
+	}
+	if head < from+p.confirmations {
+		return nil, from - 1, nil
+	}
+	last = head - p.confirmations
+	if last-from >= p.window {
+		last = from + p.window - 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+	q.FromBlock = new(big.Int).SetUint64(from)
+	q.ToBlock = new(big.Int).SetUint64(last)
+	logs, err = p.FilterLogs(ctx, q)
+	return
+}
+
+func (p *logPoller) head() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+	header, err := p.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
@@ -0,0 +1,179 @@
+package onchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChain serves the heads and logs a logPoller asks for over http
+type fakeChain struct {
+	bind.ContractBackend
+	lock    sync.Mutex
+	head    uint64
+	logs    []types.Log
+	fail    int
+	queries []ethereum.FilterQuery
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+}
+
+func (c *fakeChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (logs []types.Log, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.fail > 0 {
+		c.fail--
+		return nil, errors.New("502 Bad Gateway")
+	}
+	c.queries = append(c.queries, q)
+	for _, l := range c.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return
+}
+
+func (c *fakeChain) mine(head uint64, logs ...types.Log) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head = head
+	c.logs = append(c.logs, logs...)
+}
+
+func TestLogPollerWindow(t *testing.T) {
+	chain := &fakeChain{head: 10}
+	poller := newLogPoller(chain, 10*time.Millisecond, 5, 1)
+	ch := make(chan types.Log)
+	sub, err := poller.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// Logs at or before the subscription head are not delivered.
+	chain.mine(23, types.Log{BlockNumber: 10}, types.Log{BlockNumber: 11}, types.Log{BlockNumber: 17}, types.Log{BlockNumber: 22})
+	chain.lock.Lock()
+	chain.fail = maxPollFailures - 1
+	chain.lock.Unlock()
+	for _, want := range []uint64{11, 17, 22} {
+		select {
+		case l := <-ch:
+			if l.BlockNumber != want {
+				t.Errorf("log block, got: %d, want: %d.", l.BlockNumber, want)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(time.Second):
+			t.Fatalf("log of block %d not delivered", want)
+		}
+	}
+
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+	from := uint64(11)
+	for _, q := range chain.queries {
+		if q.FromBlock.Uint64() != from {
+			t.Errorf("poll from %d, want %d", q.FromBlock.Uint64(), from)
+		}
+		if n := q.ToBlock.Uint64() - q.FromBlock.Uint64() + 1; n > 5 {
+			t.Errorf("polled %d blocks, want at most 5", n)
+		}
+		from = q.ToBlock.Uint64() + 1
+	}
+}
+
+func TestLogPollerFailure(t *testing.T) {
+	chain := &fakeChain{head: 10, fail: maxPollFailures}
+	poller := newLogPoller(chain, 10*time.Millisecond, 5, 1)
+	sub, err := poller.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mine(12)
+	select {
+	case err := <-sub.Err():
+		if err == nil {
+			t.Error("subscription ended without an error")
+		}
+	case <-time.After(time.Second):
+		t.Error("failing poller not ended")
+	}
+}
+
+func TestLogPollerConfirmations(t *testing.T) {
+	chain := &fakeChain{head: 10}
+	poller := newLogPoller(chain, 10*time.Millisecond, 5, 3)
+	ch := make(chan types.Log)
+	sub, err := poller.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// A log a reorg could still remove is held back
+	chain.mine(13, types.Log{BlockNumber: 11})
+	select {
+	case l := <-ch:
+		t.Fatalf("log of block %d delivered with 2 confirmations, want 3", l.BlockNumber)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	chain.mine(14)
+	select {
+	case l := <-ch:
+		if l.BlockNumber != 11 {
+			t.Errorf("log block, got: %d, want: 11.", l.BlockNumber)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("confirmed log not delivered")
+	}
+}
+
+func TestLogPollerEvents(t *testing.T) {
+	proxyABI, err := abi.JSON(strings.NewReader(dosproxy.DosproxyABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &fakeChain{head: 6}
+	p, err := dosproxy.NewDosproxy(common.Address{}, newLogPoller(chain, 10*time.Millisecond, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, errc := proxyTable[SubscribeLogUrl](ctx, &dosproxy.DosproxySession{Contract: p})
+	time.Sleep(50 * time.Millisecond)
+	chain.mine(8, fakeLog(t, proxyABI.Events[subscriptionEvents[SubscribeLogUrl]]))
+
+	select {
+	case v := <-out:
+		content := v.(*LogCommon)
+		if _, ok := content.Event.(*LogUrl); !ok || content.BlockN != 7 {
+			t.Errorf("got %T at block %d, want *LogUrl at block 7", content.Event, content.BlockN)
+		}
+	case err := <-errc:
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("polled event not delivered")
+	}
+}
@@ -1,20 +1,24 @@
 package onchain
 
+//go:generate go run gen_events.go
+
 import (
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math/big"
 	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-stack/stack"
 
+	"github.com/DOSNetwork/core/share/dkg/pedersen"
 	"github.com/DOSNetwork/core/share/vss/pedersen"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 
+	"github.com/DOSNetwork/core/configuration"
 	"github.com/DOSNetwork/core/log"
 	"github.com/DOSNetwork/core/onchain/commitreveal"
 	"github.com/DOSNetwork/core/onchain/dosproxy"
@@ -23,46 +27,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-const (
-	//SubscribeLogUpdateRandom is a log type to subscribe the event LogUpdateRandom
-	SubscribeLogUpdateRandom = iota
-	//SubscribeLogRequestUserRandom is a log type to subscribe the event LogRequestUserRandom
-	SubscribeLogRequestUserRandom
-	//SubscribeLogUrl is a log type to subscribe the event LogUrl
-	SubscribeLogUrl
-	//SubscribeLogValidationResult is a log type to subscribe the event LogValidationResult
-	SubscribeLogValidationResult
-	//SubscribeLogGrouping is a log type to subscribe the event LogGrouping
-	SubscribeLogGrouping
-	//SubscribeLogPublicKeyAccepted is a log type to subscribe the event LogPublicKeyAccepted
-	SubscribeLogPublicKeyAccepted
-	//SubscribeLogPublicKeySuggested is a log type to subscribe the event LogPublicKeySuggested
-	SubscribeLogPublicKeySuggested
-	//SubscribeLogGroupDissolve is a log type to subscribe the event LogGroupDissolve
-	SubscribeLogGroupDissolve
-	//SubscribeLogInsufficientPendingNode is a log type to subscribe the event LogInsufficientPendingNode
-	SubscribeLogInsufficientPendingNode
-	//SubscribeLogInsufficientWorkingGroup is a log type to subscribe the event LogInsufficientWorkingGroup
-	SubscribeLogInsufficientWorkingGroup
-	//SubscribeLogNoWorkingGroup is a log type to subscribe the event LogNoWorkingGroup
-	SubscribeLogNoWorkingGroup
-	//SubscribeLogGroupingInitiated is a log type to subscribe the event GroupingInitiated
-	SubscribeLogGroupingInitiated
-	//SubscribeDosproxyUpdateGroupToPick is a log type to subscribe the event UpdateGroupToPick
-	SubscribeDosproxyUpdateGroupToPick
-	//SubscribeDosproxyUpdateGroupSize is a log type to subscribe the event UpdateGroupSize
-	SubscribeDosproxyUpdateGroupSize
-	//SubscribeCommitrevealLogStartCommitreveal is a log type to subscribe the event StartCommitreveal
-	SubscribeCommitrevealLogStartCommitreveal
-	//SubscribeCommitrevealLogCommit is a log type to subscribe the event LogCommit
-	SubscribeCommitrevealLogCommit
-	//SubscribeCommitrevealLogReveal is a log type to subscribe the event LogReveal
-	SubscribeCommitrevealLogReveal
-	//SubscribeCommitrevealLogRandom is a log type to subscribe the event LogRandom
-	SubscribeCommitrevealLogRandom
-)
 const (
 	//TrafficSystemRandom is a request type to build a corresponding pipeline
 	TrafficSystemRandom = iota // 0
@@ -77,6 +44,7 @@ type setFunc func(ctx context.Context, proxy *dosproxy.DosproxySession, cr *comm
 
 type request struct {
 	ctx    context.Context
+	name   string
 	idx    int
 	proxy  *dosproxy.DosproxySession
 	cr     *commitreveal.CommitrevealSession
@@ -86,18 +54,26 @@ type request struct {
 }
 
 type response struct {
-	idx int
-	tx  *types.Transaction
-	err error
+	idx  int
+	tx   *types.Transaction
+	hash common.Hash
+	err  error
 }
 
 type ethAdaptor struct {
-	proxyAddr        string
-	commitRevealAddr string
-	httpUrls         []string
-	wsUrls           []string
-	key              *keystore.Key
-	auth             *bind.TransactOpts
+	proxyAddr         string
+	commitRevealAddr  string
+	httpUrls          []string
+	wsUrls            []string
+	eventSource       string
+	pollInterval      time.Duration
+	pollBlockWindow   uint64
+	pollConfirmations uint64
+	advertisedUrls    []string
+	allowPeerUrls     bool
+	feePolicy         feePolicy
+	signer            Signer
+	auth              *bind.TransactOpts
 
 	proxies    []*dosproxy.DosproxySession
 	crs        []*commitreveal.CommitrevealSession
@@ -105,881 +81,176 @@ type ethAdaptor struct {
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 	reqQueue   chan *request
+	bus        *eventBus
+	health     *healthMonitor
+	fees       *feeTracker
 	logger     log.Logger
-}
-
-var proxyTable = []func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}){
-	SubscribeLogUpdateRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogUpdateRandom)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogUpdateRandom(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogUpdateRandom{
-						LastRandomness:    i.LastRandomness,
-						DispatchedGroupId: i.DispatchedGroupId,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogUrl: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogUrl)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-
-			sub, err := proxy.Contract.WatchLogUrl(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogUrl{
-						QueryId:           i.QueryId,
-						Timeout:           i.Timeout,
-						DataSource:        i.DataSource,
-						Selector:          i.Selector,
-						Randomness:        i.Randomness,
-						DispatchedGroupId: i.DispatchedGroupId,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogRequestUserRandom: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogRequestUserRandom)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-
-			sub, err := proxy.Contract.WatchLogRequestUserRandom(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogRequestUserRandom{
-						RequestId:            i.RequestId,
-						LastSystemRandomness: i.LastSystemRandomness,
-						UserSeed:             i.UserSeed,
-						DispatchedGroupId:    i.DispatchedGroupId,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogValidationResult: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogValidationResult)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-
-			sub, err := proxy.Contract.WatchLogValidationResult(opt, transitChan)
-			if err != nil {
-				fmt.Println("SubscribeLogValidationResult err", err)
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					fmt.Println("SubscribeLogValidationResult Done")
-
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					fmt.Println("SubscribeLogValidationResult err", err)
 
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogValidationResult{
-						TrafficType: i.TrafficType,
-						TrafficId:   i.TrafficId,
-						Message:     i.Message,
-						Signature:   i.Signature,
-						PubKey:      i.PubKey,
-						Pass:        i.Pass,
-						Version:     i.Version,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogInsufficientPendingNode: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogInsufficientPendingNode)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogInsufficientPendingNode(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogInsufficientPendingNode{
-						NumPendingNodes: i.NumPendingNodes,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogInsufficientWorkingGroup: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogInsufficientWorkingGroup)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogInsufficientWorkingGroup(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogInsufficientWorkingGroup{
-						NumWorkingGroups: i.NumWorkingGroups,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogGroupingInitiated: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogGroupingInitiated)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogGroupingInitiated(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogGroupingInitiated{}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeDosproxyUpdateGroupSize: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyUpdateGroupSize)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchUpdateGroupSize(opt, transitChan)
-			if err != nil {
-				fmt.Println("WatchUpdateGroupSize err ", err)
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogUpdateGroupSize{
-						OldSize: i.OldSize,
-						NewSize: i.NewSize,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeDosproxyUpdateGroupToPick: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyUpdateGroupToPick)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchUpdateGroupToPick(opt, transitChan)
-			if err != nil {
-				fmt.Println("WatchUpdateGroupToPick err ", err)
+	// rpc is the client of the first working connection, which the fee
+	// tracker asks for receipts across reconnections
+	rpcLock sync.Mutex
+	rpc     *rpc.Client
 
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogUpdateGroupToPick{
-						OldNum: i.OldNum,
-						NewNum: i.NewNum,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogGrouping: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogGrouping)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogGrouping(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					var participants [][]byte
-					for _, p := range i.NodeId {
-						id := p.Bytes()
-						participants = append(participants, id)
-					}
-					l := &LogGrouping{
-						GroupId: i.GroupId,
-						NodeId:  participants,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogPublicKeyAccepted: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogPublicKeyAccepted)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogPublicKeyAccepted(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogPublicKeyAccepted{
-						GroupId:          i.GroupId,
-						WorkingGroupSize: i.NumWorkingGroups,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogPublicKeySuggested: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogPublicKeySuggested)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogPublicKeySuggested(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogPublicKeySuggested{
-						GroupId: i.GroupId,
-						Count:   i.PubKeyCount,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeLogGroupDissolve: func(ctx context.Context, proxy *dosproxy.DosproxySession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *dosproxy.DosproxyLogGroupDissolve)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := proxy.Contract.WatchLogGroupDissolve(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogGroupDissolve{
-						GroupId: i.GroupId,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-}
-var crTable = []func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}){
-	SubscribeCommitrevealLogStartCommitreveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *commitreveal.CommitrevealLogStartCommitReveal)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := cr.Contract.WatchLogStartCommitReveal(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogStartCommitReveal{
-						Cid:             i.Cid,
-						StartBlock:      i.StartBlock,
-						CommitDuration:  i.CommitDuration,
-						RevealDuration:  i.RevealDuration,
-						RevealThreshold: i.RevealThreshold,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeCommitrevealLogCommit: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *commitreveal.CommitrevealLogCommit)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := cr.Contract.WatchLogCommit(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogCommit{
-						Cid:        i.Cid,
-						From:       i.From,
-						Commitment: i.Commitment,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeCommitrevealLogReveal: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *commitreveal.CommitrevealLogReveal)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := cr.Contract.WatchLogReveal(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogReveal{
-						Cid:    i.Cid,
-						From:   i.From,
-						Secret: i.Secret,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
-	SubscribeCommitrevealLogRandom: func(ctx context.Context, cr *commitreveal.CommitrevealSession) (chan interface{}, chan interface{}) {
-		out := make(chan interface{})
-		errc := make(chan interface{})
-		opt := &bind.WatchOpts{}
-		go func() {
-			transitChan := make(chan *commitreveal.CommitrevealLogRandom)
-			defer close(transitChan)
-			defer close(errc)
-			defer close(out)
-			sub, err := cr.Contract.WatchLogRandom(opt, transitChan)
-			if err != nil {
-				return
-			}
-			for {
-				var log *LogCommon
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					errc <- err
-					return
-				case i := <-transitChan:
-					l := &LogRandom{
-						Cid:    i.Cid,
-						Random: i.Random,
-					}
-					log = &LogCommon{
-						Tx:      i.Raw.TxHash.Hex(),
-						BlockN:  i.Raw.BlockNumber,
-						Removed: i.Raw.Removed,
-						Raw:     i.Raw,
-						log:     l,
-					}
-				}
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case out <- log:
-				}
-			}
-		}()
-		return out, errc
-	},
+	// chainID is the configured chain that every endpoint, configured or
+	// advertised by a peer, must serve
+	endpointLock sync.Mutex
+	chainID      uint64
+	peerUrls     []string
 }
 
 //NewEthAdaptor creates an eth implemention of ProxyAdapter
-func NewEthAdaptor(key *keystore.Key, proxyAddr, commitRevealAddr string, urls []string) (adaptor *ethAdaptor, err error) {
+func NewEthAdaptor(signer Signer, config configuration.ChainConfig) (adaptor *ethAdaptor, err error) {
 	var httpUrls []string
 	var wsUrls []string
-	for _, url := range urls {
+	for _, url := range config.RemoteNodeAddressPool {
 		if strings.Contains(url, "http") {
 			httpUrls = append(httpUrls, url)
 		} else if strings.Contains(url, "ws") {
 			wsUrls = append(wsUrls, url)
 		}
 	}
+	if config.ChainID == 0 {
+		// Never take the chain from an endpoint, it would sign for any chain
+		return nil, errors.New("no ChainID in the chain config")
+	}
+	switch config.EventSource {
+	case "":
+		config.EventSource = EventSourceWebsocket
+	case EventSourceWebsocket, EventSourcePolling:
+	default:
+		return nil, fmt.Errorf("unknown event source %s", config.EventSource)
+	}
+	policy, err := newFeePolicy(config.TxType, config.MaxFeePerGas, config.MaxPriorityFeePerGas)
+	if err != nil {
+		return
+	}
 	fmt.Println("httpUrls ", httpUrls)
 	fmt.Println("wsUrls ", wsUrls)
 
 	adaptor = &ethAdaptor{}
 	adaptor.httpUrls = httpUrls
 	adaptor.wsUrls = wsUrls
-	adaptor.proxyAddr = proxyAddr
-	adaptor.commitRevealAddr = commitRevealAddr
+	adaptor.proxyAddr = config.DOSProxyAddress
+	adaptor.commitRevealAddr = config.CommitReveal
+	adaptor.eventSource = config.EventSource
+	adaptor.pollInterval = time.Duration(config.PollInterval) * time.Second
+	adaptor.pollBlockWindow = config.PollBlockWindow
+	adaptor.pollConfirmations = config.PollConfirmations
+	adaptor.advertisedUrls = config.AdvertisedEndpoints
+	adaptor.allowPeerUrls = config.AllowPeerEndpoints
+	adaptor.chainID = config.ChainID
+	adaptor.feePolicy = policy
 	debug.FreeOSMemory()
-	adaptor.key = key
+	adaptor.signer = signer
 	debug.FreeOSMemory()
 
 	//Use account address as ID to init log module
-	log.Init(key.Address.Bytes()[:])
+	log.Init(signer.Address().Bytes()[:])
 	adaptor.logger = log.New("module", "EthProxy")
+	adaptor.fees = newFeeTracker(adaptor.currentRPC, adaptor.logger)
 	return
 }
 
+func (e *ethAdaptor) currentRPC() rpcCaller {
+	e.rpcLock.Lock()
+	defer e.rpcLock.Unlock()
+	if e.rpc == nil {
+		return nil
+	}
+	return e.rpc
+}
+
+func (e *ethAdaptor) setRPC(client *rpc.Client) {
+	e.rpcLock.Lock()
+	defer e.rpcLock.Unlock()
+	e.rpc = client
+}
+
 //End close the connection to eth and release all resources
 func (e *ethAdaptor) End() {
 	e.cancelFunc()
+	e.setRPC(nil)
 	e.clients = nil
 	e.proxies = nil
 	e.crs = nil
 	e.reqQueue = nil
+	e.bus = nil
+	e.health = nil
 	return
 }
 
 func (e *ethAdaptor) Start() (err error) {
 	//
 	e.ctx, e.cancelFunc = context.WithCancel(context.Background())
-	e.auth = bind.NewKeyedTransactor(e.key.PrivateKey)
-	e.auth.GasPrice = big.NewInt(20000000000) //1 Gwei
-	e.auth.GasLimit = uint64(6000000)
-	e.auth.Context = e.ctx
 
-	infuraClientC := DialToEth(context.Background(), e.httpUrls)
-	infuraClient := <-infuraClientC
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(300*time.Second))
 	defer cancel()
-	clients := DialToEth(ctx, e.wsUrls)
-	synClients := CheckSync(ctx, infuraClient, clients)
-
-	for client := range synClients {
-		p, er := dosproxy.NewDosproxy(common.HexToAddress(e.proxyAddr), client)
+	var infuraClient *ethclient.Client
+	if conn, ok := <-dialToEth(ctx, e.httpUrls); ok {
+		if !e.verifyChain(conn) {
+			conn.client.Close()
+			return fmt.Errorf("%s does not serve the configured chain", conn.url)
+		}
+		infuraClient = conn.client
+	}
+	urlPool := e.wsUrls
+	if e.eventSource == EventSourcePolling {
+		urlPool = e.httpUrls
+	}
+	conns := e.syncedClients(ctx, infuraClient, urlPool)
+	if peerUrls := e.peerEndpoints(); len(conns) == 0 && len(peerUrls) > 0 {
+		fmt.Println("No configured eth client, trying endpoints advertised by peers ", peerUrls)
+		conns = e.syncedClients(ctx, infuraClient, peerUrls)
+	}
+
+	var chainID *big.Int
+	if len(conns) > 0 {
+		// Every connection has been checked to serve this chain
+		chainID = new(big.Int).SetUint64(conns[0].chainID)
+		e.auth = newSignerTransactor(e.signer)
+		e.auth.GasPrice = big.NewInt(20000000000) //1 Gwei
+		e.auth.GasLimit = uint64(6000000)
+		e.auth.Context = e.ctx
+	}
+	var clientUrls []string
+	for _, conn := range conns {
+		client := conn.client
+		var backend bind.ContractBackend = client
+		if e.eventSource == EventSourcePolling {
+			backend = newLogPoller(client, e.pollInterval, e.pollBlockWindow, e.pollConfirmations)
+		}
+		backend = newFeeBackend(backend, conn.rpc, e.signer, chainID, e.feePolicy, e.fees)
+		p, er := dosproxy.NewDosproxy(common.HexToAddress(e.proxyAddr), backend)
 		if er != nil {
 			fmt.Println("NewDosproxy err ", er)
 			e.logger.Error(er)
 			continue
 		}
-		c, er := commitreveal.NewCommitreveal(common.HexToAddress(e.commitRevealAddr), client)
+		c, er := commitreveal.NewCommitreveal(common.HexToAddress(e.commitRevealAddr), backend)
 		if er != nil {
 			e.logger.Error(er)
 			err = er
 			continue
 		}
+		if len(e.clients) == 0 {
+			e.setRPC(conn.rpc)
+		}
 		e.clients = append(e.clients, client)
+		clientUrls = append(clientUrls, conn.url)
 		e.proxies = append(e.proxies, &dosproxy.DosproxySession{Contract: p, CallOpts: bind.CallOpts{Context: e.ctx}, TransactOpts: *e.auth})
 		e.crs = append(e.crs, &commitreveal.CommitrevealSession{Contract: c, CallOpts: bind.CallOpts{Context: e.ctx}, TransactOpts: *e.auth})
 	}
 
 	if len(e.proxies) == 0 {
 		fmt.Println("No any working eth client ", len(e.clients), len(e.proxies))
+		if err == nil {
+			err = errors.New("No any working eth client")
+		}
 		return
 	}
+	e.health = newHealthMonitor(clientUrls, e.logger)
+	go checkHealth(e.ctx, e.health, ethHeads(e.clients))
+	e.bus = newEventBus(e.ctx, e.watchEvent, e.logger)
 	e.reqLoop()
 	return
 }
@@ -988,9 +259,11 @@ func (e *ethAdaptor) GetTimeoutCtx(t time.Duration) (context.Context, context.Ca
 	return context.WithTimeout(e.ctx, t)
 }
 
-func (e *ethAdaptor) UpdateWsUrls(urls []string) {
-	e.wsUrls = nil
-	e.wsUrls = urls
+//Reconnect drops all connections and connects again, first to the configured
+//endpoints and, if none of them works, to the endpoints advertised by peers
+func (e *ethAdaptor) Reconnect() (err error) {
+	e.End()
+	return e.Start()
 }
 
 func (e *ethAdaptor) reqLoop() {
@@ -1003,7 +276,10 @@ func (e *ethAdaptor) reqLoop() {
 			select {
 			case req := <-e.reqQueue:
 				tx, err := req.f(req.ctx, req.proxy, req.cr, req.params)
-				resp := &response{req.idx, tx, err}
+				resp := &response{idx: req.idx, tx: tx, err: err}
+				if err == nil && tx != nil {
+					resp.hash = e.fees.track(req.name, tx)
+				}
 				go func(req *request, resp *response) {
 					select {
 					case req.reply <- resp:
@@ -1018,37 +294,96 @@ func (e *ethAdaptor) reqLoop() {
 	}()
 }
 
+// EndpointStatus returns the health of every connected endpoint
+func (e *ethAdaptor) EndpointStatus() []EndpointStatus {
+	return e.health.status()
+}
+
+// FeeReport returns the fees paid for every request type since the adaptor was created
+func (e *ethAdaptor) FeeReport() []FeeStat {
+	return e.fees.report()
+}
+
+type readResult struct {
+	idx     int
+	val     interface{}
+	err     error
+	latency time.Duration
+}
+
+// get asks the endpoints picked by the health monitor one at a time, moving on
+// when an endpoint fails or takes longer than readHedgeDelay, and returns the
+// first answer.
 func (e *ethAdaptor) get(ctx context.Context, f getFunc, p interface{}) (interface{}, interface{}) {
-	var valList []chan interface{}
-	var errList []chan interface{}
-	for i, client := range e.clients {
-		outc, errc := f(ctx, client, e.proxies[i], p)
-		valList = append(valList, outc)
-		errList = append(errList, errc)
+	order := e.health.readOrder()
+	if len(order) == 0 {
+		return nil, errors.New("No eth client")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *readResult, len(order))
+	try := func(idx int) {
+		start := time.Now()
+		outc, errc := f(ctx, e.clients[idx], e.proxies[idx], p)
+		go func() {
+			r := &readResult{idx: idx, err: errors.New("No result")}
+			select {
+			case val, ok := <-outc:
+				if ok && val != nil {
+					r.val, r.err = val, nil
+				}
+			case err, ok := <-errc:
+				if ok {
+					if err, ok := err.(error); ok {
+						r.err = err
+					}
+				}
+			}
+			r.latency = time.Since(start)
+			results <- r
+		}()
 	}
 
-	outc := first(ctx, merge(ctx, valList...))
-	errc := merge(ctx, errList...)
+	next, pending := 0, 0
+	launch := func() {
+		try(order[next])
+		next++
+		pending++
+	}
+	launch()
+	hedge := time.NewTimer(readHedgeDelay)
+	defer hedge.Stop()
 	for {
 		select {
-		case val, ok := <-outc:
-			if !ok {
-				return nil, nil
+		case r := <-results:
+			pending--
+			if ctx.Err() != nil {
+				return nil, errors.New("Timeout")
 			}
-			return val, nil
-		case err, ok := <-errc:
-			if !ok {
-				continue
+			e.health.record(r.idx, r.latency, r.err)
+			if r.err == nil {
+				return r.val, nil
+			}
+			fmt.Println("get err", r.err, " stack ", stack.Trace().TrimRuntime())
+			e.logger.Error(r.err)
+			if next < len(order) {
+				launch()
+			} else if pending == 0 {
+				return nil, r.err
+			}
+		case <-hedge.C:
+			if next < len(order) {
+				launch()
+				hedge.Reset(readHedgeDelay)
 			}
-			fmt.Println("get err", err, " stack ", stack.Trace().TrimRuntime())
-			e.logger.Error(err.(error))
 		case <-ctx.Done():
 			return nil, errors.New("Timeout")
 		}
 	}
 }
 
-func (e *ethAdaptor) set(ctx context.Context, params []interface{}, setF setFunc) (reply chan *response) {
+func (e *ethAdaptor) set(ctx context.Context, name string, params []interface{}, setF setFunc) (reply chan *response) {
 
 	f := func(ctx context.Context, idx int, pre chan *response, r *request) (out chan *response) {
 		out = make(chan *response)
@@ -1092,8 +427,8 @@ func (e *ethAdaptor) set(ctx context.Context, params []interface{}, setF setFunc
 		return
 	}
 
-	for i, proxy := range e.proxies {
-		r := &request{ctx, i, proxy, e.crs[i], setF, params, nil}
+	for _, i := range e.health.writeOrder() {
+		r := &request{ctx, name, i, e.proxies[i], e.crs[i], setF, params, nil}
 		reply = f(ctx, i, reply, r)
 	}
 
@@ -1101,28 +436,28 @@ func (e *ethAdaptor) set(ctx context.Context, params []interface{}, setF setFunc
 }
 
 // AddToWhitelist is a wrap function that build a pipeline to set groupToPick
-func (e *ethAdaptor) AddToWhitelist(ctx context.Context, addr common.Address) (err error) {
+func (e *ethAdaptor) AddToWhitelist(ctx context.Context, addr []byte) (err error) {
 	// define how to parse parameters and execute proxy function
 	f := func(ctx context.Context, proxy *dosproxy.DosproxySession, cr *commitreveal.CommitrevealSession, p []interface{}) (tx *types.Transaction, err error) {
 		if len(p) != 1 {
 			err = errors.New("Invalid parameter")
 			return
 		}
-		if addr, ok := p[0].(common.Address); ok {
-			tx, err = cr.AddToWhitelist(addr)
+		if addr, ok := p[0].([]byte); ok {
+			tx, err = cr.AddToWhitelist(common.BytesToAddress(addr))
 		}
 		return
 	}
 	// define parameters
 	var params []interface{}
 	params = append(params, addr)
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "AddToWhitelist", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("AddToWhitelist response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("AddToWhitelist response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("AddToWhitelist error ", r.err)
 			}
@@ -1157,14 +492,14 @@ func (e *ethAdaptor) StartCommitReveal(ctx context.Context, startBlock int64, co
 	params = append(params, big.NewInt(commitDuration))
 	params = append(params, big.NewInt(revealDuration))
 	params = append(params, big.NewInt(revealThreshold))
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "StartCommitReveal", params, f)
 
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("StartCommitReveal response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("StartCommitReveal response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("StartCommitReveal error ", r.err)
 			}
@@ -1191,14 +526,14 @@ func (e *ethAdaptor) SetGroupToPick(ctx context.Context, groupToPick uint64) (er
 	var params []interface{}
 	params = append(params, big.NewInt(int64(groupToPick)))
 
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "SetGroupToPick", params, f)
 
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SeGroupToPick response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SeGroupToPick response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SeGroupToPick error ", r.err)
 			}
@@ -1216,13 +551,13 @@ func (e *ethAdaptor) RegisterNewNode(ctx context.Context) (err error) {
 		return
 	}
 	defer e.logger.TimeTrack(time.Now(), "RegisterNewNode", nil)
-	reply := e.set(ctx, nil, f)
+	reply := e.set(ctx, "RegisterNewNode", nil, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("RegisterNewNode response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("RegisterNewNode response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("RegisterNewNode error ", r.err)
 			}
@@ -1239,14 +574,14 @@ func (e *ethAdaptor) SignalRandom(ctx context.Context) (err error) {
 		return
 	}
 
-	reply := e.set(ctx, nil, f)
+	reply := e.set(ctx, "SignalRandom", nil, f)
 
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SignalRandom response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SignalRandom response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SignalRandom error ", r.err)
 			}
@@ -1263,13 +598,13 @@ func (e *ethAdaptor) SignalGroupFormation(ctx context.Context) (err error) {
 		return
 	}
 
-	reply := e.set(ctx, nil, f)
+	reply := e.set(ctx, "SignalGroupFormation", nil, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SignalGroupFormation response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SignalGroupFormation response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SignalGroupFormation error ", r.err)
 			}
@@ -1286,13 +621,13 @@ func (e *ethAdaptor) SignalGroupDissolve(ctx context.Context) (err error) {
 		return
 	}
 
-	reply := e.set(ctx, nil, f)
+	reply := e.set(ctx, "SignalGroupDissolve", nil, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SignalGroupDissolve response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SignalGroupDissolve response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SignalGroupDissolve error ", r.err)
 			}
@@ -1320,13 +655,13 @@ func (e *ethAdaptor) SignalBootstrap(ctx context.Context, cid *big.Int) (err err
 	var params []interface{}
 	params = append(params, cid)
 
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "SignalBootstrap", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SignalBootstrap response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SignalBootstrap response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SignalBootstrap error ", r.err)
 			}
@@ -1354,13 +689,13 @@ func (e *ethAdaptor) SetGroupSize(ctx context.Context, size uint64) (err error)
 	var params []interface{}
 	params = append(params, big.NewInt(int64(size)))
 
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "SetGroupSize", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SetGroupSize response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SetGroupSize response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SetGroupSize error ", r.err)
 			}
@@ -1387,13 +722,13 @@ func (e *ethAdaptor) SetGroupMaturityPeriod(ctx context.Context, period uint64)
 	var params []interface{}
 	params = append(params, big.NewInt(int64(period)))
 
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "SetGroupMaturityPeriod", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SetGroupSize response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SetGroupSize response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SetGroupSize error ", r.err)
 			}
@@ -1420,13 +755,13 @@ func (e *ethAdaptor) SetGroupingThreshold(ctx context.Context, threshold uint64)
 	var params []interface{}
 	params = append(params, big.NewInt(int64(threshold)))
 
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "SetGroupingThreshold", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("SetGroupingThreshold response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("SetGroupingThreshold response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("SetGroupingThreshold error ", r.err)
 			}
@@ -1461,13 +796,13 @@ func (e *ethAdaptor) Commit(ctx context.Context, cid *big.Int, commitment [32]by
 	var params []interface{}
 	params = append(params, cid)
 	params = append(params, commitment)
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "Commit", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("Commit response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("Commit response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("Commit error ", r.err)
 			}
@@ -1497,13 +832,13 @@ func (e *ethAdaptor) Reveal(ctx context.Context, cid *big.Int, secret *big.Int)
 	var params []interface{}
 	params = append(params, cid)
 	params = append(params, secret)
-	reply := e.set(ctx, params, f)
+	reply := e.set(ctx, "Reveal", params, f)
 	select {
 	case r, ok := <-reply:
 		if ok {
 			err = r.err
 			if r.err == nil {
-				fmt.Println("Reveal response ", fmt.Sprintf("%x", r.tx.Hash()))
+				fmt.Println("Reveal response ", fmt.Sprintf("%x", r.hash))
 			} else {
 				fmt.Println("Reveal error ", r.err)
 			}
@@ -1514,17 +849,17 @@ func (e *ethAdaptor) Reveal(ctx context.Context, cid *big.Int, secret *big.Int)
 }
 
 // RegisterGroupPubKey is a wrap function that build a pipeline to call RegisterGroupPubKey
-func (e *ethAdaptor) RegisterGroupPubKey(ctx context.Context, IdWithPubKeys chan [5]*big.Int) (errc chan error) {
+func (e *ethAdaptor) RegisterGroupPubKey(ctx context.Context, pubKeys chan *dkg.GroupPubKey) (errc chan error) {
 	fmt.Println("RegisterGroupPubKey")
 	errc = make(chan error)
 	go func() {
 		defer close(errc)
 		select {
-		case idPubkey, ok := <-IdWithPubKeys:
+		case idPubkey, ok := <-pubKeys:
 			if !ok {
 				return
 			}
-			defer e.logger.TimeTrack(time.Now(), "RegisterGroupPubKey", map[string]interface{}{"GroupID": fmt.Sprintf("%x", idPubkey[0])})
+			defer e.logger.TimeTrack(time.Now(), "RegisterGroupPubKey", map[string]interface{}{"GroupID": fmt.Sprintf("%x", idPubkey.GroupID)})
 			//			defer logger.TimeTrack(time.Now(), "askMembers", nil)
 			fmt.Println("RegisterGroupPubKey got pubkey")
 
@@ -1536,13 +871,14 @@ func (e *ethAdaptor) RegisterGroupPubKey(ctx context.Context, IdWithPubKeys chan
 				}
 				fmt.Println("RegisterGroupPubKey func")
 
-				if idPubkey, ok := p[0].([5]*big.Int); ok {
-					groupId := idPubkey[0]
+				if idPubkey, ok := p[0].(*dkg.GroupPubKey); ok {
 					var pubKey [4]*big.Int
-					copy(pubKey[:], idPubkey[1:])
+					if pubKey, err = pubKeyToBig(idPubkey.PubKey); err != nil {
+						return
+					}
 					select {
 					default:
-						tx, err = proxy.RegisterGroupPubKey(groupId, pubKey)
+						tx, err = proxy.RegisterGroupPubKey(idPubkey.GroupID, pubKey)
 					case <-ctx.Done():
 						err = ctx.Err()
 					}
@@ -1552,13 +888,13 @@ func (e *ethAdaptor) RegisterGroupPubKey(ctx context.Context, IdWithPubKeys chan
 			// define parameters
 			var params []interface{}
 			params = append(params, idPubkey)
-			reply := e.set(ctx, params, f)
+			reply := e.set(ctx, "RegisterGroupPubKey", params, f)
 
 			select {
 			case r, ok := <-reply:
 				if ok {
 					if r.err == nil {
-						fmt.Println("RegisterGroupPubKey response ", fmt.Sprintf("%x", r.tx.Hash()))
+						fmt.Println("RegisterGroupPubKey response ", fmt.Sprintf("%x", r.hash))
 					} else {
 						fmt.Println("RegisterGroupPubKey error ", r.err)
 						select {
@@ -1607,13 +943,13 @@ func (e *ethAdaptor) SetRandomNum(ctx context.Context, signatures chan *vss.Sign
 			}
 			var params []interface{}
 			params = append(params, signature)
-			reply := e.set(ctx, params, f)
+			reply := e.set(ctx, "SetRandomNum", params, f)
 			for {
 				select {
 				case r, ok := <-reply:
 					if ok {
 						if r.err == nil {
-							fmt.Println("RegisterGroupPubKey response ", fmt.Sprintf("%x", r.tx.Hash()))
+							fmt.Println("RegisterGroupPubKey response ", fmt.Sprintf("%x", r.hash))
 						} else {
 							fmt.Println("RegisterGroupPubKey error ", r.err)
 							select {
@@ -1667,13 +1003,13 @@ func (e *ethAdaptor) DataReturn(ctx context.Context, signatures chan *vss.Signat
 			}
 			var params []interface{}
 			params = append(params, signature)
-			reply := e.set(ctx, params, f)
+			reply := e.set(ctx, "DataReturn", params, f)
 
 			select {
 			case r, ok := <-reply:
 				if ok {
 					if r.err == nil {
-						fmt.Println("DataReturn response ", fmt.Sprintf("%x", r.tx.Hash()))
+						fmt.Println("DataReturn response ", fmt.Sprintf("%x", r.hash))
 					} else {
 						fmt.Println("DataReturn error ", r.err)
 						select {
@@ -1693,44 +1029,75 @@ func (e *ethAdaptor) DataReturn(ctx context.Context, signatures chan *vss.Signat
 	return
 }
 
-// SubscribeEvent is a log subscription operation
-func (e *ethAdaptor) SubscribeEvent(subscribeTypes []int) (chan interface{}, chan error) {
+// SubscribeEvent subscribes to the given types of onchain events. Each event
+// passing filter is delivered once on the returned subscription, which buffers
+// up to chanBuffer events. A nil filter passes every event.
+func (e *ethAdaptor) SubscribeEvent(chanBuffer int, filter EventFilter, subscribeTypes ...int) (*Subscription, error) {
+	if e.bus == nil {
+		return nil, errors.New("adaptor not started")
+	}
+	return e.bus.subscribe(chanBuffer, filter, subscribeTypes...)
+}
+
+// watchEvent watches one type of event on every connected client
+func (e *ethAdaptor) watchEvent(ctx context.Context, subscribeType int) (chan interface{}, chan interface{}) {
 	var eventList []chan interface{}
 	var errcs []chan interface{}
+	if watch, ok := crTable[subscribeType]; ok {
+		for _, cr := range e.crs {
+			out, errc := watch(ctx, cr)
+			eventList = append(eventList, out)
+			errcs = append(errcs, errc)
+		}
+	} else if watch, ok := proxyTable[subscribeType]; ok {
+		for _, proxy := range e.proxies {
+			out, errc := watch(ctx, proxy)
+			eventList = append(eventList, out)
+			errcs = append(errcs, errc)
+		}
+	}
+	return merge(ctx, eventList...), merge(ctx, errcs...)
+}
+
+// PastEvents returns the events of the given types emitted from block from to
+// block to, in chain order. The endpoints are asked one at a time in the order
+// the health monitor picks until one answers.
+func (e *ethAdaptor) PastEvents(ctx context.Context, from, to uint64, subscribeTypes ...int) (events []*LogCommon, err error) {
+	if e.health == nil {
+		return nil, errors.New("adaptor not started")
+	}
+	err = errors.New("No eth client")
+	for _, idx := range e.health.readOrder() {
+		if events, err = e.filterEvents(ctx, idx, from, to, subscribeTypes); err == nil {
+			return
+		}
+		e.logger.Error(err)
+	}
+	return
+}
+
+func (e *ethAdaptor) filterEvents(ctx context.Context, idx int, from, to uint64, subscribeTypes []int) ([]*LogCommon, error) {
+	var events []*LogCommon
 	for _, subscribeType := range subscribeTypes {
-		if subscribeType >= SubscribeCommitrevealLogStartCommitreveal {
-			for i := 0; i < len(e.proxies); i++ {
-				fmt.Println("Subscribe CR Event ", i)
-				cr := e.crs[i]
-				if cr == nil {
-					continue
-				}
-				ctx := e.ctx
-				if ctx == nil {
-					continue
-				}
-				out, errc := crTable[subscribeType](ctx, cr)
-				eventList = append(eventList, out)
-				errcs = append(errcs, errc)
-			}
-		} else {
-			for i := 0; i < len(e.proxies); i++ {
-				fmt.Println("SubscribeEvent ", i, subscribeType)
-				proxy := e.proxies[i]
-				if proxy == nil {
-					continue
-				}
-				ctx := e.ctx
-				if ctx == nil {
-					continue
-				}
-				out, errc := proxyTable[subscribeType](ctx, proxy)
-				eventList = append(eventList, out)
-				errcs = append(errcs, errc)
-			}
+		var logs []*LogCommon
+		var err error
+		if filter, ok := crFilterTable[subscribeType]; ok {
+			logs, err = filter(ctx, e.crs[idx], from, to)
+		} else if filter, ok := proxyFilterTable[subscribeType]; ok {
+			logs, err = filter(ctx, e.proxies[idx], from, to)
+		}
+		if err != nil {
+			return nil, err
 		}
+		events = append(events, logs...)
 	}
-	return firstEvent(e.ctx, merge(e.ctx, eventList...)), convertToError(e.ctx, merge(e.ctx, errcs...))
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].BlockN != events[j].BlockN {
+			return events[i].BlockN < events[j].BlockN
+		}
+		return events[i].Index < events[j].Index
+	})
+	return events, nil
 }
 
 // LastRandomness return the last system random number
@@ -2089,7 +1456,7 @@ func (e *ethAdaptor) IsPendingNode(ctx context.Context, id []byte) (result bool,
 }
 
 // GroupPubKey returns the group public key of the given index
-func (e *ethAdaptor) GroupPubKey(ctx context.Context, idx int) (result [4]*big.Int, err error) {
+func (e *ethAdaptor) GroupPubKey(ctx context.Context, idx int) (pubKey []byte, err error) {
 	f := func(ctx context.Context, client *ethclient.Client, proxy *dosproxy.DosproxySession, p interface{}) (chan interface{}, chan interface{}) {
 		outc := make(chan interface{})
 		errc := make(chan interface{})
@@ -2121,7 +1488,7 @@ func (e *ethAdaptor) GroupPubKey(ctx context.Context, idx int) (result [4]*big.I
 
 	vr, ve := e.get(ctx, f, big.NewInt(int64(idx)))
 	if v, ok := vr.([4]*big.Int); ok {
-		result = v
+		pubKey = pubKeyFromBig(v)
 	}
 	if v, ok := ve.(error); ok {
 		err = v
@@ -2148,7 +1515,7 @@ func (e *ethAdaptor) PendingNonce(ctx context.Context) (result uint64, err error
 							return
 						case errc <- err:
 						}
-						b, err := client.BalanceAt(ctx, e.key.Address, nil)
+						b, err := client.BalanceAt(ctx, e.signer.Address(), nil)
 						if err != nil {
 							//Post http i/o timeout
 							fmt.Println("BalanceAt err ", err)
@@ -2169,7 +1536,7 @@ func (e *ethAdaptor) PendingNonce(ctx context.Context) (result uint64, err error
 		return outc, errc
 	}
 
-	vr, ve := e.get(ctx, f, e.key.Address)
+	vr, ve := e.get(ctx, f, e.signer.Address())
 	fmt.Println("PendingNonce ", vr, ve)
 	if v, ok := vr.(*big.Int); ok {
 		result = v.Uint64()
@@ -2181,7 +1548,7 @@ func (e *ethAdaptor) PendingNonce(ctx context.Context) (result uint64, err error
 
 }
 
-// Balance returns the wei balance of the node account.
+// Balance returns the balance of the node account in ether.
 func (e *ethAdaptor) Balance(ctx context.Context) (result *big.Float, err error) {
 	f := func(ctx context.Context, client *ethclient.Client, proxy *dosproxy.DosproxySession, p interface{}) (chan interface{}, chan interface{}) {
 		outc := make(chan interface{})
@@ -2207,9 +1574,9 @@ func (e *ethAdaptor) Balance(ctx context.Context) (result *big.Float, err error)
 		return outc, errc
 	}
 
-	vr, ve := e.get(ctx, f, e.key.Address)
-	if v, ok := vr.(*big.Float); ok {
-		result = v
+	vr, ve := e.get(ctx, f, e.signer.Address())
+	if v, ok := vr.(*big.Int); ok {
+		result = toUnits(v, 18)
 	}
 	if v, ok := ve.(error); ok {
 		err = v
@@ -2217,6 +1584,30 @@ func (e *ethAdaptor) Balance(ctx context.Context) (result *big.Float, err error)
 	return
 }
 
+// GasPrice returns the price per gas in wei that a transaction sent now
+// pays with the fee policy of the adaptor: the legacy gas price, or the base
+// fee and the tip up to the fee cap for a dynamic-fee transaction
+func (e *ethAdaptor) GasPrice(ctx context.Context) (*big.Int, error) {
+	client := e.currentRPC()
+	if client == nil || e.auth == nil {
+		return nil, errors.New("No eth client")
+	}
+	var baseFee *big.Int
+	if e.feePolicy.txType != TxTypeLegacy {
+		var err error
+		if baseFee, err = headBaseFee(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+	if baseFee == nil {
+		if e.feePolicy.txType == TxTypeDynamic {
+			return nil, errNoBaseFee
+		}
+		return new(big.Int).Set(e.auth.GasPrice), nil
+	}
+	return e.feePolicy.gasPrice(ctx, client, baseFee), nil
+}
+
 // CurrentBlock return the block number of the latest known header
 func (e *ethAdaptor) CurrentBlock(ctx context.Context) (result uint64, err error) {
 	f := func(ctx context.Context, client *ethclient.Client, proxy *dosproxy.DosproxySession, p interface{}) (chan interface{}, chan interface{}) {
@@ -2252,76 +1643,6 @@ func (e *ethAdaptor) CurrentBlock(ctx context.Context) (result uint64, err error
 }
 
 // Address gets the string representation of the underlying address.
-func (e *ethAdaptor) Address() (addr common.Address) {
-	return e.key.Address
-}
-
-func convertToError(ctx context.Context, i chan interface{}) (out chan error) {
-	out = make(chan error)
-	go func() {
-		defer close(out)
-		for {
-			select {
-			case e, ok := <-i:
-				if !ok {
-					return
-				}
-				if err, ok := e.(error); ok {
-					select {
-					case out <- err:
-					case <-ctx.Done():
-					}
-				}
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-	return out
-}
-
-func firstEvent(ctx context.Context, source chan interface{}) (out chan interface{}) {
-	out = make(chan interface{})
-
-	go func() {
-		defer close(out)
-		visited := make(map[string]uint64)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case event, ok := <-source:
-				if !ok {
-					return
-				}
-				if content, ok := event.(*LogCommon); ok {
-					if content.Removed {
-						continue
-					}
-					var bytes []byte
-					bytes = append(bytes, content.Raw.Data...)
-					bytes = append(bytes, new(big.Int).SetUint64(content.BlockN).Bytes()...)
-					nHash := sha256.Sum256(bytes)
-
-					identity := string(nHash[:])
-					if visited[identity] == 0 {
-						visited[identity] = content.BlockN
-						select {
-						case out <- content.log:
-						case <-ctx.Done():
-						}
-						go func(identity string) {
-							select {
-							case <-ctx.Done():
-							case <-time.After(100 * 15 * time.Second):
-								delete(visited, identity)
-							}
-						}(identity)
-					}
-				}
-			}
-		}
-	}()
-
-	return
+func (e *ethAdaptor) Address() (addr []byte) {
+	return e.signer.Address().Bytes()
 }
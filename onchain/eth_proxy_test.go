@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/DOSNetwork/core/configuration"
 )
 
 const (
@@ -15,6 +17,7 @@ const (
 
 var (
 	urls           = []string{}
+	chainID        = uint64(4)
 	proxyAddr      = ""
 	crAddr         = ""
 	credentialPath = ""
@@ -27,7 +30,7 @@ func TestGetPendingNonce(t *testing.T) {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestConcurrentSend Failed, got an Error : %s.", err.Error())
 		return
@@ -49,7 +52,7 @@ func TestLastUpdatedBlock(t *testing.T) {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestConcurrentSend Failed, got an Error : %s.", err.Error())
 		return
@@ -72,7 +75,7 @@ func TestLastUpdatedBlock(t *testing.T) {
 		t.Errorf("LastRandomness() Failed , got an error: %s.", e.Error())
 	}
 	fmt.Println("LastRandomness()	 ", rand)
-	pending, e := adaptor.IsPendingNode(context.Background(), adaptor.Address().Bytes())
+	pending, e := adaptor.IsPendingNode(context.Background(), adaptor.Address())
 	if e != nil {
 		t.Errorf("LastRandomness() Failed , got an error: %s.", e.Error())
 	}
@@ -85,13 +88,18 @@ func TestConcurrentSend(t *testing.T) {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestConcurrentSend Failed, got an error : %s.", err.Error())
 		return
 	}
 
-	sink, errc := adaptor.SubscribeEvent([]int{SubscribeDosproxyUpdateGroupToPick})
+	sub, err := adaptor.SubscribeEvent(10, nil, SubscribeDosproxyUpdateGroupToPick)
+	if err != nil {
+		t.Errorf("SubscribeEvent Failed, got an error : %s.", err.Error())
+		return
+	}
+	sink, errc := sub.Events(), sub.Err()
 
 	ctx := context.Background()
 	for i := 3; i < 8; i++ {
@@ -109,7 +117,7 @@ L:
 		select {
 		case event, ok := <-sink:
 			if ok {
-				switch content := event.(type) {
+				switch content := event.Event.(type) {
 				case *LogUpdateGroupToPick:
 					fmt.Println("DOSProxyUpdateGroupToPick ", int(content.NewNum.Uint64()))
 					result = result + int(content.NewNum.Uint64())
@@ -134,13 +142,18 @@ func TestCommitReveal(t *testing.T) {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
 
-	sink, errc := adaptor.SubscribeEvent([]int{SubscribeCommitrevealLogStartCommitreveal})
+	sub, err := adaptor.SubscribeEvent(10, nil, SubscribeCommitrevealLogStartCommitReveal)
+	if err != nil {
+		t.Errorf("SubscribeEvent Failed, got an error : %s.", err.Error())
+		return
+	}
+	sink, errc := sub.Events(), sub.Err()
 
 	ctx := context.Background()
 	err = adaptor.AddToWhitelist(ctx, adaptor.Address())
@@ -157,7 +170,7 @@ func TestCommitReveal(t *testing.T) {
 		select {
 		case event, ok := <-sink:
 			if ok {
-				switch content := event.(type) {
+				switch content := event.Event.(type) {
 				case *LogStartCommitReveal:
 					_ = content
 					fmt.Println("LogStartCommitreveal ")
@@ -178,13 +191,18 @@ func TestSetErrorHandling(t *testing.T) {
 		t.Errorf("TestCommitReveal Failed, got an error : %s.", err.Error())
 		return
 	}
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestConcurrentSend Failed, got an error : %s.", err.Error())
 		return
 	}
 
-	sink, errc := adaptor.SubscribeEvent([]int{SubscribeDosproxyUpdateGroupToPick})
+	sub, err := adaptor.SubscribeEvent(10, nil, SubscribeDosproxyUpdateGroupToPick)
+	if err != nil {
+		t.Errorf("SubscribeEvent Failed, got an error : %s.", err.Error())
+		return
+	}
+	sink, errc := sub.Events(), sub.Err()
 
 	ctx := context.Background()
 	for i := 3; i < 8; i++ {
@@ -199,7 +217,7 @@ L:
 		select {
 		case event, ok := <-sink:
 			if ok {
-				switch content := event.(type) {
+				switch content := event.Event.(type) {
 				case *LogUpdateGroupToPick:
 					fmt.Println("DOSProxyUpdateGroupToPick ", int(content.NewNum.Uint64()))
 					result = result + int(content.NewNum.Uint64())
@@ -226,15 +244,20 @@ func TestReconnect(t *testing.T) {
 		return
 	}
 
-	adaptor, err := NewEthAdaptor(key, proxyAddr, crAddr, urls)
+	adaptor, err := NewEthAdaptor(NewKeySigner(key.PrivateKey), configuration.ChainConfig{DOSProxyAddress: proxyAddr, CommitReveal: crAddr, RemoteNodeAddressPool: urls, ChainID: chainID})
 	if err != nil {
 		t.Errorf("TestConcurrentSend Failed, got an error : %s.", err.Error())
 		return
 	}
 S:
 	adaptor.Start()
-	var errcList []chan error
-	sink, errc := adaptor.SubscribeEvent([]int{SubscribeDosproxyUpdateGroupToPick, SubscribeDosproxyUpdateGroupSize})
+	var errcList []<-chan error
+	sub, err := adaptor.SubscribeEvent(10, nil, SubscribeDosproxyUpdateGroupToPick, SubscribeDosproxyUpdateGroupSize)
+	if err != nil {
+		t.Errorf("SubscribeEvent Failed, got an error : %s.", err.Error())
+		return
+	}
+	sink, errc := sub.Events(), sub.Err()
 	errcList = append(errcList, errc)
 
 	ctx := context.Background()
@@ -254,7 +277,7 @@ L:
 		select {
 		case event, ok := <-sink:
 			if ok {
-				switch content := event.(type) {
+				switch content := event.Event.(type) {
 				case *LogUpdateGroupToPick:
 					fmt.Println("DOSProxyUpdateGroupToPick ", int(content.OldNum.Uint64()), "->", int(content.NewNum.Uint64()))
 					result = result + int(content.NewNum.Uint64())
@@ -275,8 +298,9 @@ L:
 			}
 		}
 	}
-	adaptor.End()
-	adaptor.UpdateWsUrls([]string{"ws://52.24.205.11:8546"})
+	if err := adaptor.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
 	goto S
 }
 
@@ -308,7 +332,7 @@ L:
 	for {
 		select {
 		case event := <-sink:
-			switch content := event.(type) {
+			switch content := event.Event.(type) {
 			case *LogUpdateGroupToPick:
 				fmt.Println("DOSProxyUpdateGroupToPick ", int(content.NewNum.Uint64()), content.Removed, result)
 				if content.Removed != true {
@@ -0,0 +1,177 @@
+package onchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/onchain/dospayment"
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// keyStoreScryptN and keyStoreScryptP are the scrypt parameters that new and
+// re-encrypted keys are stored with
+var keyStoreScryptN, keyStoreScryptP = keystore.StandardScryptN, keystore.StandardScryptP
+
+func openKeyStore(credentialPath string) *keystore.KeyStore {
+	return keystore.NewKeyStore(credentialPath, keyStoreScryptN, keyStoreScryptP)
+}
+
+//WalletAccounts returns the accounts in the keystore at credentialPath. The
+//first one is the node account that ReadEthKey reads.
+func WalletAccounts(credentialPath string) []accounts.Account {
+	return openKeyStore(credentialPath).Accounts()
+}
+
+// findAccount returns the account of address in ks, or the node account if
+// address is empty
+func findAccount(ks *keystore.KeyStore, address string) (accounts.Account, error) {
+	if address == "" {
+		if len(ks.Accounts()) < 1 {
+			return accounts.Account{}, errors.New("No Account")
+		}
+		return ks.Accounts()[0], nil
+	}
+	if !common.IsHexAddress(address) {
+		return accounts.Account{}, fmt.Errorf("invalid address %s", address)
+	}
+	return ks.Find(accounts.Account{Address: common.HexToAddress(address)})
+}
+
+//ImportEthKey imports key, either a hex encoded private key or a keystore JSON
+//file encrypted with passphrase, into the keystore at credentialPath and
+//encrypts it with newPassphrase
+func ImportEthKey(credentialPath string, key []byte, passphrase, newPassphrase string) (addr common.Address, err error) {
+	ks := openKeyStore(credentialPath)
+	var account accounts.Account
+	key = bytes.TrimSpace(key)
+	if bytes.HasPrefix(key, []byte("{")) {
+		account, err = ks.Import(key, passphrase, newPassphrase)
+	} else {
+		privateKey, er := crypto.HexToECDSA(strings.TrimPrefix(string(key), "0x"))
+		if er != nil {
+			return addr, errors.New("neither a private key nor a keystore file")
+		}
+		account, err = ks.ImportECDSA(privateKey, newPassphrase)
+	}
+	return account.Address, err
+}
+
+//ExportEthKey returns the keystore JSON of address, or of the node account if
+//address is empty, encrypted with newPassphrase instead of passphrase
+func ExportEthKey(credentialPath, address, passphrase, newPassphrase string) ([]byte, error) {
+	ks := openKeyStore(credentialPath)
+	account, err := findAccount(ks, address)
+	if err != nil {
+		return nil, err
+	}
+	return ks.Export(account, passphrase, newPassphrase)
+}
+
+//ChangeEthKeyPassphrase encrypts the key of address, or of the node account if
+//address is empty, with newPassphrase instead of passphrase
+func ChangeEthKeyPassphrase(credentialPath, address, passphrase, newPassphrase string) error {
+	ks := openKeyStore(credentialPath)
+	account, err := findAccount(ks, address)
+	if err != nil {
+		return err
+	}
+	return ks.Update(account, passphrase, newPassphrase)
+}
+
+//AccountStatus is the state of a node account on an Ethereum chain
+type AccountStatus struct {
+	Address common.Address
+	//Balance is in ether
+	Balance *big.Float
+	//TokenBalance is in DOS tokens
+	TokenBalance *big.Float
+	//MinStake is the number of DOS tokens a node has to hold
+	MinStake *big.Int
+	//Staking reports whether the account holds enough tokens to serve requests
+	Staking     bool
+	PendingNode bool
+}
+
+//GetAccountStatus reads the status of addr from the first endpoint of config
+//that serves its chain
+func GetAccountStatus(ctx context.Context, config configuration.ChainConfig, addr common.Address) (status *AccountStatus, err error) {
+	dialCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var conn *ethConn
+	for c := range dialToEth(dialCtx, config.RemoteNodeAddressPool) {
+		if config.ChainID != 0 && c.chainID != config.ChainID {
+			fmt.Println(c.url, "serves chain ", c.chainID, " want ", config.ChainID)
+			c.client.Close()
+			continue
+		}
+		conn = c
+		break
+	}
+	if conn == nil {
+		return nil, errors.New("No any working eth client")
+	}
+	defer conn.client.Close()
+	opts := &bind.CallOpts{Context: ctx}
+
+	status = &AccountStatus{Address: addr}
+	wei, err := conn.client.BalanceAt(ctx, addr, nil)
+	if err != nil {
+		return
+	}
+	status.Balance = toUnits(wei, 18)
+
+	proxy, err := dosproxy.NewDosproxyCaller(common.HexToAddress(config.DOSProxyAddress), conn.client)
+	if err != nil {
+		return
+	}
+	next, err := proxy.PendingNodeList(opts, addr)
+	if err != nil {
+		return
+	}
+	status.PendingNode = next != common.Address{}
+
+	payment, err := dospayment.NewDospaymentCaller(common.HexToAddress(config.DOSPaymentAddress), conn.client)
+	if err != nil {
+		return
+	}
+	if status.Staking, err = payment.FromValidStakingNode(opts, addr); err != nil {
+		return
+	}
+	if status.MinStake, err = payment.MinStake(opts); err != nil {
+		return
+	}
+	tokenAddr, err := payment.NetworkToken(opts)
+	if err != nil {
+		return
+	}
+	token, err := dospayment.NewERC20ICaller(tokenAddr, conn.client)
+	if err != nil {
+		return
+	}
+	decimals, err := token.Decimals(opts)
+	if err != nil {
+		return
+	}
+	balance, err := token.BalanceOf(opts, addr)
+	if err != nil {
+		return
+	}
+	status.TokenBalance = toUnits(balance, decimals.Int64())
+	return
+}
+
+// toUnits converts an amount of the smallest unit of a token to tokens
+func toUnits(amount *big.Int, decimals int64) *big.Float {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(unit))
+}
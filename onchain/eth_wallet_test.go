@@ -0,0 +1,135 @@
+package onchain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestWalletKeys(t *testing.T) {
+	keyStoreScryptN, keyStoreScryptP = keystore.LightScryptN, keystore.LightScryptP
+	defer func() { keyStoreScryptN, keyStoreScryptP = keystore.StandardScryptN, keystore.StandardScryptP }()
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	nodeAddr, err := GenEthkey(dir, "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	addr, err := ImportEthKey(dir, []byte("0x"+hex.EncodeToString(crypto.FromECDSA(key))+"\n"), "", "imported")
+	if err != nil || addr != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("import private key, got: %x, %v.", addr, err)
+	}
+	if _, err := ImportEthKey(dir, []byte("not a key"), "", "imported"); err == nil {
+		t.Error("garbage imported")
+	}
+	accounts := WalletAccounts(dir)
+	if len(accounts) != 2 || accounts[0].Address != nodeAddr {
+		t.Fatalf("accounts %v, want the node account %x first", accounts, nodeAddr)
+	}
+
+	if err := ChangeEthKeyPassphrase(dir, addr.Hex(), "wrong", "changed"); err == nil {
+		t.Error("passphrase changed with a wrong passphrase")
+	}
+	if err := ChangeEthKeyPassphrase(dir, addr.Hex(), "imported", "changed"); err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, err := ExportEthKey(dir, addr.Hex(), "changed", "exported")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := keystore.DecryptKey(keyJSON, "exported")
+	if err != nil || exported.Address != addr {
+		t.Fatalf("exported key, got: %v, %v.", exported, err)
+	}
+	if nodeKey, err := ReadEthKey(dir, "node"); err != nil || nodeKey.Address != nodeAddr {
+		t.Errorf("node key, got: %v, %v.", nodeKey, err)
+	}
+
+	// A keystore file imports into another wallet
+	other, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(other)
+	if imported, err := ImportEthKey(other, keyJSON, "exported", "again"); err != nil || imported != addr {
+		t.Errorf("import keystore file, got: %x, %v.", imported, err)
+	}
+}
+
+func TestGetAccountStatus(t *testing.T) {
+	addr := common.HexToAddress("0xabc")
+	proxyAddr, paymentAddr, tokenAddr := common.HexToAddress("0x10"), common.HexToAddress("0x20"), common.HexToAddress("0x30")
+	word := func(v *big.Int) string { return hexutil.Encode(common.LeftPadBytes(v.Bytes(), 32)) }
+	selector := func(sig string) string { return hexutil.Encode(crypto.Keccak256([]byte(sig))[:4]) }
+	eighteen := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	calls := map[string]string{
+		proxyAddr.Hex() + selector("pendingNodeList(address)"):        word(big.NewInt(1)),
+		paymentAddr.Hex() + selector("fromValidStakingNode(address)"): word(big.NewInt(1)),
+		paymentAddr.Hex() + selector("minStake()"):                    word(big.NewInt(50000)),
+		paymentAddr.Hex() + selector("networkToken()"):                word(tokenAddr.Big()),
+		tokenAddr.Hex() + selector("decimals()"):                      word(big.NewInt(18)),
+		tokenAddr.Hex() + selector("balanceOf(address)"):              word(new(big.Int).Mul(big.NewInt(60000), eighteen)),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			resp["result"] = "0x4"
+		case "eth_getBalance":
+			resp["result"] = hexutil.EncodeBig(new(big.Int).Div(eighteen, big.NewInt(2)))
+		case "eth_call":
+			var call struct {
+				To   common.Address `json:"to"`
+				Data hexutil.Bytes  `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+			resp["result"] = calls[call.To.Hex()+hexutil.Encode(call.Data[:4])]
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	config := configuration.ChainConfig{
+		DOSProxyAddress:       proxyAddr.Hex(),
+		DOSPaymentAddress:     paymentAddr.Hex(),
+		RemoteNodeAddressPool: []string{server.URL},
+		ChainID:               5,
+	}
+	if _, err := GetAccountStatus(context.Background(), config, addr); err == nil {
+		t.Error("endpoint of another chain used")
+	}
+	config.ChainID = 4
+	status, err := GetAccountStatus(context.Background(), config, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Balance.Text('f', 1) != "0.5" || status.TokenBalance.Text('f', 0) != "60000" || status.MinStake.Int64() != 50000 || !status.Staking || !status.PendingNode {
+		t.Errorf("status %+v", status)
+	}
+}
@@ -2,17 +2,19 @@ package onchain
 
 import (
 	"math/big"
-
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 )
 
+//LogCommon is the envelope of an onchain event. It carries the log metadata
+//and one of the Log* event structs in Event. Raw is the event as the chain
+//reported it, a types.Log on Ethereum.
 type LogCommon struct {
+	Type    int
 	Tx      string
 	BlockN  uint64
+	Index   uint
 	Removed bool
-	Raw     types.Log
-	log     interface{}
+	Raw     interface{}
+	Event   interface{}
 }
 
 //LogUrl is an onchain event that DOSProxy requests a query result from the specified URL
@@ -44,8 +46,8 @@ type LogValidationResult struct {
 	TrafficType uint8
 	TrafficId   *big.Int
 	Message     []byte
-	Signature   [2]*big.Int
-	PubKey      [4]*big.Int
+	Signature   []byte
+	PubKey      []byte
 	Pass        bool
 	Version     uint8
 }
@@ -60,6 +62,7 @@ type LogGroupingInitiated struct {
 //LogInsufficientWorkingGroup is an onchain event that means there are no enough working groups to mix with pending nodes to form a new group
 type LogInsufficientWorkingGroup struct {
 	NumWorkingGroups *big.Int
+	NumPendingGroups *big.Int
 }
 
 //LogInsufficientPendingNode is an onchain event that means there are no enough pending nodes to form a new group
@@ -76,14 +79,14 @@ type LogGrouping struct {
 //LogPublicKeyAccepted is an onchain event that DOSProxy receives enough suggested pubkey so accepts it as a new pubkey
 type LogPublicKeyAccepted struct {
 	GroupId          *big.Int
+	PubKey           []byte
 	WorkingGroupSize *big.Int
 }
 
 //LogPublicKeySuggested is an onchain event that DOSProxy accepts a suggested pubkey
 type LogPublicKeySuggested struct {
-	GroupId   *big.Int
-	Count     *big.Int
-	GroupSize *big.Int
+	GroupId *big.Int
+	Count   *big.Int
 }
 
 //LogGroupDissolve is an onchain event that DOSProxy requests to dissolve a specified group
@@ -115,14 +118,14 @@ type LogStartCommitReveal struct {
 //LogCommit is an onchain event that means a secret is committed
 type LogCommit struct {
 	Cid        *big.Int
-	From       common.Address
+	From       []byte
 	Commitment [32]byte
 }
 
 //LogReveal is an onchain event that means a secret is revealed
 type LogReveal struct {
 	Cid    *big.Int
-	From   common.Address
+	From   []byte
 	Secret *big.Int
 }
 
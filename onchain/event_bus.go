@@ -0,0 +1,290 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DOSNetwork/core/log"
+)
+
+const (
+	// defaultEventBuffer is used when a subscriber asks for an unbuffered stream
+	defaultEventBuffer = 1
+	// dedupBlockWindow is how many blocks an event stays in the de-duplication set
+	dedupBlockWindow = 100
+)
+
+// EventFilter reports whether a subscriber wants to receive an event
+type EventFilter func(event *LogCommon) bool
+
+// Subscription is a stream of onchain events of the subscribed types.
+// Events are shared between subscribers and must not be modified.
+type Subscription struct {
+	bus     *eventBus
+	types   []int
+	filter  EventFilter
+	events  chan *LogCommon
+	errc    chan error
+	dropped uint64
+	closed  bool
+}
+
+// Events returns the channel that matching events are delivered on. It is
+// closed when the subscription is unsubscribed, the adaptor is stopped or the
+// connections watching one of the subscribed types are lost.
+func (s *Subscription) Events() <-chan *LogCommon {
+	return s.events
+}
+
+// Err returns the channel that watch errors of the subscribed types are
+// delivered on. It is closed together with the event channel.
+func (s *Subscription) Err() <-chan error {
+	return s.errc
+}
+
+// Dropped returns the number of events discarded because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Unsubscribe stops the delivery of events and closes the subscription channels
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s)
+}
+
+// GroupFilter returns an EventFilter that passes events addressed to a group
+// only if isMember reports the group as one of ours. Events that are not tied
+// to a group always pass.
+func GroupFilter(isMember func(groupID *big.Int) bool) EventFilter {
+	return func(event *LogCommon) bool {
+		var groupID *big.Int
+		switch content := event.Event.(type) {
+		case *LogUrl:
+			groupID = content.DispatchedGroupId
+		case *LogRequestUserRandom:
+			groupID = content.DispatchedGroupId
+		case *LogUpdateRandom:
+			groupID = content.DispatchedGroupId
+		case *LogGroupDissolve:
+			groupID = content.GroupId
+		case *LogPublicKeyAccepted:
+			groupID = content.GroupId
+		case *LogPublicKeySuggested:
+			groupID = content.GroupId
+		case *LogNoPendingGroup:
+			groupID = content.GroupId
+		case *LogPendingGroupRemoved:
+			groupID = content.GroupId
+		default:
+			return true
+		}
+		return groupID != nil && isMember(groupID)
+	}
+}
+
+// eventSource starts watching one subscription type until ctx is done
+type eventSource func(ctx context.Context, subscribeType int) (events chan interface{}, errc chan interface{})
+
+// eventBus fans the events of each subscription type out to its subscribers.
+// A type is only watched while it has at least one subscriber.
+type eventBus struct {
+	ctx    context.Context
+	source eventSource
+	logger log.Logger
+
+	lock     sync.Mutex
+	subs     map[int]map[*Subscription]bool
+	watchers map[int]context.CancelFunc
+	closed   bool
+}
+
+func newEventBus(ctx context.Context, source eventSource, logger log.Logger) *eventBus {
+	b := &eventBus{
+		ctx:      ctx,
+		source:   source,
+		logger:   logger,
+		subs:     make(map[int]map[*Subscription]bool),
+		watchers: make(map[int]context.CancelFunc),
+	}
+	go func() {
+		<-ctx.Done()
+		b.close()
+	}()
+	return b
+}
+
+func (b *eventBus) subscribe(chanBuffer int, filter EventFilter, subscribeTypes ...int) (*Subscription, error) {
+	if len(subscribeTypes) == 0 {
+		return nil, fmt.Errorf("no subscribe type")
+	}
+	for _, subscribeType := range subscribeTypes {
+		if _, ok := subscriptionEvents[subscribeType]; !ok {
+			return nil, fmt.Errorf("unknown subscribe type %d", subscribeType)
+		}
+	}
+	if chanBuffer < defaultEventBuffer {
+		chanBuffer = defaultEventBuffer
+	}
+	s := &Subscription{
+		bus:    b,
+		types:  subscribeTypes,
+		filter: filter,
+		events: make(chan *LogCommon, chanBuffer),
+		errc:   make(chan error, 1),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return nil, fmt.Errorf("event bus closed")
+	}
+	for _, subscribeType := range subscribeTypes {
+		if b.subs[subscribeType] == nil {
+			b.subs[subscribeType] = make(map[*Subscription]bool)
+		}
+		b.subs[subscribeType][s] = true
+		if b.watchers[subscribeType] == nil {
+			b.watch(subscribeType)
+		}
+	}
+	return s, nil
+}
+
+func (b *eventBus) unsubscribe(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if s.closed {
+		return
+	}
+	for _, subscribeType := range s.types {
+		delete(b.subs[subscribeType], s)
+		if len(b.subs[subscribeType]) == 0 {
+			delete(b.subs, subscribeType)
+			if cancel := b.watchers[subscribeType]; cancel != nil {
+				cancel()
+				delete(b.watchers, subscribeType)
+			}
+		}
+	}
+	s.closed = true
+	close(s.events)
+	close(s.errc)
+}
+
+func (b *eventBus) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	for subscribeType, subs := range b.subs {
+		for s := range subs {
+			if !s.closed {
+				s.closed = true
+				close(s.events)
+				close(s.errc)
+			}
+		}
+		delete(b.subs, subscribeType)
+	}
+	for subscribeType, cancel := range b.watchers {
+		cancel()
+		delete(b.watchers, subscribeType)
+	}
+}
+
+// watch must be called with the lock held
+func (b *eventBus) watch(subscribeType int) {
+	ctx, cancel := context.WithCancel(b.ctx)
+	b.watchers[subscribeType] = cancel
+	events, errc := b.source(ctx, subscribeType)
+	go func() {
+		// The same log arrives once per connected client, so keep the ones we
+		// have seen until they are dedupBlockWindow blocks old.
+		visited := make(map[string]uint64)
+		var pruned uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					// Every client stopped watching, so end the subscriptions
+					// and let their owners resubscribe.
+					b.end(ctx, subscribeType)
+					return
+				}
+				content, ok := event.(*LogCommon)
+				if !ok {
+					continue
+				}
+				identity := fmt.Sprintf("%s-%d-%t", content.Tx, content.Index, content.Removed)
+				if _, ok := visited[identity]; ok {
+					continue
+				}
+				visited[identity] = content.BlockN
+				if content.BlockN > pruned+dedupBlockWindow {
+					for id, blockN := range visited {
+						if blockN+dedupBlockWindow < content.BlockN {
+							delete(visited, id)
+						}
+					}
+					pruned = content.BlockN
+				}
+				content.Type = subscribeType
+				b.publish(subscribeType, content)
+			case e, ok := <-errc:
+				if !ok {
+					errc = nil
+					continue
+				}
+				if err, ok := e.(error); ok {
+					b.publishErr(subscribeType, err)
+				}
+			}
+		}
+	}()
+}
+
+// end unsubscribes all subscribers of a type whose watch is over
+func (b *eventBus) end(ctx context.Context, subscribeType int) {
+	b.lock.Lock()
+	var subs []*Subscription
+	if ctx.Err() == nil {
+		for s := range b.subs[subscribeType] {
+			subs = append(subs, s)
+		}
+	}
+	b.lock.Unlock()
+	for _, s := range subs {
+		b.unsubscribe(s)
+	}
+}
+
+func (b *eventBus) publish(subscribeType int, event *LogCommon) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for s := range b.subs[subscribeType] {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+			b.logger.Event("EventDropped", map[string]interface{}{"Event": subscriptionEvents[subscribeType], "Tx": event.Tx})
+		}
+	}
+}
+
+func (b *eventBus) publishErr(subscribeType int, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for s := range b.subs[subscribeType] {
+		select {
+		case s.errc <- err:
+		default:
+		}
+	}
+}
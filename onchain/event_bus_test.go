@@ -0,0 +1,242 @@
+package onchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeSource feeds the bus from channels controlled by the test
+type fakeSource struct {
+	lock    sync.Mutex
+	events  map[int]chan interface{}
+	errcs   map[int]chan interface{}
+	cancels map[int]context.Context
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		events:  make(map[int]chan interface{}),
+		errcs:   make(map[int]chan interface{}),
+		cancels: make(map[int]context.Context),
+	}
+}
+
+func (f *fakeSource) watch(ctx context.Context, subscribeType int) (chan interface{}, chan interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.events[subscribeType] = make(chan interface{})
+	f.errcs[subscribeType] = make(chan interface{})
+	f.cancels[subscribeType] = ctx
+	return f.events[subscribeType], f.errcs[subscribeType]
+}
+
+func (f *fakeSource) send(t *testing.T, subscribeType int, event *LogCommon) {
+	f.lock.Lock()
+	c := f.events[subscribeType]
+	f.lock.Unlock()
+	select {
+	case c <- event:
+	case <-time.After(time.Second):
+		t.Fatalf("bus is not watching type %d", subscribeType)
+	}
+}
+
+func (f *fakeSource) watchCtx(subscribeType int) context.Context {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.cancels[subscribeType]
+}
+
+func newTestBus(t *testing.T) (*eventBus, *fakeSource, context.CancelFunc) {
+	log.Init(common.Address{}.Bytes())
+	ctx, cancel := context.WithCancel(context.Background())
+	source := newFakeSource()
+	return newEventBus(ctx, source.watch, log.New("module", "EventBusTest")), source, cancel
+}
+
+func urlEvent(tx string, groupID int64) *LogCommon {
+	return &LogCommon{
+		Tx:     tx,
+		BlockN: 1,
+		Event:  &LogUrl{QueryId: big.NewInt(1), DispatchedGroupId: big.NewInt(groupID)},
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) *LogCommon {
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+	}
+	return nil
+}
+
+func expectNone(t *testing.T, sub *Subscription) {
+	select {
+	case event, ok := <-sub.Events():
+		if ok {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventBusMultipleSubscribers(t *testing.T) {
+	bus, source, cancel := newTestBus(t)
+	defer cancel()
+
+	first, err := bus.subscribe(5, nil, SubscribeLogUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := bus.subscribe(5, nil, SubscribeLogUrl, SubscribeLogGrouping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source.send(t, SubscribeLogUrl, urlEvent("0x1", 1))
+	for _, sub := range []*Subscription{first, second} {
+		event := receive(t, sub)
+		if event.Type != SubscribeLogUrl || event.Tx != "0x1" {
+			t.Errorf("got type %d tx %s, want type %d tx 0x1", event.Type, event.Tx, SubscribeLogUrl)
+		}
+		if _, ok := event.Event.(*LogUrl); !ok {
+			t.Errorf("got %T, want *LogUrl", event.Event)
+		}
+	}
+
+	// The same log reported by a second client is delivered only once.
+	source.send(t, SubscribeLogUrl, urlEvent("0x1", 1))
+	expectNone(t, first)
+
+	// A removed log is a different event and carries the flag.
+	removed := urlEvent("0x1", 1)
+	removed.Removed = true
+	source.send(t, SubscribeLogUrl, removed)
+	if event := receive(t, first); !event.Removed {
+		t.Error("removed flag not delivered")
+	}
+}
+
+func TestEventBusFilter(t *testing.T) {
+	bus, source, cancel := newTestBus(t)
+	defer cancel()
+
+	mine := GroupFilter(func(groupID *big.Int) bool { return groupID.Int64() == 7 })
+	sub, err := bus.subscribe(5, mine, SubscribeLogUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.send(t, SubscribeLogUrl, urlEvent("0x1", 3))
+	source.send(t, SubscribeLogUrl, urlEvent("0x2", 7))
+	if event := receive(t, sub); event.Tx != "0x2" {
+		t.Errorf("got tx %s, want 0x2", event.Tx)
+	}
+	expectNone(t, sub)
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus, source, cancel := newTestBus(t)
+	defer cancel()
+
+	urls, err := bus.subscribe(5, nil, SubscribeLogUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, err := bus.subscribe(5, nil, SubscribeLogUrl, SubscribeLogGrouping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupingCtx := source.watchCtx(SubscribeLogGrouping)
+
+	both.Unsubscribe()
+	if _, ok := <-both.Events(); ok {
+		t.Error("events channel not closed by Unsubscribe")
+	}
+	select {
+	case <-groupingCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("type without subscribers is still watched")
+	}
+	if err := source.watchCtx(SubscribeLogUrl).Err(); err != nil {
+		t.Errorf("type with subscribers stopped: %v", err)
+	}
+
+	source.send(t, SubscribeLogUrl, urlEvent("0x1", 1))
+	receive(t, urls)
+	both.Unsubscribe()
+}
+
+func TestEventBusBufferAndErrors(t *testing.T) {
+	bus, source, cancel := newTestBus(t)
+
+	sub, err := bus.subscribe(1, nil, SubscribeLogUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.send(t, SubscribeLogUrl, urlEvent("0x1", 1))
+	source.send(t, SubscribeLogUrl, urlEvent("0x2", 1))
+	deadline := time.Now().Add(time.Second)
+	for sub.Dropped() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if sub.Dropped() != 1 {
+		t.Errorf("dropped, got: %d, want: 1.", sub.Dropped())
+	}
+
+	source.lock.Lock()
+	errc := source.errcs[SubscribeLogUrl]
+	source.lock.Unlock()
+	errc <- errors.New("connection lost")
+	select {
+	case err := <-sub.Err():
+		if err.Error() != "connection lost" {
+			t.Errorf("got error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("watch error not delivered")
+	}
+
+	if _, err := bus.subscribe(1, nil, numSubscribeTypes); err == nil {
+		t.Error("unknown subscribe type accepted")
+	}
+
+	// Losing every connection of one type ends the subscriptions watching it.
+	lost, err := bus.subscribe(1, nil, SubscribeLogGrouping, SubscribeLogUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.lock.Lock()
+	close(source.events[SubscribeLogGrouping])
+	source.lock.Unlock()
+	select {
+	case _, ok := <-lost.Events():
+		if ok {
+			t.Error("unexpected event")
+		}
+	case <-time.After(time.Second):
+		t.Error("subscription not ended when its watch stopped")
+	}
+	if _, err := bus.subscribe(1, nil, SubscribeLogUrl); err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+	if event := receive(t, sub); event.Tx != "0x1" {
+		t.Errorf("got tx %s, want 0x1", event.Tx)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("events channel not closed when the bus stops")
+	}
+	if _, err := bus.subscribe(1, nil, SubscribeLogUrl); err == nil {
+		t.Error("subscribed to a stopped bus")
+	}
+}
@@ -0,0 +1,106 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+)
+
+const (
+	followReconnectDelay = 10 * time.Second
+	// followCatchUpInterval is how often a follower fetches the events its
+	// subscription may have dropped
+	followCatchUpInterval = 5 * time.Minute
+)
+
+//TrafficNames names the request types
+var TrafficNames = map[uint8]string{
+	TrafficSystemRandom: "SystemRandom",
+	TrafficUserRandom:   "UserRandom",
+	TrafficUserQuery:    "UserQuery",
+}
+
+//EventSource is the part of ProxyAdapter that delivers the events
+type EventSource interface {
+	SubscribeEvent(chanBuffer int, filter EventFilter, subscribeTypes ...int) (*Subscription, error)
+	Reconnect() error
+}
+
+//Follow hands every DOSProxy and CommitReveal event of source to handle until
+//ctx is done, reconnecting when the subscription fails. If catchUp is set, it
+//fetches the events the subscription did not deliver: before subscribing, once
+//subscribed, for the blocks mined in between, and every few minutes after.
+func Follow(ctx context.Context, source EventSource, logger log.Logger, handle func(event *LogCommon), catchUp func(ctx context.Context) error) {
+	runCatchUp := func() {
+		if catchUp == nil {
+			return
+		}
+		if err := catchUp(ctx); err != nil {
+			logger.Error(err)
+		}
+	}
+	for {
+		runCatchUp()
+		sub, err := source.SubscribeEvent(100, nil, AllSubscribeTypes()...)
+		if err != nil {
+			logger.Error(err)
+		} else {
+			runCatchUp()
+			follow(ctx, sub, logger, handle, runCatchUp)
+			sub.Unsubscribe()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		for {
+			if err := source.Reconnect(); err == nil {
+				break
+			} else {
+				logger.Error(err)
+			}
+			select {
+			case <-time.After(followReconnectDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func follow(ctx context.Context, sub *Subscription, logger log.Logger, handle func(event *LogCommon), catchUp func()) {
+	ticker := time.NewTicker(followCatchUpInterval)
+	defer ticker.Stop()
+	events, errc := sub.Events(), sub.Err()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			handle(event)
+		case <-ticker.C:
+			catchUp()
+		case err, ok := <-errc:
+			if !ok {
+				return
+			}
+			logger.Error(err)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//RequestKey identifies a request by its type and id
+func RequestKey(trafficType uint8, id *big.Int) string {
+	return fmt.Sprintf("%d-%x", trafficType, id)
+}
+
+//HexID formats a group, request or process id as the REST APIs show it
+func HexID(id *big.Int) string {
+	return fmt.Sprintf("%x", id)
+}
@@ -0,0 +1,412 @@
+// +build ignore
+
+// gen_events generates eth_events.go, the subscription and filter tables that
+// adapt every Watch* and Filter* method of the abigen DOSProxy and CommitReveal
+// bindings to the event structs in eventMsg.go. Run it through `go generate` after regenerating the
+// contract bindings.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	eventMsgFile = "eventMsg.go"
+	outputFile   = "eth_events.go"
+)
+
+// contract describes an abigen binding whose events should be adapted.
+type contract struct {
+	// Pkg is the package name of the binding.
+	Pkg string
+	// File is the path of the binding relative to the onchain package.
+	File string
+	// Type is the contract type name used by abigen, e.g. Dosproxy.
+	Type string
+	// Name is the contract name used in doc comments.
+	Name string
+	// Session is the name of the session parameter in the handler.
+	Session string
+	// Table is the name of the generated subscription table.
+	Table string
+	// FilterTable is the name of the generated table of past events.
+	FilterTable string
+	// Bare drops the contract name from subscription constants of Log* events.
+	Bare bool
+}
+
+var contracts = []contract{
+	{Pkg: "dosproxy", File: "dosproxy/DOSProxy.go", Type: "Dosproxy", Name: "DOSProxy", Session: "proxy", Table: "proxyTable", FilterTable: "proxyFilterTable", Bare: true},
+	{Pkg: "commitreveal", File: "commitreveal/CommitReveal.go", Type: "Commitreveal", Name: "CommitReveal", Session: "cr", Table: "crTable", FilterTable: "crFilterTable"},
+}
+
+// conversions lists the struct fields in eventMsg.go whose name or type differs
+// from the binding. The value is an expression over the binding event i.
+var conversions = map[string]map[string]string{
+	"LogGrouping": {
+		"NodeId": "addressesToBytes(i.NodeId)",
+	},
+	"LogGroupingInitiated": {
+		"NumPendingNodes":   "i.PendingNodePool",
+		"GroupSize":         "i.Groupsize",
+		"GroupingThreshold": "i.Groupingthreshold",
+	},
+	"LogPublicKeyAccepted": {
+		"PubKey":           "pubKeyFromBig(i.PubKey)",
+		"WorkingGroupSize": "i.NumWorkingGroups",
+	},
+	"LogPublicKeySuggested": {
+		"Count": "i.PubKeyCount",
+	},
+	"LogValidationResult": {
+		"Signature": "signatureFromBig(i.Signature)",
+		"PubKey":    "pubKeyFromBig(i.PubKey)",
+	},
+}
+
+type field struct {
+	Name string
+	Type string
+	Expr string
+}
+
+type event struct {
+	Contract contract
+	Name     string
+	Const    string
+	Struct   string
+	Indexed  int
+	Fields   []field
+	// Declare is set when eventMsg.go has no struct for this event.
+	Declare bool
+}
+
+func main() {
+	declared, err := parseStructs(eventMsgFile, "")
+	if err != nil {
+		fatal(err)
+	}
+
+	var events []*event
+	generated := make(map[string]*event)
+	for _, c := range contracts {
+		evs, err := parseContract(c)
+		if err != nil {
+			fatal(err)
+		}
+		for _, ev := range evs {
+			target, ok := declared[ev.Struct]
+			if !ok {
+				// Both contracts inherit Ownable, so its events share one struct.
+				if _, ok := generated[ev.Struct]; !ok {
+					ev.Declare = true
+					generated[ev.Struct] = ev
+				}
+				target = neutralFields(ev.Fields)
+			}
+			if ev.Fields, err = mapFields(ev, target); err != nil {
+				fatal(err)
+			}
+			events = append(events, ev)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		Contracts []contract
+		Events    []*event
+	}{contracts, events}); err != nil {
+		fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fatal(fmt.Errorf("format %s: %v", outputFile, err))
+	}
+	if err := ioutil.WriteFile(outputFile, src, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "gen_events:", err)
+	os.Exit(1)
+}
+
+// parseContract returns an event for every Watch* method on the filterer of c.
+func parseContract(c contract) ([]*event, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, c.File, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	structs, err := parseStructs(c.File, c.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*event
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || !strings.HasPrefix(fn.Name.Name, "Watch") {
+			continue
+		}
+		if recv := exprString(fset, fn.Recv.List[0].Type); recv != "*"+c.Type+"Filterer" {
+			continue
+		}
+		name := strings.TrimPrefix(fn.Name.Name, "Watch")
+		fields, ok := structs[c.Type+name]
+		if !ok {
+			return nil, fmt.Errorf("%s: no event struct for %s", c.File, fn.Name.Name)
+		}
+		ev := &event{
+			Contract: c,
+			Name:     name,
+			Const:    "Subscribe" + c.Type + name,
+			Struct:   name,
+			Fields:   fields,
+		}
+		if !strings.HasPrefix(name, "Log") {
+			ev.Struct = "Log" + name
+		} else if c.Bare {
+			ev.Const = "Subscribe" + name
+		}
+		// opts and sink are followed by one filter parameter per indexed argument.
+		for _, p := range fn.Type.Params.List[2:] {
+			ev.Indexed += len(p.Names)
+		}
+		events = append(events, ev)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events, nil
+}
+
+// parseStructs collects the exported fields of struct types in file whose name
+// starts with prefix. The abigen Raw field is skipped for bindings.
+func parseStructs(file, prefix string) (map[string][]field, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	structs := make(map[string][]field)
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || !strings.HasPrefix(spec.Name.Name, prefix) {
+			return false
+		}
+		var fields []field
+		for _, fl := range st.Fields.List {
+			for _, name := range fl.Names {
+				if prefix != "" && name.Name == "Raw" {
+					continue
+				}
+				if !ast.IsExported(name.Name) {
+					continue
+				}
+				fields = append(fields, field{Name: name.Name, Type: exprString(fset, fl.Type)})
+			}
+		}
+		structs[spec.Name.Name] = fields
+		return false
+	})
+	return structs, nil
+}
+
+// neutralFields declares addresses as bytes so that event structs do not
+// depend on the chain.
+func neutralFields(fields []field) (neutral []field) {
+	for _, f := range fields {
+		if f.Type == "common.Address" {
+			f.Type = "[]byte"
+		}
+		neutral = append(neutral, f)
+	}
+	return
+}
+
+// mapFields resolves the source expression of every field in target.
+func mapFields(ev *event, target []field) ([]field, error) {
+	source := make(map[string]field)
+	for _, f := range ev.Fields {
+		source[f.Name] = f
+	}
+	var fields []field
+	for _, f := range target {
+		if expr, ok := conversions[ev.Struct][f.Name]; ok {
+			f.Expr = expr
+		} else if s, ok := source[f.Name]; ok && s.Type == f.Type {
+			f.Expr = "i." + f.Name
+		} else if ok && s.Type == "common.Address" && f.Type == "[]byte" {
+			f.Expr = "i." + f.Name + ".Bytes()"
+		} else {
+			return nil, fmt.Errorf("%s.%s: no matching field in %s%s", ev.Struct, f.Name, ev.Contract.Type, ev.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func exprString(fset *token.FileSet, x ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, x)
+	return buf.String()
+}
+
+var tmpl = template.Must(template.New(outputFile).Funcs(template.FuncMap{
+	"nils": func(n int) string { return strings.Repeat(", nil", n) },
+}).Parse(`// Code generated by gen_events.go - DO NOT EDIT.
+
+package onchain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/DOSNetwork/core/onchain/commitreveal"
+	"github.com/DOSNetwork/core/onchain/dosproxy"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const (
+{{- range $i, $e := .Events}}
+	//{{$e.Const}} is a log type to subscribe the event {{$e.Name}}
+	{{$e.Const}}{{if eq $i 0}} = iota{{end}}
+{{- end}}
+	numSubscribeTypes
+)
+
+// AllSubscribeTypes returns the log types of every DOSProxy and CommitReveal event
+func AllSubscribeTypes() []int {
+	types := make([]int, numSubscribeTypes)
+	for i := range types {
+		types[i] = i
+	}
+	return types
+}
+
+// subscriptionEvents maps each subscription type to the contract event it watches.
+var subscriptionEvents = map[int]string{
+{{- range .Events}}
+	{{.Const}}: "{{.Name}}",
+{{- end}}
+}
+
+// subscriptionStructs creates the event struct of each subscription type.
+var subscriptionStructs = map[int]func() interface{}{
+{{- range .Events}}
+	{{.Const}}: func() interface{} { return new({{.Struct}}) },
+{{- end}}
+}
+{{range $c := .Contracts}}
+var {{$c.Table}} = map[int]func(ctx context.Context, {{$c.Session}} *{{$c.Pkg}}.{{$c.Type}}Session) (chan interface{}, chan interface{}){
+{{- range $.Events}}{{if eq .Contract.Type $c.Type}}
+	{{.Const}}: func(ctx context.Context, {{$c.Session}} *{{$c.Pkg}}.{{$c.Type}}Session) (chan interface{}, chan interface{}) {
+		out := make(chan interface{})
+		errc := make(chan interface{})
+		opt := &bind.WatchOpts{}
+		go func() {
+			transitChan := make(chan *{{$c.Pkg}}.{{$c.Type}}{{.Name}})
+			defer close(transitChan)
+			defer close(errc)
+			defer close(out)
+			sub, err := {{$c.Session}}.Contract.Watch{{.Name}}(opt, transitChan{{nils .Indexed}})
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for {
+				var log *LogCommon
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case err := <-sub.Err():
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				case i := <-transitChan:
+					l := &{{.Struct}}{
+{{- range .Fields}}
+						{{.Name}}: {{.Expr}},
+{{- end}}
+					}
+					log = &LogCommon{
+						Tx:      i.Raw.TxHash.Hex(),
+						BlockN:  i.Raw.BlockNumber,
+						Index:   i.Raw.Index,
+						Removed: i.Raw.Removed,
+						Raw:     i.Raw,
+						Event:   l,
+					}
+				}
+				select {
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				case out <- log:
+				}
+			}
+		}()
+		return out, errc
+	},
+{{- end}}{{end}}
+}
+
+var {{$c.FilterTable}} = map[int]func(ctx context.Context, {{$c.Session}} *{{$c.Pkg}}.{{$c.Type}}Session, from, to uint64) ([]*LogCommon, error){
+{{- range $.Events}}{{if eq .Contract.Type $c.Type}}
+	{{.Const}}: func(ctx context.Context, {{$c.Session}} *{{$c.Pkg}}.{{$c.Type}}Session, from, to uint64) ([]*LogCommon, error) {
+		it, err := {{$c.Session}}.Contract.Filter{{.Name}}(&bind.FilterOpts{Start: from, End: &to, Context: ctx}{{nils .Indexed}})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var logs []*LogCommon
+		for it.Next() {
+			i := it.Event
+			l := &{{.Struct}}{
+{{- range .Fields}}
+				{{.Name}}: {{.Expr}},
+{{- end}}
+			}
+			logs = append(logs, &LogCommon{
+				Tx:      i.Raw.TxHash.Hex(),
+				BlockN:  i.Raw.BlockNumber,
+				Index:   i.Raw.Index,
+				Removed: i.Raw.Removed,
+				Raw:     i.Raw,
+				Event:   l,
+			})
+		}
+		return logs, it.Error()
+	},
+{{- end}}{{end}}
+}
+{{end}}
+{{- range .Events}}{{if .Declare}}
+//{{.Struct}} is an onchain event that {{.Contract.Name}} emits as {{.Name}}
+type {{.Struct}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}{{end}}`))
@@ -0,0 +1,291 @@
+package onchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// signTimeout leaves an external signer time to ask its operator
+	signTimeout = 2 * time.Minute
+)
+
+//Signer signs transactions and messages for the node account
+type Signer interface {
+	//Address returns the account the signer signs for
+	Address() common.Address
+	//SignTx signs a legacy transaction with EIP-155 replay protection
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	//SignDynamicFeeTx returns the EIP-2718 encoding of the signed transaction and its hash
+	SignDynamicFeeTx(ctx context.Context, tx *DynamicFeeTx) (raw []byte, hash common.Hash, err error)
+	//SignText returns the 65 byte [R || S || V] signature of the EIP-191
+	//personal message text, with V 0 or 1
+	SignText(ctx context.Context, text []byte) ([]byte, error)
+}
+
+// keySigner signs with a private key held in the client process
+type keySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+//NewKeySigner creates a Signer that signs with key
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key, crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (s *keySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *keySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewEIP155Signer(chainID), s.key)
+}
+
+func (s *keySigner) SignDynamicFeeTx(ctx context.Context, tx *DynamicFeeTx) ([]byte, common.Hash, error) {
+	return tx.sign(s.key)
+}
+
+func (s *keySigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	return crypto.Sign(textHash(text), s.key)
+}
+
+// textHash is the EIP-191 hash of a personal message
+func textHash(text []byte) []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text)))
+}
+
+// remoteSigner asks a signer speaking the Clef external API to sign, so that
+// the key is never in the client process
+type remoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// signTxArgs are the transaction fields of account_signTransaction
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+type signTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+//DialSigner connects to an external signer at endpoint, a http(s) url or the
+//path of a unix socket, that signs for account. An empty account selects the
+//only account of the signer.
+func DialSigner(ctx context.Context, endpoint, account string) (Signer, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []common.Address
+	if err := client.CallContext(ctx, &accounts, "account_list"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	s := &remoteSigner{client: client}
+	switch {
+	case account != "":
+		s.address = common.HexToAddress(account)
+		for _, a := range accounts {
+			if a == s.address {
+				return s, nil
+			}
+		}
+		err = fmt.Errorf("signer does not hold account %s", account)
+	case len(accounts) == 1:
+		s.address = accounts[0]
+		return s, nil
+	default:
+		err = fmt.Errorf("signer holds %d accounts, choose one", len(accounts))
+	}
+	client.Close()
+	return nil, err
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) signTx(ctx context.Context, args *signTxArgs) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, signTimeout)
+	defer cancel()
+	var result signTxResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	return result.Raw, nil
+}
+
+func (s *remoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	raw, err := s.signTx(ctx, &signTxArgs{
+		From:     s.address,
+		To:       tx.To(),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Data:     tx.Data(),
+		ChainID:  (*hexutil.Big)(chainID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, signed); err != nil {
+		return nil, err
+	}
+	// The operator of the signer may have changed the transaction
+	if sender, err := types.Sender(types.NewEIP155Signer(chainID), signed); err != nil || sender != s.address {
+		return nil, errors.New("signer signed for another account")
+	}
+	if signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() || signed.GasPrice().Cmp(tx.GasPrice()) != 0 ||
+		signed.Value().Cmp(tx.Value()) != 0 || !bytes.Equal(signed.Data(), tx.Data()) || !sameRecipient(signed.To(), tx.To()) {
+		return nil, errors.New("signer changed the transaction")
+	}
+	return signed, nil
+}
+
+func (s *remoteSigner) SignDynamicFeeTx(ctx context.Context, tx *DynamicFeeTx) (raw []byte, hash common.Hash, err error) {
+	raw, err = s.signTx(ctx, &signTxArgs{
+		From:                 s.address,
+		To:                   tx.To,
+		Gas:                  hexutil.Uint64(tx.Gas),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap),
+		Value:                (*hexutil.Big)(tx.Value),
+		Nonce:                hexutil.Uint64(tx.Nonce),
+		Data:                 tx.Data,
+		ChainID:              (*hexutil.Big)(tx.ChainID),
+	})
+	if err != nil {
+		return
+	}
+	signed, sender, err := decodeDynamicFeeTx(raw)
+	if err != nil {
+		return
+	}
+	if sender != s.address {
+		return nil, hash, errors.New("signer signed for another account")
+	}
+	if signed.ChainID.Cmp(tx.ChainID) != 0 || signed.Nonce != tx.Nonce || signed.Gas != tx.Gas ||
+		signed.GasFeeCap.Cmp(tx.GasFeeCap) != 0 || signed.GasTipCap.Cmp(tx.GasTipCap) != 0 ||
+		signed.Value.Cmp(tx.Value) != 0 || !bytes.Equal(signed.Data, tx.Data) || !sameRecipient(signed.To, tx.To) {
+		return nil, hash, errors.New("signer changed the transaction")
+	}
+	hash = common.BytesToHash(crypto.Keccak256(raw))
+	return
+}
+
+func (s *remoteSigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, signTimeout)
+	defer cancel()
+	var sig hexutil.Bytes
+	if err := s.client.CallContext(ctx, &sig, "account_signData", "text/plain", s.address, hexutil.Bytes(text)); err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("signature of %d bytes", len(sig))
+	}
+	// Clef returns V as 27 or 28
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(textHash(text), sig)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(*pub) != s.address {
+		return nil, errors.New("signer signed for another account")
+	}
+	return sig, nil
+}
+
+// signedDynamicFeeTx is the payload of a signed dynamic-fee transaction.
+// rlp does not flatten embedded structs.
+type signedDynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"`
+	Value      *big.Int
+	Data       []byte
+	AccessList []accessTuple
+	V, R, S    *big.Int
+}
+
+// decodeDynamicFeeTx decodes the EIP-2718 encoding of a signed dynamic-fee
+// transaction and recovers its sender
+func decodeDynamicFeeTx(raw []byte) (tx *DynamicFeeTx, sender common.Address, err error) {
+	if len(raw) == 0 || raw[0] != dynamicFeeTxType {
+		return nil, sender, errors.New("not a dynamic-fee transaction")
+	}
+	var signed signedDynamicFeeTx
+	if err = rlp.DecodeBytes(raw[1:], &signed); err != nil {
+		return
+	}
+	tx = &DynamicFeeTx{signed.ChainID, signed.Nonce, signed.GasTipCap, signed.GasFeeCap, signed.Gas, signed.To, signed.Value, signed.Data, signed.AccessList}
+	payload, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return
+	}
+	if !signed.V.IsUint64() || signed.V.Uint64() > 1 {
+		return nil, sender, errors.New("invalid signature")
+	}
+	sig := make([]byte, 65)
+	copy(sig[32-len(signed.R.Bytes()):32], signed.R.Bytes())
+	copy(sig[64-len(signed.S.Bytes()):64], signed.S.Bytes())
+	sig[64] = byte(signed.V.Uint64())
+	pub, err := crypto.SigToPub(crypto.Keccak256(append([]byte{dynamicFeeTxType}, payload...)), sig)
+	if err != nil {
+		return
+	}
+	return tx, crypto.PubkeyToAddress(*pub), nil
+}
+
+func sameRecipient(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// newSignerTransactor creates a transactor that leaves transactions unsigned.
+// The feeBackend signs each of them once it knows their fees.
+func newSignerTransactor(signer Signer) *bind.TransactOpts {
+	from := signer.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return tx, nil
+		},
+	}
+}
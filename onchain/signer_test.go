@@ -0,0 +1,125 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// clefNode serves the Clef external API for its accounts and signs every
+// request with key
+func clefNode(accounts []common.Address, key *ecdsa.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "account_list":
+			resp["result"] = accounts
+		case "account_signTransaction":
+			var args signTxArgs
+			json.Unmarshal(req.Params[0], &args)
+			var raw []byte
+			if args.MaxFeePerGas != nil {
+				tx := &DynamicFeeTx{(*big.Int)(args.ChainID), uint64(args.Nonce), (*big.Int)(args.MaxPriorityFeePerGas), (*big.Int)(args.MaxFeePerGas), uint64(args.Gas), args.To, (*big.Int)(args.Value), args.Data, nil}
+				raw, _, _ = tx.sign(key)
+			} else {
+				tx := types.NewTransaction(uint64(args.Nonce), *args.To, (*big.Int)(args.Value), uint64(args.Gas), (*big.Int)(args.GasPrice), args.Data)
+				signed, _ := types.SignTx(tx, types.NewEIP155Signer((*big.Int)(args.ChainID)), key)
+				raw, _ = rlp.EncodeToBytes(signed)
+			}
+			resp["result"] = map[string]interface{}{"raw": hexutil.Bytes(raw)}
+		case "account_signData":
+			var data hexutil.Bytes
+			json.Unmarshal(req.Params[2], &data)
+			sig, _ := crypto.Sign(textHash(data), key)
+			sig[64] += 27
+			resp["result"] = hexutil.Bytes(sig)
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	other, _ := crypto.GenerateKey()
+	ctx := context.Background()
+
+	server := clefNode([]common.Address{address}, key)
+	defer server.Close()
+	signer, err := DialSigner(ctx, server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != address {
+		t.Fatalf("address, got: %x, want: %x.", signer.Address(), address)
+	}
+	if _, err := DialSigner(ctx, server.URL, crypto.PubkeyToAddress(other.PublicKey).Hex()); err == nil {
+		t.Error("account the signer does not hold accepted")
+	}
+
+	chainID := big.NewInt(4)
+	to := common.HexToAddress("0x1")
+	tx := types.NewTransaction(3, to, big.NewInt(0), 21000, big.NewInt(5), []byte{1})
+	signed, err := signer.SignTx(ctx, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, _ := NewKeySigner(key).SignTx(ctx, tx, chainID)
+	if signed.Hash() != local.Hash() {
+		t.Error("remote and local signature of a legacy transaction differ")
+	}
+
+	dtx := &DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(9), Gas: 21000, To: &to, Value: big.NewInt(0), Data: []byte{1}}
+	raw, hash, err := signer.SignDynamicFeeTx(ctx, dtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localRaw, localHash, _ := NewKeySigner(key).SignDynamicFeeTx(ctx, dtx)
+	if hash != localHash || string(raw) != string(localRaw) {
+		t.Error("remote and local signature of a dynamic-fee transaction differ")
+	}
+
+	sig, err := signer.SignText(ctx, []byte("dos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, err := crypto.SigToPub(textHash([]byte("dos")), sig); err != nil || crypto.PubkeyToAddress(*pub) != address {
+		t.Error("text signature does not recover the account")
+	}
+
+	// A signer signing with another key is refused
+	rogue := clefNode([]common.Address{address}, other)
+	defer rogue.Close()
+	signer, err = DialSigner(ctx, rogue.URL, address.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.SignTx(ctx, tx, chainID); err == nil {
+		t.Error("legacy transaction signed by another account accepted")
+	}
+	if _, _, err := signer.SignDynamicFeeTx(ctx, dtx); err == nil {
+		t.Error("dynamic-fee transaction signed by another account accepted")
+	}
+	if _, err := signer.SignText(ctx, []byte("dos")); err == nil {
+		t.Error("text signed by another account accepted")
+	}
+}
@@ -0,0 +1,278 @@
+package onchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type tmEventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// tmEvent is an event of a transaction as reported by block_results
+type tmEvent struct {
+	Type       string             `json:"type"`
+	Attributes []tmEventAttribute `json:"attributes"`
+}
+
+func (ev tmEvent) attribute(key string) (string, bool) {
+	for _, a := range ev.Attributes {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+type tmBlockResults struct {
+	TxsResults []struct {
+		Code   uint32    `json:"code"`
+		Events []tmEvent `json:"events"`
+	} `json:"txs_results"`
+}
+
+type tmBlock struct {
+	Block struct {
+		Data struct {
+			Txs [][]byte `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// tmEventTypes maps the event names of the DOS application to subscription
+// types. Events both contracts declare map to the DOSProxy one.
+var tmEventTypes = func() map[string]int {
+	types := make(map[string]int)
+	var subscribeTypes []int
+	for t := range subscriptionEvents {
+		subscribeTypes = append(subscribeTypes, t)
+	}
+	sort.Ints(subscribeTypes)
+	for _, t := range subscribeTypes {
+		if _, ok := types[subscriptionEvents[t]]; !ok {
+			types[subscriptionEvents[t]] = t
+		}
+	}
+	return types
+}()
+
+// tmWatcher receives the events of one subscription type until ctx is done
+type tmWatcher struct {
+	ctx  context.Context
+	out  chan interface{}
+	errc chan interface{}
+}
+
+// tmWatchers are the watchers served by one event poller
+type tmWatchers struct {
+	lock     sync.Mutex
+	watchers map[int][]*tmWatcher
+	stopped  bool
+}
+
+func newTmWatchers() *tmWatchers {
+	return &tmWatchers{watchers: make(map[int][]*tmWatcher)}
+}
+
+// watch registers a watcher of subscribeType with the event poller
+func (ws *tmWatchers) watch(ctx context.Context, subscribeType int) (chan interface{}, chan interface{}) {
+	w := &tmWatcher{ctx, make(chan interface{}), make(chan interface{}, 1)}
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	if ws.stopped {
+		close(w.out)
+		return w.out, w.errc
+	}
+	ws.watchers[subscribeType] = append(ws.watchers[subscribeType], w)
+	return w.out, w.errc
+}
+
+func (ws *tmWatchers) deliver(ctx context.Context, subscribeType int, content *LogCommon) {
+	ws.lock.Lock()
+	watchers := append([]*tmWatcher(nil), ws.watchers[subscribeType]...)
+	ws.lock.Unlock()
+	for _, w := range watchers {
+		select {
+		case w.out <- content:
+		case <-w.ctx.Done():
+			ws.remove(subscribeType, w)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ws *tmWatchers) remove(subscribeType int, w *tmWatcher) {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	watchers := ws.watchers[subscribeType]
+	for i := range watchers {
+		if watchers[i] == w {
+			ws.watchers[subscribeType] = append(watchers[:i], watchers[i+1:]...)
+			close(w.out)
+			return
+		}
+	}
+}
+
+func (ws *tmWatchers) fail(err error) {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	for _, watchers := range ws.watchers {
+		for _, w := range watchers {
+			select {
+			case w.errc <- err:
+			default:
+			}
+		}
+	}
+}
+
+// stop closes every watcher so that their subscriptions end
+func (ws *tmWatchers) stop() {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	ws.stopped = true
+	for _, watchers := range ws.watchers {
+		for _, w := range watchers {
+			close(w.out)
+		}
+	}
+	ws.watchers = nil
+}
+
+// pollEvents reads the events of every block from next on, one poll every
+// pollInterval, and ends all watchers after maxPollFailures failed polls
+func (e *tmAdaptor) pollEvents(ctx context.Context, ws *tmWatchers, next uint64) {
+	defer ws.stop()
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		last, err := e.pollBlocks(ctx, ws, next)
+		next = last + 1
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		failures++
+		if failures >= maxPollFailures {
+			ws.fail(err)
+			return
+		}
+	}
+}
+
+// pollBlocks delivers the events from block from up to the head or the end
+// of the window and returns the last block that was read
+func (e *tmAdaptor) pollBlocks(ctx context.Context, ws *tmWatchers, from uint64) (last uint64, err error) {
+	last = from - 1
+	head, err := e.CurrentBlock(ctx)
+	if err != nil || head < from {
+		return
+	}
+	if head-from >= e.pollBlockWindow {
+		head = from + e.pollBlockWindow - 1
+	}
+	for height := from; height <= head; height++ {
+		err = e.pollBlock(ctx, height, func(subscribeType int, content *LogCommon) {
+			ws.deliver(ctx, subscribeType, content)
+		})
+		if err != nil {
+			return
+		}
+		last = height
+	}
+	return
+}
+
+// pollBlock hands every event of block height to deliver in order
+func (e *tmAdaptor) pollBlock(ctx context.Context, height uint64, deliver func(subscribeType int, content *LogCommon)) error {
+	var results tmBlockResults
+	if err := e.call(ctx, &results, "block_results", fmt.Sprint(height)); err != nil {
+		return err
+	}
+	var block *tmBlock
+	index := uint(0)
+	for i, tx := range results.TxsResults {
+		if tx.Code != 0 {
+			continue
+		}
+		for _, ev := range tx.Events {
+			if ev.Type != tmEventType {
+				continue
+			}
+			if block == nil {
+				block = &tmBlock{}
+				if err := e.call(ctx, block, "block", fmt.Sprint(height)); err != nil {
+					return err
+				}
+			}
+			if i >= len(block.Block.Data.Txs) {
+				return errors.New("block_results do not match the block")
+			}
+			content, subscribeType, err := decodeTmEvent(ev)
+			if err != nil {
+				e.logger.Error(err)
+				continue
+			}
+			content.Tx = tmTxHash(block.Block.Data.Txs[i])
+			content.BlockN = height
+			content.Index = index
+			index++
+			deliver(subscribeType, content)
+		}
+	}
+	return nil
+}
+
+// PastEvents returns the events of the given types in the blocks from from to
+// to, in chain order
+func (e *tmAdaptor) PastEvents(ctx context.Context, from, to uint64, subscribeTypes ...int) ([]*LogCommon, error) {
+	wanted := make(map[int]bool)
+	for _, t := range subscribeTypes {
+		wanted[t] = true
+	}
+	var events []*LogCommon
+	for height := from; height <= to; height++ {
+		err := e.pollBlock(ctx, height, func(subscribeType int, content *LogCommon) {
+			if wanted[subscribeType] {
+				events = append(events, content)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// decodeTmEvent converts an event of the DOS application to the Log* struct
+// it names
+func decodeTmEvent(ev tmEvent) (*LogCommon, int, error) {
+	name, _ := ev.attribute("event")
+	subscribeType, ok := tmEventTypes[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown event %s", name)
+	}
+	data, _ := ev.attribute("data")
+	event := subscriptionStructs[subscribeType]()
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		return nil, 0, fmt.Errorf("event %s: %s", name, err)
+	}
+	return &LogCommon{Raw: ev, Event: event}, subscribeType, nil
+}
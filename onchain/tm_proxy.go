@@ -0,0 +1,543 @@
+package onchain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/share/dkg/pedersen"
+	"github.com/DOSNetwork/core/share/vss/pedersen"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The Tendermint adaptor talks to a chain running the DOS application over
+// the Tendermint RPC. The application is reached through three calls:
+//
+// abci_query with path /dos/<name> and the hex of the JSON encoded argument
+// returns the JSON encoded value of a read, e.g. /dos/groupSize.
+//
+// broadcast_tx_sync takes a tmTx, the JSON encoded method call signed by the
+// node account as an EIP-191 personal message. The application orders the transactions of a sender by Nonce.
+//
+// block_results reports what the application did as events of type dos with
+// an event attribute naming one of the Log* events and a data attribute with
+// its JSON encoding.
+const (
+	tmQueryPath = "/dos/"
+	tmEventType = "dos"
+)
+
+// tmTx is a method call of the DOS application
+type tmTx struct {
+	Method    string
+	Args      json.RawMessage `json:",omitempty"`
+	Sender    []byte
+	Nonce     uint64
+	Signature []byte `json:",omitempty"`
+}
+
+type tmStatus struct {
+	NodeInfo struct {
+		Network string `json:"network"`
+	} `json:"node_info"`
+	SyncInfo struct {
+		LatestBlockHeight uint64 `json:"latest_block_height,string"`
+		CatchingUp        bool   `json:"catching_up"`
+	} `json:"sync_info"`
+}
+
+type tmQueryResult struct {
+	Response struct {
+		Code  uint32 `json:"code"`
+		Log   string `json:"log"`
+		Value []byte `json:"value"`
+	} `json:"response"`
+}
+
+type tmBroadcastResult struct {
+	Code uint32 `json:"code"`
+	Log  string `json:"log"`
+	Hash string `json:"hash"`
+}
+
+type tmAdaptor struct {
+	urls            []string
+	network         string
+	pollInterval    time.Duration
+	pollBlockWindow uint64
+	signer          Signer
+
+	clients    []*rpc.Client
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	bus        *eventBus
+	health     *healthMonitor
+	logger     log.Logger
+
+	// sendLock orders the transactions of this node by nonce
+	sendLock   sync.Mutex
+	nonce      uint64
+	nonceKnown bool
+}
+
+//NewTmAdaptor creates an adaptor for a Tendermint chain running the DOS application
+func NewTmAdaptor(signer Signer, config configuration.ChainConfig) (adaptor *tmAdaptor, err error) {
+	if len(config.RemoteNodeAddressPool) == 0 {
+		return nil, errors.New("no Tendermint endpoint configured")
+	}
+	adaptor = &tmAdaptor{}
+	adaptor.urls = config.RemoteNodeAddressPool
+	adaptor.network = config.Network
+	adaptor.pollInterval = time.Duration(config.PollInterval) * time.Second
+	if adaptor.pollInterval <= 0 {
+		adaptor.pollInterval = defaultPollInterval
+	}
+	adaptor.pollBlockWindow = config.PollBlockWindow
+	if adaptor.pollBlockWindow == 0 {
+		adaptor.pollBlockWindow = defaultPollBlockWindow
+	}
+	adaptor.signer = signer
+
+	//Use account address as ID to init log module
+	log.Init(signer.Address().Bytes()[:])
+	adaptor.logger = log.New("module", "TmProxy")
+	return
+}
+
+//Start connects to every configured endpoint that serves the configured network
+func (e *tmAdaptor) Start() (err error) {
+	e.ctx, e.cancelFunc = context.WithCancel(context.Background())
+	var urls []string
+	var head uint64
+	for _, url := range e.urls {
+		client, er := rpc.Dial(url)
+		if er != nil {
+			fmt.Println(url, ":Dial err ", er)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(e.ctx, healthCheckTimeout)
+		var status tmStatus
+		er = client.CallContext(ctx, &status, "status")
+		cancel()
+		switch {
+		case er != nil:
+			fmt.Println(url, ":status err ", er)
+		case status.SyncInfo.CatchingUp:
+			fmt.Println(url, " is catching up")
+		case e.network != "" && status.NodeInfo.Network != e.network:
+			e.logger.Event("ChainIDMismatch", map[string]interface{}{"Url": url, "ChainID": status.NodeInfo.Network, "Want": e.network})
+		default:
+			e.network = status.NodeInfo.Network
+			if status.SyncInfo.LatestBlockHeight > head {
+				head = status.SyncInfo.LatestBlockHeight
+			}
+			e.clients = append(e.clients, client)
+			urls = append(urls, url)
+			continue
+		}
+		client.Close()
+	}
+	if len(e.clients) == 0 {
+		return errors.New("No any working tendermint client")
+	}
+
+	e.health = newHealthMonitor(urls, e.logger)
+	var heads []headFunc
+	for _, client := range e.clients {
+		client := client
+		heads = append(heads, func(ctx context.Context) (uint64, error) {
+			var status tmStatus
+			err := client.CallContext(ctx, &status, "status")
+			return status.SyncInfo.LatestBlockHeight, err
+		})
+	}
+	go checkHealth(e.ctx, e.health, heads)
+	watchers := newTmWatchers()
+	go e.pollEvents(e.ctx, watchers, head+1)
+	e.bus = newEventBus(e.ctx, watchers.watch, e.logger)
+	return
+}
+
+//End closes the connections to the chain and releases all resources
+func (e *tmAdaptor) End() {
+	e.cancelFunc()
+	for _, client := range e.clients {
+		client.Close()
+	}
+	e.clients = nil
+	e.bus = nil
+	e.health = nil
+	e.sendLock.Lock()
+	e.nonceKnown = false
+	e.sendLock.Unlock()
+}
+
+//Reconnect drops all connections and connects again
+func (e *tmAdaptor) Reconnect() error {
+	e.End()
+	return e.Start()
+}
+
+func (e *tmAdaptor) GetTimeoutCtx(t time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(e.ctx, t)
+}
+
+//AdvertisedEndpoints returns no endpoints, Tendermint endpoints are not shared with peers
+func (e *tmAdaptor) AdvertisedEndpoints() *Endpoints {
+	return &Endpoints{}
+}
+
+//AddPeerEndpoints ignores the endpoints of peers
+func (e *tmAdaptor) AddPeerEndpoints(advertised *Endpoints) {}
+
+// EndpointStatus returns the health of every connected endpoint
+func (e *tmAdaptor) EndpointStatus() []EndpointStatus {
+	return e.health.status()
+}
+
+// FeeReport returns nothing, the DOS application does not charge fees
+func (e *tmAdaptor) FeeReport() []FeeStat {
+	return nil
+}
+
+// call asks the endpoints picked by the health monitor one at a time and
+// returns the first answer
+func (e *tmAdaptor) call(ctx context.Context, result interface{}, method string, args ...interface{}) (err error) {
+	order := e.health.readOrder()
+	if len(order) == 0 {
+		return errors.New("No tendermint client")
+	}
+	for _, i := range order {
+		start := time.Now()
+		err = e.clients[i].CallContext(ctx, result, method, args...)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.health.record(i, time.Since(start), err)
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
+// query reads name from the DOS application
+func (e *tmAdaptor) query(ctx context.Context, name string, arg interface{}, value interface{}) error {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	var result tmQueryResult
+	if err := e.call(ctx, &result, "abci_query", tmQueryPath+name, fmt.Sprintf("%X", data), "0", false); err != nil {
+		return err
+	}
+	if result.Response.Code != 0 {
+		return fmt.Errorf("query %s failed: %s", name, result.Response.Log)
+	}
+	return json.Unmarshal(result.Response.Value, value)
+}
+
+func (e *tmAdaptor) queryUint(ctx context.Context, name string) (result uint64, err error) {
+	err = e.query(ctx, name, nil, &result)
+	return
+}
+
+// send signs a call of method and broadcasts it to the endpoints in write
+// order until one of them accepts it
+func (e *tmAdaptor) send(ctx context.Context, method string, args interface{}) (err error) {
+	defer e.logger.TimeTrack(time.Now(), method, nil)
+	e.sendLock.Lock()
+	defer e.sendLock.Unlock()
+	if !e.nonceKnown {
+		if err = e.query(ctx, "nonce", e.Address(), &e.nonce); err != nil {
+			return
+		}
+		e.nonceKnown = true
+	}
+	tx := &tmTx{Method: method, Sender: e.Address(), Nonce: e.nonce}
+	if args != nil {
+		if tx.Args, err = json.Marshal(args); err != nil {
+			return
+		}
+	}
+	raw, err := signTmTx(ctx, tx, e.signer)
+	if err != nil {
+		return
+	}
+
+	for _, i := range e.health.writeOrder() {
+		var result tmBroadcastResult
+		start := time.Now()
+		err = e.clients[i].CallContext(ctx, &result, "broadcast_tx_sync", raw)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.health.record(i, time.Since(start), err)
+		if err != nil {
+			fmt.Println("Switch to next client to handle request because of e ,", err)
+			continue
+		}
+		if result.Code != 0 {
+			// The nonce may be out of step with the application
+			e.nonceKnown = false
+			return fmt.Errorf("transaction failed: %s", result.Log)
+		}
+		e.nonce++
+		fmt.Println(method, " response ", result.Hash)
+		return nil
+	}
+	if err == nil {
+		err = errors.New("No tendermint client")
+	}
+	return
+}
+
+// signTmTx signs tx with signer and returns its encoding
+func signTmTx(ctx context.Context, tx *tmTx, signer Signer) ([]byte, error) {
+	tx.Signature = nil
+	unsigned, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Signature, err = signer.SignText(ctx, unsigned); err != nil {
+		return nil, err
+	}
+	return json.Marshal(tx)
+}
+
+// tmTxHash returns the hash Tendermint reports for a transaction
+func tmTxHash(raw []byte) string {
+	return fmt.Sprintf("%X", sha256.Sum256(raw))
+}
+
+// sendFrom sends the first value of a channel once it is received
+func (e *tmAdaptor) sendFrom(ctx context.Context, method string, args func() (interface{}, bool)) (errc chan error) {
+	errc = make(chan error)
+	go func() {
+		defer close(errc)
+		arg, ok := args()
+		if !ok {
+			return
+		}
+		if err := e.send(ctx, method, arg); err != nil {
+			fmt.Println(method, " error ", err)
+			select {
+			case errc <- err:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return
+}
+
+// SetRandomNum sends the system random signature of a group
+func (e *tmAdaptor) SetRandomNum(ctx context.Context, signatures chan *vss.Signature) (errc chan error) {
+	return e.sendFrom(ctx, "SetRandomNum", func() (interface{}, bool) {
+		select {
+		case sig, ok := <-signatures:
+			return sig, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	})
+}
+
+// DataReturn sends the signed result of a query or a user random request
+func (e *tmAdaptor) DataReturn(ctx context.Context, signatures chan *vss.Signature) (errc chan error) {
+	return e.sendFrom(ctx, "DataReturn", func() (interface{}, bool) {
+		select {
+		case sig, ok := <-signatures:
+			return sig, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	})
+}
+
+// RegisterGroupPubKey sends the public key a group generated
+func (e *tmAdaptor) RegisterGroupPubKey(ctx context.Context, pubKeys chan *dkg.GroupPubKey) (errc chan error) {
+	return e.sendFrom(ctx, "RegisterGroupPubKey", func() (interface{}, bool) {
+		select {
+		case pubKey, ok := <-pubKeys:
+			return pubKey, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	})
+}
+
+// SetGroupingThreshold sets the grouping threshold
+func (e *tmAdaptor) SetGroupingThreshold(ctx context.Context, threshold uint64) error {
+	return e.send(ctx, "SetGroupingThreshold", map[string]uint64{"Threshold": threshold})
+}
+
+// SetGroupToPick sets the number of groups to pick
+func (e *tmAdaptor) SetGroupToPick(ctx context.Context, groupToPick uint64) error {
+	return e.send(ctx, "SetGroupToPick", map[string]uint64{"GroupToPick": groupToPick})
+}
+
+// SetGroupSize sets the group size
+func (e *tmAdaptor) SetGroupSize(ctx context.Context, size uint64) error {
+	return e.send(ctx, "SetGroupSize", map[string]uint64{"Size": size})
+}
+
+// SetGroupMaturityPeriod sets the group maturity period
+func (e *tmAdaptor) SetGroupMaturityPeriod(ctx context.Context, period uint64) error {
+	return e.send(ctx, "SetGroupMaturityPeriod", map[string]uint64{"Period": period})
+}
+
+// AddToWhitelist allows addr to take part in commit-reveal
+func (e *tmAdaptor) AddToWhitelist(ctx context.Context, addr []byte) error {
+	return e.send(ctx, "AddToWhitelist", map[string][]byte{"Addr": addr})
+}
+
+// StartCommitReveal starts a commit-reveal process
+func (e *tmAdaptor) StartCommitReveal(ctx context.Context, startBlock int64, commitDuration int64, revealDuration int64, revealThreshold int64) error {
+	return e.send(ctx, "StartCommitReveal", map[string]int64{
+		"StartBlock":      startBlock,
+		"CommitDuration":  commitDuration,
+		"RevealDuration":  revealDuration,
+		"RevealThreshold": revealThreshold,
+	})
+}
+
+// Commit commits a secret to the commit-reveal process cid
+func (e *tmAdaptor) Commit(ctx context.Context, cid *big.Int, commitment [32]byte) error {
+	return e.send(ctx, "Commit", map[string]interface{}{"Cid": cid, "Commitment": commitment[:]})
+}
+
+// Reveal reveals a secret to the commit-reveal process cid
+func (e *tmAdaptor) Reveal(ctx context.Context, cid *big.Int, secret *big.Int) error {
+	return e.send(ctx, "Reveal", map[string]*big.Int{"Cid": cid, "Secret": secret})
+}
+
+// RegisterNewNode registers this node as a pending node
+func (e *tmAdaptor) RegisterNewNode(ctx context.Context) error {
+	return e.send(ctx, "RegisterNewNode", nil)
+}
+
+// SignalRandom asks for a new system random number
+func (e *tmAdaptor) SignalRandom(ctx context.Context) error {
+	return e.send(ctx, "SignalRandom", nil)
+}
+
+// SignalGroupFormation asks to form a new group
+func (e *tmAdaptor) SignalGroupFormation(ctx context.Context) error {
+	return e.send(ctx, "SignalGroupFormation", nil)
+}
+
+// SignalGroupDissolve asks to dissolve an expired group
+func (e *tmAdaptor) SignalGroupDissolve(ctx context.Context) error {
+	return e.send(ctx, "SignalGroupDissolve", nil)
+}
+
+// SignalBootstrap asks to bootstrap with the random of commit-reveal process cid
+func (e *tmAdaptor) SignalBootstrap(ctx context.Context, cid *big.Int) error {
+	return e.send(ctx, "SignalBootstrap", map[string]*big.Int{"Cid": cid})
+}
+
+// GetExpiredWorkingGroupSize returns the number of expired working groups
+func (e *tmAdaptor) GetExpiredWorkingGroupSize(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "expiredWorkingGroupSize")
+}
+
+// GroupSize returns the group size
+func (e *tmAdaptor) GroupSize(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "groupSize")
+}
+
+// GetWorkingGroupSize returns the number of working groups
+func (e *tmAdaptor) GetWorkingGroupSize(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "workingGroupSize")
+}
+
+// GroupToPick returns the number of groups to pick
+func (e *tmAdaptor) GroupToPick(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "groupToPick")
+}
+
+// LastUpdatedBlock returns the block the system random was last updated at
+func (e *tmAdaptor) LastUpdatedBlock(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "lastUpdatedBlock")
+}
+
+// NumPendingGroups returns the number of pending groups
+func (e *tmAdaptor) NumPendingGroups(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "pendingGroups")
+}
+
+// NumPendingNodes returns the number of pending nodes
+func (e *tmAdaptor) NumPendingNodes(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "pendingNodes")
+}
+
+// RefreshSystemRandomHardLimit returns the number of blocks a system random is valid for
+func (e *tmAdaptor) RefreshSystemRandomHardLimit(ctx context.Context) (uint64, error) {
+	return e.queryUint(ctx, "systemRandomHardLimit")
+}
+
+// PendingNonce returns the nonce of the next transaction of this node
+func (e *tmAdaptor) PendingNonce(ctx context.Context) (nonce uint64, err error) {
+	err = e.query(ctx, "nonce", e.Address(), &nonce)
+	return
+}
+
+// GroupPubKey returns the public key of the working group of the given index
+func (e *tmAdaptor) GroupPubKey(ctx context.Context, idx int) (pubKey []byte, err error) {
+	err = e.query(ctx, "groupPubKey", idx, &pubKey)
+	return
+}
+
+// IsPendingNode checks whether the node of id is a pending node
+func (e *tmAdaptor) IsPendingNode(ctx context.Context, id []byte) (pending bool, err error) {
+	err = e.query(ctx, "isPendingNode", id, &pending)
+	return
+}
+
+// Balance returns the balance of this node in tokens
+func (e *tmAdaptor) Balance(ctx context.Context) (balance *big.Float, err error) {
+	var tokens string
+	if err = e.query(ctx, "balance", e.Address(), &tokens); err != nil {
+		return
+	}
+	balance, ok := new(big.Float).SetString(strings.TrimSpace(tokens))
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %s", tokens)
+	}
+	return
+}
+
+// GasPrice is zero, the DOS application charges no fees
+func (e *tmAdaptor) GasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+// CurrentBlock returns the latest block height
+func (e *tmAdaptor) CurrentBlock(ctx context.Context) (uint64, error) {
+	var status tmStatus
+	err := e.call(ctx, &status, "status")
+	return status.SyncInfo.LatestBlockHeight, err
+}
+
+// Address returns the address of the node account
+func (e *tmAdaptor) Address() []byte {
+	return e.signer.Address().Bytes()
+}
+
+// SubscribeEvent subscribes to the given types of onchain events. Each event
+// passing filter is delivered once on the returned subscription, which buffers
+// up to chanBuffer events. A nil filter passes every event.
+func (e *tmAdaptor) SubscribeEvent(chanBuffer int, filter EventFilter, subscribeTypes ...int) (*Subscription, error) {
+	if e.bus == nil {
+		return nil, errors.New("adaptor not started")
+	}
+	return e.bus.subscribe(chanBuffer, filter, subscribeTypes...)
+}
@@ -0,0 +1,258 @@
+package onchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/configuration"
+	"github.com/DOSNetwork/core/share/dkg/pedersen"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type tmTxResult struct {
+	Code   uint32    `json:"code"`
+	Events []tmEvent `json:"events"`
+}
+
+type tmMockBlock struct {
+	txs     [][]byte
+	results []tmTxResult
+}
+
+// tmNode is a Tendermint node running a small DOS application that mines
+// every accepted transaction in a block of its own
+type tmNode struct {
+	lock    sync.Mutex
+	network string
+	blocks  []tmMockBlock
+	nonces  map[string]uint64
+	pending map[string]bool
+	pubKeys [][]byte
+}
+
+func newTmNode(network string) *tmNode {
+	return &tmNode{network: network, blocks: make([]tmMockBlock, 5), nonces: make(map[string]uint64), pending: make(map[string]bool)}
+}
+
+func (n *tmNode) serve() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var params []string
+		for _, p := range req.Params {
+			var s string
+			json.Unmarshal(p, &s)
+			params = append(params, s)
+		}
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "status":
+			resp["result"] = map[string]interface{}{
+				"node_info": map[string]interface{}{"network": n.network},
+				"sync_info": map[string]interface{}{"latest_block_height": strconv.Itoa(len(n.blocks)), "catching_up": false},
+			}
+		case "abci_query":
+			data, _ := hex.DecodeString(params[1])
+			value, err := n.query(strings.TrimPrefix(params[0], tmQueryPath), data)
+			response := map[string]interface{}{"code": 0, "value": value}
+			if err != nil {
+				response = map[string]interface{}{"code": 1, "log": err.Error()}
+			}
+			resp["result"] = map[string]interface{}{"response": response}
+		case "broadcast_tx_sync":
+			var raw []byte
+			json.Unmarshal(req.Params[0], &raw)
+			result := map[string]interface{}{"code": 0, "hash": tmTxHash(raw)}
+			if err := n.deliver(raw); err != nil {
+				result = map[string]interface{}{"code": 4, "log": err.Error()}
+			}
+			resp["result"] = result
+		case "block_results", "block":
+			height, _ := strconv.Atoi(params[0])
+			block := n.blocks[height-1]
+			if req.Method == "block" {
+				resp["result"] = map[string]interface{}{"block": map[string]interface{}{"data": map[string]interface{}{"txs": block.txs}}}
+			} else {
+				resp["result"] = map[string]interface{}{"txs_results": block.results}
+			}
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func (n *tmNode) query(name string, data []byte) (interface{}, error) {
+	var arg []byte
+	switch name {
+	case "groupSize":
+		return json.Marshal(3)
+	case "nonce":
+		json.Unmarshal(data, &arg)
+		return json.Marshal(n.nonces[string(arg)])
+	case "isPendingNode":
+		json.Unmarshal(data, &arg)
+		return json.Marshal(n.pending[string(arg)])
+	case "groupPubKey":
+		var idx int
+		json.Unmarshal(data, &idx)
+		if idx >= len(n.pubKeys) {
+			return nil, fmt.Errorf("no group %d", idx)
+		}
+		return json.Marshal(n.pubKeys[idx])
+	}
+	return nil, fmt.Errorf("unknown query %s", name)
+}
+
+func (n *tmNode) deliver(raw []byte) error {
+	var tx tmTx
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return err
+	}
+	sig := tx.Signature
+	tx.Signature = nil
+	unsigned, _ := json.Marshal(tx)
+	pub, err := crypto.SigToPub(textHash(unsigned), sig)
+	if err != nil || !bytes.Equal(crypto.PubkeyToAddress(*pub).Bytes(), tx.Sender) {
+		return fmt.Errorf("invalid signature")
+	}
+	sender := string(tx.Sender)
+	if tx.Nonce != n.nonces[sender] {
+		return fmt.Errorf("invalid nonce %d, want %d", tx.Nonce, n.nonces[sender])
+	}
+	var event string
+	var data interface{}
+	switch tx.Method {
+	case "RegisterNewNode":
+		n.pending[sender] = true
+		event, data = "LogRegisteredNewPendingNode", &LogRegisteredNewPendingNode{Node: tx.Sender}
+	case "RegisterGroupPubKey":
+		var pubKey dkg.GroupPubKey
+		json.Unmarshal(tx.Args, &pubKey)
+		n.pubKeys = append(n.pubKeys, pubKey.PubKey)
+		event, data = "LogPublicKeyAccepted", &LogPublicKeyAccepted{GroupId: pubKey.GroupID, PubKey: pubKey.PubKey, WorkingGroupSize: big.NewInt(int64(len(n.pubKeys)))}
+	default:
+		return fmt.Errorf("unknown method %s", tx.Method)
+	}
+	n.nonces[sender]++
+	encoded, _ := json.Marshal(data)
+	n.blocks = append(n.blocks, tmMockBlock{
+		txs: [][]byte{raw},
+		results: []tmTxResult{{Events: []tmEvent{
+			{Type: "message", Attributes: []tmEventAttribute{{"action", tx.Method}}},
+			{Type: tmEventType, Attributes: []tmEventAttribute{{"event", event}, {"data", string(encoded)}}},
+		}}},
+	})
+	return nil
+}
+
+func newTestTmAdaptor(t *testing.T, url, network string) *tmAdaptor {
+	key, _ := crypto.GenerateKey()
+	adaptor, err := NewProxyAdapter(TENDERMINT, NewKeySigner(key), configuration.ChainConfig{RemoteNodeAddressPool: []string{url}, Network: network})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := adaptor.(*tmAdaptor)
+	e.pollInterval = 10 * time.Millisecond
+	return e
+}
+
+func receiveEvent(t *testing.T, sub *Subscription) *LogCommon {
+	select {
+	case event := <-sub.Events():
+		return event
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered")
+	}
+	return nil
+}
+
+func TestTmAdaptor(t *testing.T) {
+	node := newTmNode("dos-test")
+	server := node.serve()
+	defer server.Close()
+
+	if err := newTestTmAdaptor(t, server.URL, "dos-other").Start(); err == nil {
+		t.Fatal("endpoint of another network accepted")
+	}
+	e := newTestTmAdaptor(t, server.URL, "dos-test")
+	if err := e.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer e.End()
+	sub, err := e.SubscribeEvent(4, nil, SubscribeLogRegisteredNewPendingNode, SubscribeLogPublicKeyAccepted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.RegisterNewNode(ctx); err != nil {
+		t.Fatal(err)
+	}
+	event := receiveEvent(t, sub)
+	registered, ok := event.Event.(*LogRegisteredNewPendingNode)
+	if !ok || !bytes.Equal(registered.Node, e.Address()) || event.BlockN != 6 {
+		t.Errorf("got %T %+v at block %d", event.Event, event.Event, event.BlockN)
+	}
+	node.lock.Lock()
+	if want := tmTxHash(node.blocks[5].txs[0]); event.Tx != want {
+		t.Errorf("event tx, got: %s, want: %s.", event.Tx, want)
+	}
+	node.lock.Unlock()
+	if pending, err := e.IsPendingNode(ctx, e.Address()); err != nil || !pending {
+		t.Errorf("registered node pending: %t, %v", pending, err)
+	}
+
+	pubKey := make([]byte, 129)
+	pubKey[0], pubKey[128] = 1, 7
+	pubKeys := make(chan *dkg.GroupPubKey, 1)
+	pubKeys <- &dkg.GroupPubKey{GroupID: big.NewInt(42), PubKey: pubKey}
+	if err, ok := <-e.RegisterGroupPubKey(ctx, pubKeys); ok {
+		t.Fatal(err)
+	}
+	event = receiveEvent(t, sub)
+	if accepted, ok := event.Event.(*LogPublicKeyAccepted); !ok || accepted.GroupId.Int64() != 42 || !bytes.Equal(accepted.PubKey, pubKey) {
+		t.Errorf("got %T %+v", event.Event, event.Event)
+	}
+	if got, err := e.GroupPubKey(ctx, 0); err != nil || !bytes.Equal(got, pubKey) {
+		t.Errorf("group public key, got: %x, %v.", got, err)
+	}
+	if size, err := e.GroupSize(ctx); err != nil || size != 3 {
+		t.Errorf("group size, got: %d, %v, want: 3.", size, err)
+	}
+
+	// A rejected transaction makes the adaptor read the nonce again.
+	node.lock.Lock()
+	node.nonces[string(e.Address())] = 10
+	node.lock.Unlock()
+	if err := e.RegisterNewNode(ctx); err == nil || !strings.Contains(err.Error(), "transaction failed") {
+		t.Errorf("transaction with a stale nonce, got: %v.", err)
+	}
+	if err := e.RegisterNewNode(ctx); err != nil {
+		t.Error(err)
+	}
+	if nonce, err := e.PendingNonce(ctx); err != nil || nonce != 11 {
+		t.Errorf("nonce, got: %d, %v, want: 11.", nonce, err)
+	}
+}
@@ -1,23 +1,22 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/binary"
 	"errors"
+	"io"
 	"net"
-	"os"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/DOSNetwork/core/sign/bls"
+	"github.com/DOSNetwork/core/p2p/noise"
 	"github.com/DOSNetwork/core/suites"
 
 	"github.com/dedis/kyber"
 
-	"crypto/aes"
-	"crypto/cipher"
-
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
@@ -27,6 +26,21 @@ const (
 	msgSizeLimit = 1024 * 1024
 	bufSize      = 1024
 	headerSize   = 4
+	// handshakeTimeout bounds the whole handshake, signing included
+	handshakeTimeout = 60 * time.Second
+	challengeSize    = 32
+	// rekeyInterval is the number of messages sent with a key before both
+	// sides move on to the next one
+	rekeyInterval = 1 << 16
+)
+
+// errForeignPackage and errInvalidPackage are the errors of packages that
+// the peer did not send or did not sign, errConnectedToSelf the error of a
+// handshake with this node
+var (
+	errForeignPackage  = errors.New("package sender is not the peer")
+	errInvalidPackage  = errors.New("package signature is invalid")
+	errConnectedToSelf = errors.New("connected to self")
 )
 
 type client struct {
@@ -34,13 +48,14 @@ type client struct {
 	incomingConn bool
 
 	suite        suites.Suite
+	self         *identity
 	localID      []byte
-	localSecKey  kyber.Scalar
-	localPubKey  kyber.Point
+	verifyPeer   PeerVerifier
+	remote       *ID
 	remoteID     []byte
 	remotePubKey kyber.Point
-	dhKey        []byte
-	dhNonce      []byte
+	sendCipher   *noise.CipherState
+	recvCipher   *noise.CipherState
 
 	ctx      context.Context
 	cancel   context.CancelFunc
@@ -49,6 +64,20 @@ type client struct {
 	errc     chan error
 }
 
+// frame is a package on its way to the connection. sent, if set, is closed
+// once the package is written.
+type frame struct {
+	bytes []byte
+	sent  chan struct{}
+}
+
+// written closes the sent channel of f
+func (f frame) written() {
+	if f.sent != nil {
+		close(f.sent)
+	}
+}
+
 func mergeErrors(ctx context.Context, cs ...<-chan error) chan error {
 	var wg sync.WaitGroup
 	// We must ensure that the output channel has the capacity to
@@ -83,38 +112,44 @@ func mergeErrors(ctx context.Context, cs ...<-chan error) chan error {
 	return out
 }
 
-func newClient(suite suites.Suite, secKey kyber.Scalar, localPubKey kyber.Point, localID []byte, conn net.Conn, incomingConn bool) (c *client, err error) {
+func newClient(suite suites.Suite, self *identity, verifyPeer PeerVerifier, conn net.Conn, incomingConn bool) (c *client, err error) {
 	c = &client{
 		suite:        suite,
-		localSecKey:  secKey,
-		localPubKey:  localPubKey,
-		localID:      localID,
+		self:         self,
+		localID:      self.id,
+		verifyPeer:   verifyPeer,
 		conn:         conn,
 		incomingConn: incomingConn,
 	}
 	c.ctx, c.cancel = context.WithCancel(context.Background())
 	c.sender = make(chan request, 21)
 	//Wait for exchanging ID complete
-	err = c.exchangeID()
-	if c.remoteID == nil {
-		err = errors.New("exchangeID failed")
-		return
-	}
-	if err != nil {
+	if err = c.exchangeID(); err != nil {
 		logger.Error(err)
+		c.close()
 		return
 	}
 
 	var errs []<-chan error
-	var packByte chan []byte
+	var packByte chan frame
+	if qc, ok := conn.(*quicConn); ok {
+		// QUIC encrypts the streams already, and they arrive in any order
+		bytes, errc := readStreams(c.ctx, qc.sess)
+		errs = append(errs, errc)
+		c.receiver, packByte, errc = dispatch(c.ctx, c.self, c.remote, incomingConn, c.suite, c.remotePubKey, c.sender, bytes)
+		errs = append(errs, errc)
+		errs = append(errs, sendStreams(c.ctx, qc.sess, packByte))
+		c.errc = mergeErrors(c.ctx, errs...)
+		return
+	}
 	//Build a secure pipeline
 	bytes, errc := readBytes(c.ctx, conn, c.localID, c.remoteID, incomingConn)
 	errs = append(errs, errc)
-	decrypted, errc := decrypt(c.ctx, c.dhKey, c.dhNonce, bytes)
+	decrypted, errc := decrypt(c.ctx, c.recvCipher, bytes)
 	errs = append(errs, errc)
-	c.receiver, packByte, errc = dispatch(c.ctx, c.localID, c.remoteID, incomingConn, c.suite, c.remotePubKey, c.sender, decrypted)
+	c.receiver, packByte, errc = dispatch(c.ctx, c.self, c.remote, incomingConn, c.suite, c.remotePubKey, c.sender, decrypted)
 	errs = append(errs, errc)
-	encrypted, errc := encrypt(c.ctx, c.dhKey, c.dhNonce, packByte)
+	encrypted, errc := encrypt(c.ctx, c.sendCipher, packByte)
 	errs = append(errs, errc)
 	errc = sendBytes(c.ctx, c.conn, encrypted, c.localID, c.remoteID, incomingConn)
 	errs = append(errs, errc)
@@ -128,189 +163,228 @@ func (c *client) close() {
 	c.conn.Close()
 }
 
+// exchangeID runs the handshake. A Noise XX handshake first sets up the
+// encrypted channel with fresh ephemeral keys. Over it both sides send their
+// ID with a fresh challenge and the certificate of their p2p public key, then an Auth signing their p2p public key, the
+// challenge of the peer and the Noise handshake hash with the Ethereum key of
+// their ID. The channel is used only once the signature of the peer is
+// verified against the ID it claims.
 func (c *client) exchangeID() (err error) {
-	var wg sync.WaitGroup
-	ch := make(chan struct{})
-	wg.Add(2)
-	go c.sendID(&wg)
-	go c.receiveID(&wg)
-	go func() {
-		wg.Wait()
-		ch <- struct{}{}
-	}()
-	timeout := time.Duration(60) * time.Second
-	select {
-	case <-ch:
+	c.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+	defer func() {
 		f := map[string]interface{}{
 			"localID":  c.localID,
 			"remoteID": c.remoteID}
-		if logger != nil {
+		if err != nil {
+			f["Error"] = err.Error()
+			logger.Event("exchangeIDFail", f)
+		} else {
 			logger.Event("exchangeIDSuccess", f)
 		}
-	case <-time.After(timeout):
-		f := map[string]interface{}{
-			"localID": c.localID}
-		logger.Event("exchangeIDFail", f)
-	}
-	return
-}
+	}()
 
-func (c *client) receiveID(wg *sync.WaitGroup) (err error) {
-	defer wg.Done()
-	// Read until all header bytes have been read.
-	header := make([]byte, headerSize)
-	// Read until all header bytes have been read.
-	bytesRead, totalBytesRead := 0, 0
-	for totalBytesRead < headerSize && err == nil {
-		if bytesRead, err = c.conn.Read(header[totalBytesRead:]); err != nil {
-			if err.Error() == "EOF" {
-				c.close()
-			}
-			return
-		}
-		totalBytesRead += bytesRead
+	binding, err := c.noiseHandshake()
+	if err != nil {
+		return
 	}
-
-	// Decode message size.
-	size := binary.BigEndian.Uint32(header)
-	header = nil
-	if size > msgSizeLimit || size <= 0 {
-		err = errors.New("p2p message size is not valid " + strconv.Itoa(int(size)))
+	challenge := make([]byte, challengeSize)
+	if _, err = rand.Read(challenge); err != nil {
 		return
 	}
-	// Read until all message bytes have been read.
-	buffer := make([]byte, size)
-
-	contentBytesRead, totalContentBytesRead := 0, 0
-	for totalContentBytesRead < int(size) && err == nil {
-		if contentBytesRead, err = c.conn.Read(buffer[totalContentBytesRead:]); err != nil {
-			return
-		}
-		totalContentBytesRead += contentBytesRead
+	pubKeyBytes := c.self.pubKeyBytes
+	reply, err := c.exchange(&ID{PublicKey: pubKeyBytes, Id: c.localID, Challenge: challenge, Certificate: c.self.certificate})
+	if err != nil {
+		return
 	}
-	pa := new(Package)
-	if err = proto.Unmarshal(buffer, pa); err != nil {
-		logger.Error(err)
+	id, ok := reply.(*ID)
+	if !ok {
+		return errors.New("handshake expected an ID")
+	}
+	if err = c.receiveID(id); err != nil {
 		return
 	}
-	buffer = nil
 
-	var ptr ptypes.DynamicAny
-	if err = ptypes.UnmarshalAny(pa.GetAnything(), &ptr); err != nil {
-		logger.Error(err)
+	ctx, cancel := context.WithTimeout(c.ctx, handshakeTimeout)
+	defer cancel()
+	sig, err := c.self.signer.SignText(ctx, handshakeText(pubKeyBytes, id.GetChallenge(), binding))
+	if err != nil {
+		return
+	}
+	if reply, err = c.exchange(&Auth{Signature: sig}); err != nil {
 		return
 	}
-	id, ok := ptr.Message.(*ID)
+	auth, ok := reply.(*Auth)
 	if !ok {
+		return errors.New("handshake expected an Auth")
+	}
+	if err = verifyHandshake(c.remoteID, id.GetPublicKey(), challenge, binding, auth.GetSignature()); err != nil {
 		return
 	}
+	// Only a verified ID tells that the peer is this node
+	if bytes.Equal(c.remoteID, c.localID) {
+		return errConnectedToSelf
+	}
+	if c.verifyPeer != nil {
+		err = c.verifyPeer(c.remoteID)
+	}
+	return
+}
 
-	c.remoteID = id.GetId()
+// noiseHandshake runs the Noise XX handshake, the dialer as initiator, and
+// returns the handshake hash that identifies the session. Over QUIC the
+// prologue binds the session to the certificate that signed the QUIC keys.
+func (c *client) noiseHandshake() (binding []byte, err error) {
+	initiator := !c.incomingConn
+	prologue := []byte(handshakePrefix)
+	if qc, ok := c.conn.(*quicConn); ok {
+		prologue = append(prologue, qc.binding...)
+	}
+	hs := noise.NewHandshake(initiator, c.self.noiseKey, prologue)
+	for !hs.Complete() {
+		var msg []byte
+		if hs.WriteTurn() {
+			if msg, err = hs.WriteMessage(nil); err != nil {
+				return
+			}
+			err = writeFrame(c.conn, msg)
+		} else {
+			if msg, err = readFrame(c.conn, noise.MaxMessageLen); err != nil {
+				return
+			}
+			_, err = hs.ReadMessage(msg)
+		}
+		if err != nil {
+			return
+		}
+	}
+	c.sendCipher, c.recvCipher = hs.Ciphers()
+	return hs.HandshakeHash(), nil
+}
+
+// exchange sends m and reads the message the peer sends at the same time
+func (c *client) exchange(m proto.Message) (reply proto.Message, err error) {
+	errc := make(chan error, 1)
+	go func() { errc <- c.sendHandshake(m) }()
+	reply, err = c.receiveHandshake()
+	if sendErr := <-errc; err == nil {
+		err = sendErr
+	}
+	return
+}
 
-	if string(c.remoteID) == string(c.localID) {
-		os.Exit(2)
+func (c *client) receiveID(id *ID) (err error) {
+	if len(id.GetChallenge()) != challengeSize {
+		return errors.New("handshake challenge of invalid size")
 	}
+	c.remoteID = id.GetId()
 	pub := c.suite.G2().Point()
 	if err = pub.UnmarshalBinary(id.GetPublicKey()); err != nil {
-		logger.Error(err)
 		return
 	}
-	c.remotePubKey = pub
-
-	var dhBytes []byte
-	dhKey := c.suite.Point().Mul(c.localSecKey, c.remotePubKey)
-	if dhBytes, err = dhKey.MarshalBinary(); err != nil {
-		logger.Error(err)
+	if err = verifyCertificate(c.remoteID, id.GetPublicKey(), id.GetCertificate()); err != nil {
 		return
 	}
-	c.dhKey = dhBytes[0:32]
-	c.dhNonce = dhBytes[32:44]
+	c.remote = id
+	c.remotePubKey = pub
 	return
 }
 
-func (c *client) sendID(wg *sync.WaitGroup) (err error) {
-	defer wg.Done()
-	var anything *any.Any
-	var bytes, pubKeyBytes []byte
-	if pubKeyBytes, err = c.localPubKey.MarshalBinary(); err != nil {
-		logger.Error(err)
+func (c *client) receiveHandshake() (m proto.Message, err error) {
+	var buffer []byte
+	if buffer, err = readFrame(c.conn, msgSizeLimit); err != nil {
 		return
 	}
-
-	pID := &ID{
-		PublicKey: pubKeyBytes,
-		Id:        c.localID,
+	if buffer, err = c.recvCipher.Decrypt(nil, buffer); err != nil {
+		return
+	}
+	pa := new(Package)
+	if err = proto.Unmarshal(buffer, pa); err != nil {
+		return
+	}
+	var ptr ptypes.DynamicAny
+	if err = ptypes.UnmarshalAny(pa.GetAnything(), &ptr); err != nil {
+		return
 	}
+	return ptr.Message, nil
+}
 
-	if anything, err = ptypes.MarshalAny(pID); err != nil {
-		logger.Error(err)
+func (c *client) sendHandshake(m proto.Message) (err error) {
+	var anything *any.Any
+	var bytes []byte
+	if anything, err = ptypes.MarshalAny(m); err != nil {
 		return
 	}
 	pa := &Package{
 		Anything: anything,
 	}
 	if bytes, err = proto.Marshal(pa); err != nil {
-		logger.Error(err)
 		return
 	}
-	if len(bytes) > msgSizeLimit {
-		err = errors.New("p2p message size is too big " + strconv.Itoa(int(len(bytes))))
+	if bytes, err = c.sendCipher.Encrypt(nil, bytes); err != nil {
 		return
 	}
-	prefix := make([]byte, headerSize)
-	binary.BigEndian.PutUint32(prefix, uint32(len(bytes)))
-
-	bytes = append(prefix, bytes...)
+	return writeFrame(c.conn, bytes)
+}
 
-	bytesRead, totalBytesRead := 0, 0
-	for totalBytesRead < headerSize && err == nil {
-		if bytesRead, err = c.conn.Write(bytes); err != nil {
-			c.close()
-			return
-		}
-		totalBytesRead += bytesRead
+// readFrame reads a message prefixed with its size
+func readFrame(conn io.Reader, limit int) (buffer []byte, err error) {
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return
+	}
+	// Decode message size.
+	size := binary.BigEndian.Uint32(header)
+	if size > uint32(limit) || size <= 0 {
+		err = errors.New("p2p message size is not valid " + strconv.Itoa(int(size)))
+		return
 	}
+	buffer = make([]byte, size)
+	_, err = io.ReadFull(conn, buffer)
+	return
+}
 
+// writeFrame writes a message prefixed with its size
+func writeFrame(conn io.Writer, bytes []byte) (err error) {
+	if len(bytes) > msgSizeLimit {
+		return errors.New("p2p message size is too big " + strconv.Itoa(int(len(bytes))))
+	}
+	prefix := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(prefix, uint32(len(bytes)))
+	_, err = conn.Write(append(prefix, bytes...))
 	return
 }
 
-func encrypt(ctx context.Context, dhKey, dhNonce []byte, plaintext chan []byte) (chan []byte, chan error) {
-	out := make(chan []byte)
+// encrypt seals the messages in order with cs, rekeying it every
+// rekeyInterval messages like the decrypt of the peer does
+func encrypt(ctx context.Context, cs *noise.CipherState, plaintext chan frame) (chan frame, chan error) {
+	out := make(chan frame)
 	errc := make(chan error)
 
 	go func() {
 		defer close(out)
 		defer close(errc)
+		var sent uint64
 		for {
 			select {
-			case c, ok := <-plaintext:
+			case f, ok := <-plaintext:
 				if !ok {
 					return
 				}
-
-				var err error
-				var block cipher.Block
-				var aesgcm cipher.AEAD
-
-				if block, err = aes.NewCipher(dhKey); err != nil {
+				c, err := cs.Encrypt(nil, f.bytes)
+				if err != nil {
 					select {
 					case errc <- err:
 					case <-ctx.Done():
 					}
-					continue
+					return
 				}
-
-				if aesgcm, err = cipher.NewGCM(block); err != nil {
-					select {
-					case errc <- err:
-					case <-ctx.Done():
-					}
-					continue
+				if sent++; sent%rekeyInterval == 0 {
+					cs.Rekey()
 				}
-				c = aesgcm.Seal(nil, dhNonce, c, nil)
 
 				select {
-				case out <- c:
+				case out <- frame{bytes: c, sent: f.sent}:
 				case <-ctx.Done():
 				}
 			case <-ctx.Done():
@@ -321,46 +395,32 @@ func encrypt(ctx context.Context, dhKey, dhNonce []byte, plaintext chan []byte)
 	return out, errc
 }
 
-func decrypt(ctx context.Context, dhKey, dhNonce []byte, ciphertext chan []byte) (chan []byte, chan error) {
+// decrypt opens the messages in order with cs. A message that fails to open
+// was forged, replayed, reordered or dropped, which ends the pipeline.
+func decrypt(ctx context.Context, cs *noise.CipherState, ciphertext chan []byte) (chan []byte, chan error) {
 	out := make(chan []byte)
 	errc := make(chan error)
 
 	go func() {
 		defer close(out)
 		defer close(errc)
+		var received uint64
 		for {
 			select {
 			case c, ok := <-ciphertext:
 				if !ok {
 					return
 				}
-
-				var block cipher.Block
-				var aesgcm cipher.AEAD
-				var err error
-
-				if block, err = aes.NewCipher(dhKey); err != nil {
+				c, err := cs.Decrypt(nil, c)
+				if err != nil {
 					select {
 					case errc <- err:
 					case <-ctx.Done():
 					}
-					continue
+					return
 				}
-
-				if aesgcm, err = cipher.NewGCM(block); err != nil {
-					select {
-					case errc <- err:
-					case <-ctx.Done():
-					}
-					continue
-				}
-
-				if c, err = aesgcm.Open(nil, dhNonce, c, nil); err != nil {
-					select {
-					case errc <- err:
-					case <-ctx.Done():
-					}
-					continue
+				if received++; received%rekeyInterval == 0 {
+					cs.Rekey()
 				}
 
 				select {
@@ -375,9 +435,12 @@ func decrypt(ctx context.Context, dhKey, dhNonce []byte, ciphertext chan []byte)
 	return out, errc
 }
 
-func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool, suite suites.Suite, pub kyber.Point, sendMsg chan request, receiveBytes chan []byte) (chan interface{}, chan []byte, chan error) {
+// dispatch signs the packages to send, once their nonce is set, with the key
+// of self. It checks that every received package was sent by remote and
+// signed with its certified key, and hands it on with the evidence of that.
+func dispatch(ctx context.Context, self *identity, remote *ID, incomingConn bool, suite suites.Suite, pub kyber.Point, sendMsg chan request, receiveBytes chan []byte) (chan interface{}, chan frame, chan error) {
 	receiver := make(chan interface{}, 21)
-	sender := make(chan []byte, 21)
+	sender := make(chan frame, 21)
 	errc := make(chan error)
 	requests := make(map[uint64]request)
 	var nonce uint64
@@ -398,10 +461,13 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 					}
 					req.p.RequestNonce = nonce
 				}
-				//Encode the package
+				//Sign and encode the package
 				var bytes []byte
-				var err error
-				if bytes, err = proto.Marshal(req.p); err != nil {
+				err := signPackage(suite, self.secKey, req.p)
+				if err == nil {
+					bytes, err = proto.Marshal(req.p)
+				}
+				if err != nil {
 					go func() {
 						select {
 						case req.errc <- err:
@@ -414,12 +480,14 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 					}()
 					continue
 				}
+				f := frame{bytes: bytes}
 				if !req.p.ReplyFlag {
 					requests[nonce] = req
 					nonce++
+					f.sent = req.sent
 				}
 				select {
-				case sender <- bytes:
+				case sender <- f:
 					if !req.p.ReplyFlag {
 					} else {
 						//req.cancel()
@@ -430,13 +498,13 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 						close(req.reply)
 					}
 				}
-			case bytes, ok := <-receiveBytes:
+			case data, ok := <-receiveBytes:
 				if !ok {
 					return
 				}
 
 				pa := new(Package)
-				if err := proto.Unmarshal(bytes, pa); err != nil {
+				if err := proto.Unmarshal(data, pa); err != nil {
 					select {
 					case errc <- err:
 					case <-ctx.Done():
@@ -459,15 +527,19 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 					replyCtx = request.ctx
 					replyRecivier = request.reply
 				}
-				if pub != nil {
-					if err := bls.Verify(suite, pub, pa.GetAnything().Value, pa.GetSignature()); err != nil {
-
-						select {
-						case replyErrc <- err:
-						case <-replyCtx.Done():
-						}
-						continue
+				if string(pa.GetSender()) != string(remote.GetId()) {
+					select {
+					case replyErrc <- errForeignPackage:
+					case <-replyCtx.Done():
+					}
+					continue
+				}
+				if err := verifyPackage(suite, pub, pa); err != nil {
+					select {
+					case replyErrc <- errInvalidPackage:
+					case <-replyCtx.Done():
 					}
+					continue
 				}
 
 				var ptr ptypes.DynamicAny
@@ -479,7 +551,8 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 					}
 					continue
 				}
-				msg := P2PMessage{Msg: ptr, Sender: pa.GetSender(), RequestNonce: pa.GetRequestNonce()}
+				evidence := &Evidence{Package: data, PublicKey: remote.GetPublicKey(), Certificate: remote.GetCertificate()}
+				msg := P2PMessage{Msg: ptr, Sender: pa.GetSender(), RequestNonce: pa.GetRequestNonce(), Evidence: evidence}
 
 				select {
 				case replyRecivier <- msg:
@@ -495,14 +568,14 @@ func dispatch(ctx context.Context, localID, remoteID []byte, incomingConn bool,
 	return receiver, sender, errc
 }
 
-func sendBytes(ctx context.Context, c net.Conn, bytesC chan []byte, localID, remoteID []byte, incomingConn bool) chan error {
+func sendBytes(ctx context.Context, c net.Conn, framec chan frame, localID, remoteID []byte, incomingConn bool) chan error {
 	errc := make(chan error)
 	prefix := make([]byte, headerSize)
 	go func() {
 		defer close(errc)
 		for {
 			select {
-			case bytes, ok := <-bytesC:
+			case f, ok := <-framec:
 				if !ok {
 					return
 				}
@@ -510,6 +583,7 @@ func sendBytes(ctx context.Context, c net.Conn, bytesC chan []byte, localID, rem
 				var err error
 				bytesWrite, totalBytesWrtie := 0, 0
 
+				bytes := f.bytes
 				size := uint32(len(bytes))
 				binary.BigEndian.PutUint32(prefix, size)
 				bytes = append(prefix, bytes...)
@@ -523,6 +597,9 @@ func sendBytes(ctx context.Context, c net.Conn, bytesC chan []byte, localID, rem
 					}
 					totalBytesWrtie += bytesWrite
 				}
+				if err == nil {
+					f.written()
+				}
 			case <-ctx.Done():
 
 				return
@@ -609,7 +686,6 @@ func (c *client) send(req request) error {
 	go func(req request) {
 		var anything *any.Any
 		var err error
-		var sig []byte
 
 		if anything, err = ptypes.MarshalAny(req.msg); err != nil {
 			select {
@@ -622,22 +698,10 @@ func (c *client) send(req request) error {
 			}
 			return
 		}
-		if sig, err = bls.Sign(c.suite, c.localSecKey, anything.Value); err != nil {
-			select {
-			case req.errc <- err:
-			case <-req.ctx.Done():
-			}
-			close(req.errc)
-			if req.reply != nil {
-				close(req.reply)
-			}
-			return
-		}
 
 		req.p = &Package{
-			Sender:    c.localID,
-			Anything:  anything,
-			Signature: sig,
+			Sender:   c.localID,
+			Anything: anything,
 		}
 		if req.rType == 2 {
 			req.p.RequestNonce = req.nonce
@@ -1,7 +1,10 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -10,23 +13,43 @@ import (
 
 	"github.com/DOSNetwork/core/log"
 	"github.com/DOSNetwork/core/suites"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/protobuf/proto"
 )
 
 func Init() {
 	logger = log.New("module", "p2p")
 }
-func listen(t *testing.T, listener net.Listener, sID string) {
+
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newSigner() *keySigner {
+	key, _ := crypto.GenerateKey()
+	return &keySigner{key}
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text))), s.key)
+}
+
+func listen(t *testing.T, listener net.Listener, id []byte, signer Signer) {
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
 			continue
 		}
-		go func(conn net.Conn, sID string) {
+		go func(conn net.Conn) {
 			suite := suites.MustFind("bn256")
-			secret := suite.Scalar().Pick(suite.RandomStream())
-			public := suite.Point().Mul(secret, nil)
-			client, err := newClient(suite, secret, public, []byte(sID), conn, true)
+			self, _ := newIdentity(suite, signer)
+			self.id = id
+			client, err := newClient(suite, self, nil, conn, true)
 			if err != nil {
 				fmt.Println("err", err)
 
@@ -54,17 +77,20 @@ func listen(t *testing.T, listener net.Listener, sID string) {
 				reply.msg = proto.Message(&Pong{Count: p.Count + 10})
 				client.send(reply)
 			}
-		}(conn, sID)
+		}(conn)
 	}
 }
 
 func TestExchangeID(t *testing.T) {
 	var listenerA net.Listener
 	var err error
+	log.Init(common.Address{}.Bytes())
+	Init()
 	if listenerA, err = net.Listen("tcp", ":9901"); err != nil {
 		return
 	}
-	go listen(t, listenerA, "server")
+	server := newSigner()
+	go listen(t, listenerA, server.Address().Bytes(), server)
 
 	add := "localhost:9901"
 	var conn net.Conn
@@ -73,24 +99,75 @@ func TestExchangeID(t *testing.T) {
 		t.Errorf("Can't Dial to %s ,Error %s", add, err.Error())
 	}
 	suite := suites.MustFind("bn256")
-	secret := suite.Scalar().Pick(suite.RandomStream())
-	public := suite.Point().Mul(secret, nil)
-	client, err = newClient(suite, secret, public, []byte("local"), conn, false)
+	local := newSigner()
+	self, _ := newIdentity(suite, local)
+	client, err = newClient(suite, self, nil, conn, false)
+	if err != nil {
+		t.Fatalf("newClient,Error %s", err.Error())
+	}
+	if !bytes.Equal(client.localID, local.Address().Bytes()) || !bytes.Equal(client.remoteID, server.Address().Bytes()) {
+		t.Errorf("ExchangeID ,Error %x %x", client.localID, client.remoteID)
+	}
+
+	// A peer claiming an ID it holds no key for is rejected
+	impostor, err := net.Listen("tcp", ":9903")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go listen(t, impostor, newSigner().Address().Bytes(), server)
+	if conn, err = net.Dial("tcp", "localhost:9903"); err != nil {
+		t.Fatalf("Can't Dial to %s ,Error %s", "localhost:9903", err.Error())
+	}
+	if _, err = newClient(suite, self, nil, conn, false); err == nil {
+		t.Error("ExchangeID accepted an ID signed for by another account")
+	}
+
+	// A peer claiming the ID of this node is rejected, not obeyed
+	selfImpostor, err := net.Listen("tcp", ":9941")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go listen(t, selfImpostor, local.Address().Bytes(), server)
+	if conn, err = net.Dial("tcp", "localhost:9941"); err != nil {
+		t.Fatalf("Can't Dial to %s ,Error %s", "localhost:9941", err.Error())
+	}
+	if _, err = newClient(suite, self, nil, conn, false); err == nil || err == errConnectedToSelf {
+		t.Errorf("ExchangeID with a peer claiming our ID, got: %v, want: a verification error.", err)
+	}
+
+	// A node connected to itself finds out once the handshake is verified
+	selfListener, err := net.Listen("tcp", ":9942")
 	if err != nil {
-		t.Errorf("newClient,Error %s", err.Error())
+		t.Fatal(err)
 	}
-	if string(client.localID) != "local" || string(client.remoteID) != "server" {
-		t.Errorf("ExchangeID ,Error %s %s", string(client.localID), string(client.remoteID))
+	go listen(t, selfListener, local.Address().Bytes(), local)
+	if conn, err = net.Dial("tcp", "localhost:9942"); err != nil {
+		t.Fatalf("Can't Dial to %s ,Error %s", "localhost:9942", err.Error())
+	}
+	if _, err = newClient(suite, self, nil, conn, false); err != errConnectedToSelf {
+		t.Errorf("ExchangeID with itself, got: %v, want: %v.", err, errConnectedToSelf)
+	}
+
+	// So is an authenticated peer the verifier refuses
+	if conn, err = net.Dial("tcp", add); err != nil {
+		t.Fatalf("Can't Dial to %s ,Error %s", add, err.Error())
+	}
+	refuse := func(id []byte) error { return errors.New("not a member") }
+	if _, err = newClient(suite, self, refuse, conn, false); err == nil {
+		t.Error("ExchangeID accepted a peer refused by the verifier")
 	}
 }
 
 func TestRequest(t *testing.T) {
 	var listenerA net.Listener
 	var err error
+	log.Init(common.Address{}.Bytes())
+	Init()
 	if listenerA, err = net.Listen("tcp", ":9902"); err != nil {
 		return
 	}
-	go listen(t, listenerA, "server")
+	signer := newSigner()
+	go listen(t, listenerA, signer.Address().Bytes(), signer)
 
 	add := "localhost:9902"
 	var conn net.Conn
@@ -99,12 +176,11 @@ func TestRequest(t *testing.T) {
 		t.Errorf("Can't Dial to %s ,Error %s", add, err.Error())
 	}
 	suite := suites.MustFind("bn256")
-	secret := suite.Scalar().Pick(suite.RandomStream())
-	public := suite.Point().Mul(secret, nil)
-
-	client, err = newClient(suite, secret, public, []byte("local"), conn, false)
+	local := newSigner()
+	self, _ := newIdentity(suite, local)
+	client, err = newClient(suite, self, nil, conn, false)
 	if err != nil {
-		t.Errorf("newClient,Error %s", err.Error())
+		t.Fatalf("newClient,Error %s", err.Error())
 	}
 	var count uint64
 	checkRoll := make(map[uint64]uint64)
@@ -138,6 +214,9 @@ func TestRequest(t *testing.T) {
 				if !ok {
 					t.Errorf("casting failed")
 				}
+				if _, err := msg.Evidence.Verify(); err != nil {
+					t.Errorf("reply evidence, Error %s", err.Error())
+				}
 				checkRoll[count] = p.Count
 				return
 			case e, ok := <-callReq.errc:
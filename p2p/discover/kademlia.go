@@ -0,0 +1,550 @@
+package discover
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DOSNetwork/core/p2p/internal"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+const (
+	// alpha is the number of peers asked at once during a lookup
+	alpha = 3
+	// packetSize bounds the UDP packets, a full LookupNodeResponse included
+	packetSize = 2048
+	// requestTimeout is how long a peer has to answer
+	requestTimeout = 2 * time.Second
+	// refreshInterval is the time between two refreshes of the routing table
+	refreshInterval = 10 * time.Minute
+	// maxRecords bounds the records a node keeps for others
+	maxRecords = 4096
+)
+
+var errTimeout = errors.New("kademlia: request timeout")
+
+// knownPeer is how a peer is saved across restarts
+type knownPeer struct {
+	ID      string
+	Address string
+}
+
+type kademlia struct {
+	self  []byte
+	port  int
+	path  string
+	conn  *net.UDPConn
+	table *table
+
+	mu      sync.Mutex
+	pending map[uint64]chan *internal.Package
+	// found caches the addresses lookups found, lookups are the ones running
+	found   map[string]*net.UDPAddr
+	lookups map[string]bool
+	// record is the one of the local node, records the verified ones of
+	// others, stored here or found by lookups
+	record  *Record
+	records map[string]*Record
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewKademlia creates a Kademlia implementation that listens for UDP on port.
+// The peers it knows are saved to path, if not empty, and contacted again
+// when it restarts. Peers find each other by their ID, each node keeping at
+// most bucketSize peers for each length of prefix they share with its ID.
+func NewKademlia(id []byte, port int, path string) (Membership, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+	if err != nil {
+		return nil, err
+	}
+	k := &kademlia{
+		self:    id,
+		port:    port,
+		path:    path,
+		conn:    conn,
+		table:   newTable(id),
+		pending: make(map[uint64]chan *internal.Package),
+		found:   make(map[string]*net.UDPAddr),
+		lookups: make(map[string]bool),
+		records: make(map[string]*Record),
+	}
+	k.ctx, k.cancel = context.WithCancel(context.Background())
+	go k.readLoop()
+	k.loadPeers()
+	go k.refreshLoop()
+	return k, nil
+}
+
+// Join pings the bootstrap nodes, given as IP or IP:port, and publishes the
+// local record, which fills the routing table. Returns the number of nodes that
+// answered.
+func (k *kademlia) Join(bootstrapIP []string) (num int, err error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, ip := range bootstrapIP {
+		addr, err := k.bootstrapAddr(ip)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(addr *net.UDPAddr) {
+			defer wg.Done()
+			if k.ping(addr) == nil {
+				mu.Lock()
+				num++
+				mu.Unlock()
+			}
+		}(addr)
+	}
+	wg.Wait()
+	if num == 0 {
+		return 0, errors.New("kademlia: no bootstrap node answered")
+	}
+	k.publish()
+	k.savePeers()
+	return
+}
+
+// Leave saves the known peers and stops answering
+func (k *kademlia) Leave() {
+	k.savePeers()
+	k.cancel()
+	k.conn.Close()
+}
+
+// Lookup returns the IP address of the given ID if it is known. Otherwise it
+// starts looking it up in the background and returns nil, so a caller that
+// asks again later finds it without waiting for the network.
+func (k *kademlia) Lookup(id []byte) (addr net.IP) {
+	if r := k.Resolve(id); r != nil {
+		return r.IP()
+	}
+	if p := k.table.find(id); p != nil {
+		return p.addr.IP
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if found := k.found[string(id)]; found != nil {
+		return found.IP
+	}
+	k.lookupLater(id)
+	return nil
+}
+
+// Advertise stores the record at the nodes closest to the local ID, where
+// lookups of the local ID end
+func (k *kademlia) Advertise(r *Record) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.record = r
+	k.mu.Unlock()
+	k.publish()
+	return nil
+}
+
+// publish looks the local ID up, which fills the routing table, and stores
+// the local record at the closest nodes found
+func (k *kademlia) publish() {
+	closest, _ := k.lookup(k.self)
+	k.mu.Lock()
+	r := k.record
+	k.mu.Unlock()
+	if r == nil {
+		return
+	}
+	for _, p := range closest {
+		k.send(p.addr, &internal.StoreRecord{Record: r.toProto()}, 0, false)
+	}
+}
+
+// Resolve returns the record of the given ID if it is known. Otherwise it
+// starts looking it up in the background and returns nil, like Lookup.
+func (k *kademlia) Resolve(id []byte) *Record {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if string(id) == string(k.self) {
+		return k.record
+	}
+	if r := k.records[string(id)]; r != nil {
+		return r
+	}
+	k.lookupLater(id)
+	return nil
+}
+
+// lookupLater looks id up in the background and caches what it finds. It
+// must be called with mu held.
+func (k *kademlia) lookupLater(id []byte) {
+	if k.lookups[string(id)] {
+		return
+	}
+	k.lookups[string(id)] = true
+	go func() {
+		var found *net.UDPAddr
+		closest, record := k.lookup(id)
+		for _, p := range closest {
+			if string(p.id) == string(id) {
+				found = p.addr
+			}
+		}
+		if record != nil {
+			k.keep(record)
+		}
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		delete(k.lookups, string(id))
+		if found != nil {
+			k.found[string(id)] = found
+		}
+	}()
+}
+
+// keep stores a verified record unless a newer one of the node is known
+func (k *kademlia) keep(r *Record) {
+	if len(r.ID) != len(k.self) || r.Verify() != nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if old := k.records[string(r.ID)]; old != nil && old.Seq >= r.Seq {
+		return
+	}
+	if len(k.records) >= maxRecords {
+		// Forget the record farthest from the local ID
+		var farthest string
+		for id := range k.records {
+			if farthest == "" || closer([]byte(farthest), []byte(id), k.self) {
+				farthest = id
+			}
+		}
+		if !closer(r.ID, []byte(farthest), k.self) {
+			return
+		}
+		delete(k.records, farthest)
+	}
+	k.records[string(r.ID)] = r
+}
+
+// NumOfPeers returns the number of known peers, the local node included
+func (k *kademlia) NumOfPeers() int {
+	return k.table.len() + 1
+}
+
+// MembersIP returns the IP addresses of the known peers
+func (k *kademlia) MembersIP() (addr []net.IP) {
+	for _, p := range k.table.peers() {
+		addr = append(addr, p.addr.IP)
+	}
+	return
+}
+
+// MembersID returns the IDs of the known peers and of the local node
+func (k *kademlia) MembersID() (list [][]byte) {
+	list = append(list, k.self)
+	for _, p := range k.table.peers() {
+		list = append(list, p.id)
+	}
+	return
+}
+
+func (k *kademlia) bootstrapAddr(ip string) (*net.UDPAddr, error) {
+	if _, _, err := net.SplitHostPort(ip); err != nil {
+		ip = net.JoinHostPort(ip, strconv.Itoa(k.port))
+	}
+	return net.ResolveUDPAddr("udp", ip)
+}
+
+// lookup asks the peers closest to target for even closer ones, alpha at a
+// time, until the bucketSize closest peers it knows of all answered. It also
+// returns the newest record of target the peers had.
+func (k *kademlia) lookup(target []byte) (closest []*peer, record *Record) {
+	closest = k.table.closest(target, bucketSize)
+	asked := map[string]bool{string(k.self): true}
+	seen := map[string]bool{string(k.self): true}
+	for _, p := range closest {
+		seen[string(p.id)] = true
+	}
+	for {
+		var batch []*peer
+		for _, p := range closest {
+			if !asked[string(p.id)] && len(batch) < alpha {
+				asked[string(p.id)] = true
+				batch = append(batch, p)
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+		type reply struct {
+			found  []*peer
+			record *Record
+		}
+		replies := make(chan reply, len(batch))
+		for _, p := range batch {
+			go func(p *peer) {
+				found, record, _ := k.findNode(p.addr, target)
+				replies <- reply{found, record}
+			}(p)
+		}
+		for range batch {
+			reply := <-replies
+			if r := reply.record; r != nil && string(r.ID) == string(target) && r.Verify() == nil {
+				if record == nil || r.Seq > record.Seq {
+					record = r
+				}
+			}
+			for _, p := range reply.found {
+				if !seen[string(p.id)] {
+					seen[string(p.id)] = true
+					closest = append(closest, p)
+				}
+			}
+		}
+		sortByDistance(closest, target)
+		if len(closest) > bucketSize {
+			closest = closest[:bucketSize]
+		}
+	}
+}
+
+func (k *kademlia) ping(addr *net.UDPAddr) error {
+	_, err := k.request(addr, &internal.Ping{})
+	return err
+}
+
+func (k *kademlia) findNode(addr *net.UDPAddr, target []byte) ([]*peer, *Record, error) {
+	reply, err := k.request(addr, &internal.LookupNodeRequest{Target: &internal.ID{Id: target}})
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, ok := reply.(*internal.LookupNodeResponse)
+	if !ok {
+		return nil, nil, errors.New("kademlia: unexpected reply")
+	}
+	var found []*peer
+	for _, id := range resp.GetPeers() {
+		a, err := net.ResolveUDPAddr("udp", id.GetAddress())
+		if err != nil || len(id.GetId()) != len(k.self) {
+			continue
+		}
+		found = append(found, &peer{id: id.GetId(), addr: a})
+	}
+	return found, recordFromProto(resp.GetRecord()), nil
+}
+
+// request sends m to addr and waits for the reply with the same nonce
+func (k *kademlia) request(addr *net.UDPAddr, m proto.Message) (proto.Message, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	nonce := binary.BigEndian.Uint64(b[:])
+	replyc := make(chan *internal.Package, 1)
+	k.mu.Lock()
+	k.pending[nonce] = replyc
+	k.mu.Unlock()
+	defer func() {
+		k.mu.Lock()
+		delete(k.pending, nonce)
+		k.mu.Unlock()
+	}()
+
+	if err := k.send(addr, m, nonce, false); err != nil {
+		return nil, err
+	}
+	select {
+	case pa := <-replyc:
+		var ptr ptypes.DynamicAny
+		if err := ptypes.UnmarshalAny(pa.GetAnything(), &ptr); err != nil {
+			return nil, err
+		}
+		return ptr.Message, nil
+	case <-time.After(requestTimeout):
+		return nil, errTimeout
+	case <-k.ctx.Done():
+		return nil, k.ctx.Err()
+	}
+}
+
+func (k *kademlia) send(addr *net.UDPAddr, m proto.Message, nonce uint64, reply bool) error {
+	anything, err := ptypes.MarshalAny(m)
+	if err != nil {
+		return err
+	}
+	bytes, err := proto.Marshal(&internal.Package{
+		Anything:     anything,
+		Sender:       &internal.ID{Id: k.self},
+		RequestNonce: nonce,
+		ReplyFlag:    reply,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = k.conn.WriteToUDP(bytes, addr)
+	return err
+}
+
+func (k *kademlia) readLoop() {
+	buffer := make([]byte, packetSize)
+	for {
+		n, from, err := k.conn.ReadFromUDP(buffer)
+		if err != nil {
+			select {
+			case <-k.ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+		pa := new(internal.Package)
+		if err := proto.Unmarshal(buffer[:n], pa); err != nil {
+			continue
+		}
+		k.handle(pa, from)
+	}
+}
+
+// handle answers requests and hands replies to their request. The sender is
+// added to the routing table at the address it sent from.
+func (k *kademlia) handle(pa *internal.Package, from *net.UDPAddr) {
+	id := pa.GetSender().GetId()
+	if len(id) != len(k.self) || string(id) == string(k.self) {
+		return
+	}
+	if pa.GetReplyFlag() {
+		k.mu.Lock()
+		replyc := k.pending[pa.GetRequestNonce()]
+		k.mu.Unlock()
+		if replyc == nil {
+			return
+		}
+		select {
+		case replyc <- pa:
+		default:
+		}
+		k.seen(&peer{id: id, addr: from})
+		return
+	}
+
+	var ptr ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(pa.GetAnything(), &ptr); err != nil {
+		return
+	}
+	switch content := ptr.Message.(type) {
+	case *internal.Ping:
+		k.send(from, &internal.Pong{}, pa.GetRequestNonce(), true)
+	case *internal.LookupNodeRequest:
+		target := content.GetTarget().GetId()
+		resp := &internal.LookupNodeResponse{}
+		for _, p := range k.table.closest(target, bucketSize) {
+			if string(p.id) != string(id) {
+				resp.Peers = append(resp.Peers, &internal.ID{Id: p.id, Address: p.addr.String()})
+			}
+		}
+		k.mu.Lock()
+		r := k.records[string(target)]
+		if string(target) == string(k.self) {
+			r = k.record
+		}
+		k.mu.Unlock()
+		if r != nil {
+			resp.Record = r.toProto()
+		}
+		k.send(from, resp, pa.GetRequestNonce(), true)
+	case *internal.StoreRecord:
+		if r := recordFromProto(content.GetRecord()); r != nil && string(r.ID) == string(id) {
+			k.keep(r)
+		}
+	default:
+		return
+	}
+	k.seen(&peer{id: id, addr: from})
+}
+
+// seen adds p to the routing table. When its bucket is full the least
+// recently seen peer is pinged and replaced by p only if it does not answer.
+func (k *kademlia) seen(p *peer) {
+	oldest := k.table.add(p)
+	if oldest == nil {
+		return
+	}
+	go func() {
+		if k.ping(oldest.addr) != nil {
+			k.table.remove(oldest.id)
+			k.table.add(p)
+		}
+	}()
+}
+
+func (k *kademlia) refreshLoop() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			k.publish()
+			random := make([]byte, len(k.self))
+			rand.Read(random)
+			k.lookup(random)
+			k.savePeers()
+		case <-k.ctx.Done():
+			return
+		}
+	}
+}
+
+// loadPeers pings the peers saved by the last run, which adds the ones still
+// there to the routing table
+func (k *kademlia) loadPeers() {
+	if k.path == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return
+	}
+	var known []knownPeer
+	if err := json.Unmarshal(data, &known); err != nil {
+		return
+	}
+	for _, p := range known {
+		if addr, err := net.ResolveUDPAddr("udp", p.Address); err == nil {
+			go k.ping(addr)
+		}
+	}
+}
+
+func (k *kademlia) savePeers() error {
+	if k.path == "" {
+		return nil
+	}
+	known := []knownPeer{}
+	for _, p := range k.table.peers() {
+		known = append(known, knownPeer{ID: hex.EncodeToString(p.id), Address: p.addr.String()})
+	}
+	data, err := json.MarshalIndent(known, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp := k.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.path)
+}
@@ -0,0 +1,151 @@
+package discover
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func randomID() []byte {
+	id := make([]byte, 20)
+	rand.Read(id)
+	return id
+}
+
+func signRecord(key *ecdsa.PrivateKey, address string, seq uint64) *Record {
+	id := crypto.PubkeyToAddress(key.PublicKey).Bytes()
+	text := RecordText(id, address, seq)
+	sig, _ := crypto.Sign(crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text))), key)
+	return &Record{ID: id, Address: address, Seq: seq, Signature: sig}
+}
+
+func TestRecord(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	r := signRecord(key, "10.0.0.1:9501", 1)
+	if err := r.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if r.IP().String() != "10.0.0.1" || r.Port() != "9501" {
+		t.Errorf("record of %s port %s", r.IP(), r.Port())
+	}
+	forged := *r
+	forged.Address = "10.0.0.2:9501"
+	if forged.Verify() == nil {
+		t.Error("record redirected to another address verified")
+	}
+	other, _ := crypto.GenerateKey()
+	forged = *signRecord(other, r.Address, 2)
+	forged.ID = r.ID
+	if forged.Verify() == nil {
+		t.Error("record signed by another account verified")
+	}
+}
+
+func TestTable(t *testing.T) {
+	self := make([]byte, 20)
+	tb := newTable(self)
+	// Every ID starting with a one bit falls into bucket 0
+	var first *peer
+	for i := 0; i < bucketSize+1; i++ {
+		id := randomID()
+		id[0] |= 0x80
+		p := &peer{id: id, addr: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: i}}
+		if first == nil {
+			first = p
+		}
+		if oldest := tb.add(p); i < bucketSize && oldest != nil {
+			t.Fatalf("peer %d not added to a bucket with room", i)
+		} else if i == bucketSize && oldest != first {
+			t.Fatal("full bucket did not return its least recently seen peer")
+		}
+	}
+	if tb.len() != bucketSize {
+		t.Errorf("table of %d peers, want %d", tb.len(), bucketSize)
+	}
+
+	near := make([]byte, 20)
+	near[19] = 1
+	tb.add(&peer{id: near, addr: &net.UDPAddr{}})
+	if closest := tb.closest(self, 1); len(closest) != 1 || string(closest[0].id) != string(near) {
+		t.Error("closest peer is not the nearest one")
+	}
+}
+
+func TestKademlia(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kademlia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newNode := func(id []byte, port int, path string) *kademlia {
+		m, err := NewKademlia(id, port, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.(*kademlia)
+	}
+	key, _ := crypto.GenerateKey()
+	a := newNode(randomID(), 17946, "")
+	defer a.Leave()
+	b := newNode(crypto.PubkeyToAddress(key.PublicKey).Bytes(), 17947, "")
+	defer b.Leave()
+	path := filepath.Join(dir, "peers.json")
+	c := newNode(randomID(), 17948, path)
+
+	for _, m := range []*kademlia{b, c} {
+		if num, err := m.Join([]string{"127.0.0.1:17946"}); err != nil || num != 1 {
+			t.Fatalf("Join reached %d nodes: %v", num, err)
+		}
+	}
+
+	// c learns of b through a
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Lookup(b.self) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Lookup did not find a peer known to the bootstrap node")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if c.Lookup(randomID()) != nil {
+		t.Error("Lookup found an unknown ID")
+	}
+
+	// The record b stores in the DHT resolves its ID to its p2p address
+	if err := b.Advertise(signRecord(key, "127.0.0.1:9501", 1)); err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if r := c.Resolve(b.self); r != nil {
+			if r.Address != "127.0.0.1:9501" {
+				t.Errorf("resolved to %s", r.Address)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Resolve did not find the advertised record")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// A restart on the saved peers finds them again without bootstrapping
+	c.Leave()
+	d := newNode(randomID(), 17948, path)
+	defer d.Leave()
+	deadline = time.Now().Add(5 * time.Second)
+	for d.NumOfPeers() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d peers after a restart, want 3", d.NumOfPeers())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -15,8 +15,15 @@ type Membership interface {
 	NumOfPeers() int
 	MembersIP() (addr []net.IP)
 	MembersID() (list [][]byte)
+	// Advertise spreads the record of the local node
+	Advertise(r *Record) error
+	// Resolve returns the verified record of the given ID, nil if unknown
+	Resolve(id []byte) *Record
 }
 
+// recordTag is the Serf tag holding the record of a member
+const recordTag = "record"
+
 //NewSerfNet creates a Serf implementation
 func NewSerfNet(Addr net.IP, id []byte) (Membership, error) {
 	var err error
@@ -92,3 +99,28 @@ func (s *serfNet) MembersID() (list [][]byte) {
 	}
 	return
 }
+
+// Advertise sets the record as a tag of the local member
+func (s *serfNet) Advertise(r *Record) error {
+	tag, err := r.encode()
+	if err != nil {
+		return err
+	}
+	return s.serf.SetTags(map[string]string{recordTag: tag})
+}
+
+// Resolve returns the record tag of the member with the given ID
+func (s *serfNet) Resolve(id []byte) *Record {
+	members := s.serf.Members()
+	for i := 0; i < len(members); i++ {
+		if members[i].Name != string(id) {
+			continue
+		}
+		r, err := decodeRecord(members[i].Tags[recordTag])
+		if err != nil || string(r.ID) != string(id) || r.Verify() != nil {
+			return nil
+		}
+		return r
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package discover
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/DOSNetwork/core/p2p/internal"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/protobuf/proto"
+)
+
+// recordPrefix separates record signatures from other personal messages
+// signed with the node key
+const recordPrefix = "DOS p2p record:"
+
+// Record is the address of the p2p listener of a node, signed by the Ethereum
+// key of its ID so that no other node can redirect connections to it
+type Record struct {
+	ID      []byte
+	Address string
+	// Seq orders the records of a node, the highest one is current
+	Seq       uint64
+	Signature []byte
+}
+
+// RecordText is what a node signs to advertise address
+func RecordText(id []byte, address string, seq uint64) []byte {
+	var b bytes.Buffer
+	b.WriteString(recordPrefix)
+	b.Write(id)
+	binary.Write(&b, binary.BigEndian, seq)
+	b.WriteString(address)
+	return b.Bytes()
+}
+
+// Verify checks that the record is signed by the account of its ID and that
+// its address is an IP and a port
+func (r *Record) Verify() error {
+	if _, _, err := net.SplitHostPort(r.Address); err != nil {
+		return err
+	}
+	if len(r.Signature) != 65 {
+		return fmt.Errorf("record signature of %d bytes", len(r.Signature))
+	}
+	text := RecordText(r.ID, r.Address, r.Seq)
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text)))
+	pub, err := crypto.SigToPub(hash, r.Signature)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(crypto.PubkeyToAddress(*pub).Bytes(), r.ID) {
+		return errors.New("record signed by another account")
+	}
+	return nil
+}
+
+// IP returns the IP address of the record
+func (r *Record) IP() net.IP {
+	host, _, _ := net.SplitHostPort(r.Address)
+	return net.ParseIP(host)
+}
+
+// Port returns the port of the record
+func (r *Record) Port() string {
+	_, port, _ := net.SplitHostPort(r.Address)
+	return port
+}
+
+func (r *Record) toProto() *internal.Record {
+	return &internal.Record{Id: r.ID, Address: r.Address, Seq: r.Seq, Signature: r.Signature}
+}
+
+func recordFromProto(r *internal.Record) *Record {
+	if r == nil {
+		return nil
+	}
+	return &Record{ID: r.GetId(), Address: r.GetAddress(), Seq: r.GetSeq(), Signature: r.GetSignature()}
+}
+
+// encode and decode turn a record into text that fits a Serf tag
+func (r *Record) encode() (string, error) {
+	data, err := proto.Marshal(r.toProto())
+	return hex.EncodeToString(data), err
+}
+
+func decodeRecord(s string) (*Record, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	r := new(internal.Record)
+	if err := proto.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return recordFromProto(r), nil
+}
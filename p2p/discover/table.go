@@ -0,0 +1,152 @@
+package discover
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"sync"
+)
+
+// bucketSize is the k of Kademlia, the most peers a bucket holds
+const bucketSize = 16
+
+type peer struct {
+	id   []byte
+	addr *net.UDPAddr
+}
+
+// table is the Kademlia routing table. Bucket i holds the peers whose ID
+// shares its first i bits with the local ID, least recently seen first.
+type table struct {
+	mu      sync.Mutex
+	self    []byte
+	buckets [][]*peer
+}
+
+func newTable(self []byte) *table {
+	return &table{self: self, buckets: make([][]*peer, 8*len(self))}
+}
+
+// bucketIndex returns the length of the prefix id shares with the local ID,
+// -1 for the local ID or an ID of another length
+func (t *table) bucketIndex(id []byte) int {
+	if len(id) != len(t.self) {
+		return -1
+	}
+	for i := range id {
+		if x := id[i] ^ t.self[i]; x != 0 {
+			n := 0
+			for ; x&0x80 == 0; x <<= 1 {
+				n++
+			}
+			return 8*i + n
+		}
+	}
+	return -1
+}
+
+// add marks p as seen. If its bucket is full, p is not added and the least
+// recently seen peer of the bucket is returned, to be replaced if it is gone.
+func (t *table) add(p *peer) (oldest *peer) {
+	i := t.bucketIndex(p.id)
+	if i < 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.buckets[i]
+	for j, q := range b {
+		if bytes.Equal(q.id, p.id) {
+			t.buckets[i] = append(append(b[:j:j], b[j+1:]...), p)
+			return nil
+		}
+	}
+	if len(b) >= bucketSize {
+		return b[0]
+	}
+	t.buckets[i] = append(b, p)
+	return nil
+}
+
+func (t *table) remove(id []byte) {
+	i := t.bucketIndex(id)
+	if i < 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.buckets[i]
+	for j, q := range b {
+		if bytes.Equal(q.id, id) {
+			t.buckets[i] = append(b[:j:j], b[j+1:]...)
+			return
+		}
+	}
+}
+
+func (t *table) find(id []byte) *peer {
+	i := t.bucketIndex(id)
+	if i < 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, q := range t.buckets[i] {
+		if bytes.Equal(q.id, id) {
+			return q
+		}
+	}
+	return nil
+}
+
+func (t *table) peers() (list []*peer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range t.buckets {
+		list = append(list, b...)
+	}
+	return
+}
+
+func (t *table) len() (n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range t.buckets {
+		n += len(b)
+	}
+	return
+}
+
+// closest returns the n known peers closest to target
+func (t *table) closest(target []byte, n int) []*peer {
+	list := t.peers()
+	sortByDistance(list, target)
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// sortByDistance sorts peers by the XOR distance of their ID to target
+func sortByDistance(list []*peer, target []byte) {
+	sort.Slice(list, func(i, j int) bool {
+		return closer(list[i].id, list[j].id, target)
+	})
+}
+
+// closer reports whether a is closer to target than b
+func closer(a, b, target []byte) bool {
+	for i := range target {
+		var da, db byte
+		if i < len(a) {
+			da = a[i] ^ target[i]
+		}
+		if i < len(b) {
+			db = b[i] ^ target[i]
+		}
+		if da != db {
+			return da < db
+		}
+	}
+	return false
+}
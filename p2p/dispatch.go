@@ -0,0 +1,288 @@
+package p2p
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Policy is what happens to a message for a subscriber that is not ready
+type Policy int
+
+const (
+	// Backpressure makes the message wait for the subscriber up to
+	// dispatchTimeout in one of dispatchLimit slots, and drops it if there is
+	// no slot left or the wait times out
+	Backpressure Policy = iota
+	// Drop drops the message at once
+	Drop
+)
+
+// dispatchLimit bounds the messages waiting for a subscriber, which wait at
+// most dispatchTimeout. handlerLimit bounds the handlers running at once,
+// beyond it requests are dropped and the peers retry them.
+const (
+	dispatchLimit   = 1024
+	dispatchTimeout = time.Minute
+	handlerLimit    = 256
+)
+
+// Filter selects the messages of a subscription
+type Filter func(msg P2PMessage) bool
+
+// FromSenders selects the messages sent by one of ids
+func FromSenders(ids ...[]byte) Filter {
+	return func(msg P2PMessage) bool {
+		for _, id := range ids {
+			if bytes.Equal(msg.Sender, id) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InSession selects the messages with sessionID as session ID, like the
+// messages of a DKG
+func InSession(sessionID string) Filter {
+	return func(msg P2PMessage) bool {
+		m, ok := msg.Msg.Message.(interface{ GetSessionId() string })
+		return ok && m.GetSessionId() == sessionID
+	}
+}
+
+// SubscribeOptions configure a subscription. Buffer is the size of its
+// channel and a nil Filter selects every message.
+type SubscribeOptions struct {
+	Buffer int
+	Filter Filter
+	Policy Policy
+}
+
+// Subscription receives the messages of its types on C
+type Subscription struct {
+	C      <-chan P2PMessage
+	out    chan P2PMessage
+	done   chan struct{}
+	types  []string
+	filter Filter
+	policy Policy
+}
+
+// Handler answers a request. No reply is sent if reply is nil or err is set.
+type Handler func(msg P2PMessage) (reply proto.Message, err error)
+
+// DispatchStats counts the messages of a type
+type DispatchStats struct {
+	Type        string
+	Subscribers int
+	Delivered   uint64
+	Dropped     uint64
+	Handled     uint64
+	// Unhandled are the requests dropped because too many handlers ran
+	Unhandled uint64
+}
+
+type typeStats struct {
+	delivered, dropped, handled, unhandled uint64
+}
+
+// dispatcher routes the received messages to every subscription of their
+// type and the requests to the handler of their type
+type dispatcher struct {
+	mu       sync.Mutex
+	subs     map[string][]*Subscription
+	handlers map[string]Handler
+	stats    map[string]*typeStats
+	pending  chan struct{}
+	running  chan struct{}
+	reply    func(id []byte, nonce uint64, m proto.Message) error
+}
+
+func newDispatcher(reply func(id []byte, nonce uint64, m proto.Message) error) *dispatcher {
+	return &dispatcher{
+		subs:     make(map[string][]*Subscription),
+		handlers: make(map[string]Handler),
+		stats:    make(map[string]*typeStats),
+		pending:  make(chan struct{}, dispatchLimit),
+		running:  make(chan struct{}, handlerLimit),
+		reply:    reply,
+	}
+}
+
+// typeName names the type of a message, pointer or not
+func typeName(m interface{}) string {
+	t := reflect.TypeOf(m)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// typeStats returns the counters of messageType. The caller holds the lock.
+func (d *dispatcher) typeStats(messageType string) *typeStats {
+	s := d.stats[messageType]
+	if s == nil {
+		s = new(typeStats)
+		d.stats[messageType] = s
+	}
+	return s
+}
+
+func (d *dispatcher) subscribe(opts SubscribeOptions, messages ...interface{}) *Subscription {
+	out := make(chan P2PMessage, opts.Buffer)
+	s := &Subscription{C: out, out: out, done: make(chan struct{}), filter: opts.Filter, policy: opts.Policy}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, m := range messages {
+		t := typeName(m)
+		s.types = append(s.types, t)
+		d.subs[t] = append(d.subs[t], s)
+		d.typeStats(t)
+	}
+	return s
+}
+
+func (d *dispatcher) unsubscribe(s *Subscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range s.types {
+		d.remove(t, s)
+	}
+}
+
+// unsubscribeAll removes every subscription to the types of messages
+func (d *dispatcher) unsubscribeAll(messages ...interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, m := range messages {
+		t := typeName(m)
+		for _, s := range d.subs[t] {
+			d.remove(t, s)
+		}
+	}
+}
+
+// remove takes s off the subscriptions of messageType and stops its pending
+// deliveries once it has no type left. The caller holds the lock.
+func (d *dispatcher) remove(messageType string, s *Subscription) {
+	subs := d.subs[messageType]
+	for i, x := range subs {
+		if x == s {
+			d.subs[messageType] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	for i, t := range s.types {
+		if t == messageType {
+			s.types = append(s.types[:i:i], s.types[i+1:]...)
+			break
+		}
+	}
+	if len(s.types) == 0 {
+		close(s.done)
+	}
+}
+
+func (d *dispatcher) handle(message interface{}, h Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t := typeName(message)
+	if h == nil {
+		delete(d.handlers, t)
+		return
+	}
+	d.handlers[t] = h
+	d.typeStats(t)
+}
+
+// dispatch hands msg to the subscriptions of its type that select it, and a
+// request to the handler of its type
+func (d *dispatcher) dispatch(msg P2PMessage) {
+	if msg.Msg.Message == nil {
+		return
+	}
+	t := typeName(msg.Msg.Message)
+	d.mu.Lock()
+	subs := d.subs[t]
+	h := d.handlers[t]
+	stats := d.typeStats(t)
+	d.mu.Unlock()
+
+	if h != nil && msg.Topic == "" {
+		select {
+		case d.running <- struct{}{}:
+			atomic.AddUint64(&stats.handled, 1)
+			go func() {
+				defer func() { <-d.running }()
+				reply, err := h(msg)
+				if err != nil {
+					logger.Error(err)
+					return
+				}
+				if reply != nil {
+					d.reply(msg.Sender, msg.RequestNonce, reply)
+				}
+			}()
+		default:
+			atomic.AddUint64(&stats.unhandled, 1)
+		}
+	}
+
+	for _, s := range subs {
+		if s.filter != nil && !s.filter(msg) {
+			continue
+		}
+		select {
+		case s.out <- msg:
+			atomic.AddUint64(&stats.delivered, 1)
+			continue
+		default:
+		}
+		if s.policy == Drop {
+			atomic.AddUint64(&stats.dropped, 1)
+			continue
+		}
+		select {
+		case d.pending <- struct{}{}:
+			go func(s *Subscription) {
+				defer func() { <-d.pending }()
+				select {
+				case s.out <- msg:
+					atomic.AddUint64(&stats.delivered, 1)
+				case <-s.done:
+					atomic.AddUint64(&stats.dropped, 1)
+				case <-time.After(dispatchTimeout):
+					atomic.AddUint64(&stats.dropped, 1)
+				}
+			}(s)
+		default:
+			atomic.AddUint64(&stats.dropped, 1)
+		}
+	}
+}
+
+func (d *dispatcher) statistics() []DispatchStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list := make([]DispatchStats, 0, len(d.stats))
+	for t, s := range d.stats {
+		list = append(list, DispatchStats{
+			Type:        t,
+			Subscribers: len(d.subs[t]),
+			Delivered:   atomic.LoadUint64(&s.delivered),
+			Dropped:     atomic.LoadUint64(&s.dropped),
+			Handled:     atomic.LoadUint64(&s.handled),
+			Unhandled:   atomic.LoadUint64(&s.unhandled),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Type < list[j].Type
+	})
+	return list
+}
@@ -0,0 +1,98 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func message(sender string, m proto.Message) P2PMessage {
+	return P2PMessage{Msg: ptypes.DynamicAny{Message: m}, Sender: []byte(sender)}
+}
+
+func TestDispatchSubscribers(t *testing.T) {
+	d := newDispatcher(nil)
+	first := d.subscribe(SubscribeOptions{Buffer: 1}, Ping{})
+	second := d.subscribe(SubscribeOptions{Buffer: 1, Filter: FromSenders([]byte("a"))}, &Ping{}, Pong{})
+
+	d.dispatch(message("b", &Ping{Count: 1}))
+	d.dispatch(message("a", &Pong{Count: 2}))
+	if msg := <-first.C; msg.Msg.Message.(*Ping).Count != 1 {
+		t.Errorf("first subscriber got %v", msg.Msg.Message)
+	}
+	if msg := <-second.C; msg.Msg.Message.(*Pong).Count != 2 {
+		t.Errorf("filtered subscriber got %v", msg.Msg.Message)
+	}
+
+	d.unsubscribe(first)
+	d.dispatch(message("a", &Ping{Count: 3}))
+	select {
+	case <-first.C:
+		t.Error("message delivered after Unsubscribe")
+	default:
+	}
+	if msg := <-second.C; msg.Msg.Message.(*Ping).Count != 3 {
+		t.Errorf("second subscriber got %v", msg.Msg.Message)
+	}
+}
+
+func TestDispatchPolicy(t *testing.T) {
+	d := newDispatcher(nil)
+	drop := d.subscribe(SubscribeOptions{Policy: Drop}, Ping{})
+	wait := d.subscribe(SubscribeOptions{}, Ping{})
+	d.dispatch(message("a", &Ping{Count: 1}))
+	select {
+	case <-drop.C:
+		t.Error("message kept for a subscriber with the Drop policy")
+	case <-time.After(100 * time.Millisecond):
+	}
+	select {
+	case <-wait.C:
+	case <-time.After(time.Second):
+		t.Error("message not kept for a subscriber with backpressure")
+	}
+	// The waiting delivery is counted once it is received
+	stats := d.statistics()
+	for deadline := time.Now().Add(time.Second); stats[0].Delivered == 0 && time.Now().Before(deadline); stats = d.statistics() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(stats) != 1 || stats[0].Type != "p2p.Ping" || stats[0].Delivered != 1 || stats[0].Dropped != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestDispatchHandler(t *testing.T) {
+	replies := make(chan proto.Message, 1)
+	d := newDispatcher(func(id []byte, nonce uint64, m proto.Message) error {
+		if string(id) != "a" || nonce != 5 {
+			t.Errorf("reply to %s nonce %d", id, nonce)
+		}
+		replies <- m
+		return nil
+	})
+	d.handle(Ping{}, func(msg P2PMessage) (proto.Message, error) {
+		return &Pong{Count: msg.Msg.Message.(*Ping).Count + 1}, nil
+	})
+	msg := message("a", &Ping{Count: 1})
+	msg.RequestNonce = 5
+	d.dispatch(msg)
+	select {
+	case m := <-replies:
+		if m.(*Pong).Count != 2 {
+			t.Errorf("reply %v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request not answered")
+	}
+
+	// Published messages are no requests
+	msg.Topic = "topic"
+	d.dispatch(msg)
+	select {
+	case <-replies:
+		t.Error("published message answered")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
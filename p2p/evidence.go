@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/DOSNetwork/core/sign/bls"
+	"github.com/DOSNetwork/core/suites"
+	"github.com/dedis/kyber"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/protobuf/proto"
+)
+
+// packagePrefix separates package digests from other bn256 signatures
+const packagePrefix = "DOS p2p package:"
+
+// packageDigest hashes every field of pa but the signature, each variable
+// length field prefixed with its length
+func packageDigest(pa *Package) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(packagePrefix)
+	field := func(b []byte) {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(b)))
+		buf.Write(size[:])
+		buf.Write(b)
+	}
+	field(pa.GetSender())
+	field([]byte(pa.GetAnything().GetTypeUrl()))
+	field(pa.GetAnything().GetValue())
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], pa.GetRequestNonce())
+	buf.Write(nonce[:])
+	if pa.GetReplyFlag() {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	field(pa.GetScope())
+	return crypto.Keccak256(buf.Bytes())
+}
+
+func signPackage(suite suites.Suite, secKey kyber.Scalar, pa *Package) (err error) {
+	pa.Signature, err = bls.Sign(suite, secKey, packageDigest(pa))
+	return
+}
+
+func verifyPackage(suite suites.Suite, pub kyber.Point, pa *Package) error {
+	return bls.Verify(suite, pub, packageDigest(pa), pa.GetSignature())
+}
+
+// Evidence is a package as it was received together with the public key that
+// signed it and the certificate of that key by the sender. It proves to anyone
+// that the sender sent the package, so a member can be blamed for a bad DKG
+// deal or signature share.
+type Evidence struct {
+	Package     []byte
+	PublicKey   []byte
+	Certificate []byte
+}
+
+// Verify checks the evidence and returns the package it proves was sent by its
+// sender
+func (e *Evidence) Verify() (*Package, error) {
+	pa := new(Package)
+	if err := proto.Unmarshal(e.Package, pa); err != nil {
+		return nil, err
+	}
+	if err := verifyCertificate(pa.GetSender(), e.PublicKey, e.Certificate); err != nil {
+		return nil, err
+	}
+	suite := suites.MustFind("bn256")
+	pub := suite.G2().Point()
+	if err := pub.UnmarshalBinary(e.PublicKey); err != nil {
+		return nil, err
+	}
+	if err := verifyPackage(suite, pub, pa); err != nil {
+		return nil, errors.New("evidence: package signature is invalid")
+	}
+	return pa, nil
+}
@@ -0,0 +1,59 @@
+package p2p
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DOSNetwork/core/suites"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func TestEvidence(t *testing.T) {
+	suite := suites.MustFind("bn256")
+	signer := newSigner()
+	self, err := newIdentity(suite, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anything, _ := ptypes.MarshalAny(&Ping{Count: 7})
+	pa := &Package{Sender: self.id, Anything: anything, RequestNonce: 3}
+	if err := signPackage(suite, self.secKey, pa); err != nil {
+		t.Fatal(err)
+	}
+	evidence := func(pa *Package, cert []byte) *Evidence {
+		data, _ := proto.Marshal(pa)
+		return &Evidence{Package: data, PublicKey: self.pubKeyBytes, Certificate: cert}
+	}
+
+	got, err := evidence(pa, self.certificate).Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.GetSender(), signer.Address().Bytes()) {
+		t.Errorf("evidence of sender %x, want %x", got.GetSender(), signer.Address().Bytes())
+	}
+
+	// Changing any field breaks the package signature
+	tampered := *pa
+	tampered.RequestNonce = 4
+	if _, err := evidence(&tampered, self.certificate).Verify(); err == nil {
+		t.Error("evidence of a package with another nonce verified")
+	}
+	tampered = *pa
+	tampered.ReplyFlag = true
+	if _, err := evidence(&tampered, self.certificate).Verify(); err == nil {
+		t.Error("evidence of a package turned into a reply verified")
+	}
+
+	// Blaming another node takes its certificate
+	other := newSigner()
+	framed := *pa
+	framed.Sender = other.Address().Bytes()
+	if err := signPackage(suite, self.secKey, &framed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := evidence(&framed, self.certificate).Verify(); err == nil {
+		t.Error("evidence against a node that did not certify the key verified")
+	}
+}
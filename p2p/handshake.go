@@ -0,0 +1,115 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DOSNetwork/core/p2p/noise"
+	"github.com/DOSNetwork/core/suites"
+	"github.com/dedis/kyber"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// handshakePrefix separates handshake signatures from other personal
+	// messages signed with the node key
+	handshakePrefix = "DOS p2p handshake:"
+	// certificatePrefix separates certificates of p2p public keys
+	certificatePrefix = "DOS p2p key:"
+)
+
+// Signer signs the handshake with the Ethereum key of the node ID.
+// onchain.Signer satisfies it.
+type Signer interface {
+	Address() common.Address
+	SignText(ctx context.Context, text []byte) ([]byte, error)
+}
+
+// PeerVerifier decides whether a peer that proved it holds the key of id may
+// connect, for example by checking that it is a registered node
+type PeerVerifier func(id []byte) error
+
+// identity holds the keys a node proves itself and signs its packages with.
+// The bn256 key pair signs packages and the certificate, signed once by the
+// Ethereum key of id, makes those signatures attributable to id.
+type identity struct {
+	id          []byte
+	secKey      kyber.Scalar
+	pubKey      kyber.Point
+	pubKeyBytes []byte
+	certificate []byte
+	noiseKey    noise.DHKey
+	signer      Signer
+}
+
+// newIdentity generates the p2p keys of the node and certifies them with signer
+func newIdentity(suite suites.Suite, signer Signer) (*identity, error) {
+	self := &identity{id: signer.Address().Bytes(), signer: signer}
+	self.secKey = suite.Scalar().Pick(suite.RandomStream())
+	self.pubKey = suite.Point().Mul(self.secKey, nil)
+	var err error
+	if self.pubKeyBytes, err = self.pubKey.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if self.noiseKey, err = noise.GenerateKey(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	if self.certificate, err = signer.SignText(ctx, certificateText(self.pubKeyBytes)); err != nil {
+		return nil, err
+	}
+	return self, nil
+}
+
+// handshakeText is what a node signs to bind its p2p public key and the
+// encrypted channel to its ID. The challenge of the peer keeps the signature
+// from being replayed and binding, the Noise handshake hash, from being
+// relayed to another channel.
+func handshakeText(pubKey, challenge, binding []byte) []byte {
+	text := append([]byte(handshakePrefix), pubKey...)
+	text = append(text, challenge...)
+	return append(text, binding...)
+}
+
+// verifyHandshake checks that sig is the signature by the account id of
+// pubKey, challenge and binding
+func verifyHandshake(id, pubKey, challenge, binding, sig []byte) error {
+	if err := verifyText(id, handshakeText(pubKey, challenge, binding), sig); err != nil {
+		return fmt.Errorf("handshake: %s", err)
+	}
+	return nil
+}
+
+// certificateText is what a node signs to own the packages signed with pubKey
+func certificateText(pubKey []byte) []byte {
+	return append([]byte(certificatePrefix), pubKey...)
+}
+
+// verifyCertificate checks that cert is the signature by the account id of
+// pubKey
+func verifyCertificate(id, pubKey, cert []byte) error {
+	if err := verifyText(id, certificateText(pubKey), cert); err != nil {
+		return fmt.Errorf("certificate: %s", err)
+	}
+	return nil
+}
+
+// verifyText checks that sig is the EIP-191 signature of text by the account id
+func verifyText(id, text, sig []byte) error {
+	if len(sig) != 65 {
+		return fmt.Errorf("signature of %d bytes", len(sig))
+	}
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text)))
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(crypto.PubkeyToAddress(*pub).Bytes(), id) {
+		return errors.New("signed by another account")
+	}
+	return nil
+}
@@ -322,7 +322,9 @@ func (m *LookupNodeRequest) GetTarget() *ID {
 }
 
 type LookupNodeResponse struct {
-	Peers                []*ID    `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	Peers []*ID `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	// record is the address record of the target, if known
+	Record               *Record  `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -359,6 +361,13 @@ func (m *LookupNodeResponse) GetPeers() []*ID {
 	return nil
 }
 
+func (m *LookupNodeResponse) GetRecord() *Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
 type Bytes struct {
 	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -397,6 +406,110 @@ func (m *Bytes) GetData() []byte {
 	return nil
 }
 
+// Record is the signed address a node advertises
+type Record struct {
+	Id      []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// seq orders the records of a node, the highest one is current
+	Seq uint64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	// signature is the EIP-191 signature of the record by the key of id
+	Signature            []byte   `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+func (*Record) Descriptor() ([]byte, []int) {
+	return fileDescriptor_package_e71f04067b664858, []int{8}
+}
+func (m *Record) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Record.Unmarshal(m, b)
+}
+func (m *Record) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Record.Marshal(b, m, deterministic)
+}
+func (dst *Record) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Record.Merge(dst, src)
+}
+func (m *Record) XXX_Size() int {
+	return xxx_messageInfo_Record.Size(m)
+}
+func (m *Record) XXX_DiscardUnknown() {
+	xxx_messageInfo_Record.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Record proto.InternalMessageInfo
+
+func (m *Record) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *Record) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Record) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Record) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// StoreRecord asks a node to keep a record for the nodes looking it up
+type StoreRecord struct {
+	Record               *Record  `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StoreRecord) Reset()         { *m = StoreRecord{} }
+func (m *StoreRecord) String() string { return proto.CompactTextString(m) }
+func (*StoreRecord) ProtoMessage()    {}
+func (*StoreRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_package_e71f04067b664858, []int{9}
+}
+func (m *StoreRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoreRecord.Unmarshal(m, b)
+}
+func (m *StoreRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoreRecord.Marshal(b, m, deterministic)
+}
+func (dst *StoreRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoreRecord.Merge(dst, src)
+}
+func (m *StoreRecord) XXX_Size() int {
+	return xxx_messageInfo_StoreRecord.Size(m)
+}
+func (m *StoreRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoreRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoreRecord proto.InternalMessageInfo
+
+func (m *StoreRecord) GetRecord() *Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Package)(nil), "internal.Package")
 	proto.RegisterType((*Hi)(nil), "internal.Hi")
@@ -406,34 +519,40 @@ func init() {
 	proto.RegisterType((*LookupNodeRequest)(nil), "internal.LookupNodeRequest")
 	proto.RegisterType((*LookupNodeResponse)(nil), "internal.LookupNodeResponse")
 	proto.RegisterType((*Bytes)(nil), "internal.Bytes")
+	proto.RegisterType((*Record)(nil), "internal.Record")
+	proto.RegisterType((*StoreRecord)(nil), "internal.StoreRecord")
 }
 
 func init() { proto.RegisterFile("package.proto", fileDescriptor_package_e71f04067b664858) }
 
 var fileDescriptor_package_e71f04067b664858 = []byte{
-	// 375 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x52, 0xc1, 0x8e, 0xd3, 0x30,
-	0x10, 0x55, 0xb2, 0x69, 0xda, 0x0e, 0x5d, 0x24, 0x2c, 0x84, 0x2c, 0x60, 0xa5, 0x28, 0x70, 0xc8,
-	0x29, 0x8b, 0xe0, 0x02, 0x47, 0xd0, 0x0a, 0x51, 0x01, 0x55, 0x95, 0x1f, 0xa8, 0x9c, 0x66, 0x6a,
-	0xac, 0x06, 0xdb, 0xd8, 0xce, 0xc1, 0x37, 0x3e, 0x1d, 0xc5, 0x76, 0x01, 0xc1, 0x75, 0x0f, 0x96,
-	0x3d, 0xef, 0xbd, 0x19, 0xcf, 0x1b, 0x1b, 0xae, 0x35, 0x3b, 0x9e, 0x19, 0xc7, 0x56, 0x1b, 0xe5,
-	0x14, 0x59, 0x09, 0xe9, 0xd0, 0x48, 0x36, 0x3e, 0xbd, 0x09, 0x40, 0x3f, 0x9d, 0x6e, 0xb5, 0xf3,
-	0x1a, 0xed, 0x2d, 0x93, 0x7e, 0x5e, 0x51, 0x58, 0xff, 0xcc, 0x61, 0xb9, 0x8f, 0xa9, 0xe4, 0x15,
-	0xac, 0x98, 0xf4, 0xee, 0x9b, 0x90, 0x9c, 0x66, 0x55, 0xd6, 0x3c, 0x78, 0xfd, 0xb8, 0xe5, 0x4a,
-	0xf1, 0x31, 0x55, 0xed, 0xa7, 0x53, 0xfb, 0x5e, 0xfa, 0xee, 0xb7, 0x8a, 0x3c, 0x81, 0x52, 0x4f,
-	0xfd, 0x19, 0x3d, 0xcd, 0xab, 0xac, 0xd9, 0x74, 0x29, 0x22, 0xcf, 0x61, 0x6d, 0x05, 0x97, 0xcc,
-	0x4d, 0x06, 0xe9, 0x55, 0xa0, 0xfe, 0x00, 0xe4, 0x25, 0x94, 0x16, 0xe5, 0x80, 0x86, 0x16, 0xe1,
-	0x96, 0x4d, 0x7b, 0xe9, 0xb6, 0xdd, 0xde, 0x75, 0x89, 0x23, 0x2f, 0xe0, 0xda, 0xe0, 0x8f, 0x09,
-	0xad, 0x3b, 0x48, 0x25, 0x8f, 0x48, 0x17, 0x55, 0xd6, 0x14, 0xdd, 0x26, 0x81, 0xbb, 0x19, 0x9b,
-	0x45, 0xdf, 0xd1, 0x5a, 0xc6, 0x31, 0x89, 0xca, 0x28, 0x4a, 0x60, 0x14, 0xdd, 0x00, 0x18, 0xd4,
-	0xa3, 0x3f, 0x9c, 0x46, 0xc6, 0xe9, 0xb2, 0xca, 0x9a, 0x55, 0xb7, 0x0e, 0xc8, 0xc7, 0x91, 0xf1,
-	0xfa, 0x2b, 0xe4, 0x9f, 0xc4, 0x2c, 0xd2, 0x53, 0x3f, 0x8a, 0xe3, 0x61, 0xb6, 0x93, 0xc5, 0x9e,
-	0x23, 0xf2, 0x19, 0x3d, 0xa1, 0xb0, 0x64, 0xc3, 0x60, 0xd0, 0xda, 0x60, 0x75, 0xdd, 0x5d, 0x42,
-	0xf2, 0x10, 0x72, 0x31, 0x24, 0x93, 0xb9, 0x18, 0xe6, 0x72, 0xdb, 0xbb, 0xfb, 0x2b, 0x57, 0x42,
-	0xb1, 0x17, 0x92, 0x87, 0x5d, 0x49, 0x5e, 0xbf, 0x83, 0x47, 0x5f, 0x94, 0x3a, 0x4f, 0x7a, 0xa7,
-	0x06, 0xec, 0xe2, 0x2c, 0xe6, 0x89, 0x3a, 0x66, 0x38, 0xba, 0xf4, 0x6e, 0xff, 0x4c, 0x34, 0x72,
-	0xf5, 0x5b, 0x20, 0x7f, 0xa7, 0x5a, 0xad, 0xa4, 0x45, 0x52, 0xc3, 0x42, 0x23, 0x1a, 0x4b, 0xb3,
-	0xea, 0xea, 0xbf, 0xd4, 0x48, 0xd5, 0xcf, 0x60, 0xf1, 0xc1, 0x3b, 0xb4, 0x84, 0x40, 0x31, 0x30,
-	0xc7, 0x92, 0xa1, 0x70, 0xee, 0xcb, 0xf0, 0x39, 0xde, 0xfc, 0x0a, 0x00, 0x00, 0xff, 0xff, 0x93,
-	0x7a, 0xd2, 0xeb, 0x83, 0x02, 0x00, 0x00,
+	// 433 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x52, 0x4d, 0x6f, 0xd4, 0x30,
+	0x10, 0x95, 0xd3, 0x6c, 0x76, 0x77, 0x76, 0x8b, 0x8a, 0x85, 0x50, 0x04, 0x54, 0x8a, 0x02, 0x87,
+	0x9c, 0x52, 0x54, 0x0e, 0x88, 0x23, 0xa8, 0x42, 0x54, 0x40, 0x55, 0x99, 0x1f, 0xb0, 0x72, 0x36,
+	0xb3, 0x26, 0xda, 0x60, 0xbb, 0xb6, 0x73, 0xc8, 0x8d, 0x9f, 0x8e, 0x62, 0x7b, 0x59, 0xbe, 0xd4,
+	0x13, 0x87, 0x28, 0xf6, 0x9b, 0x37, 0xe3, 0xf7, 0x9e, 0x06, 0x4e, 0x35, 0xdf, 0xee, 0xb9, 0xc0,
+	0x5a, 0x1b, 0xe5, 0x14, 0x5d, 0x74, 0xd2, 0xa1, 0x91, 0xbc, 0x7f, 0x72, 0xee, 0x81, 0x66, 0xd8,
+	0x5d, 0x68, 0x37, 0x6a, 0xb4, 0x17, 0x5c, 0x8e, 0xd3, 0x17, 0x88, 0xe5, 0xf7, 0x04, 0xe6, 0xb7,
+	0xa1, 0x95, 0xbe, 0x84, 0x05, 0x97, 0xa3, 0xfb, 0xda, 0x49, 0x91, 0x93, 0x82, 0x54, 0xab, 0xcb,
+	0x47, 0xb5, 0x50, 0x4a, 0xf4, 0x71, 0x6a, 0x33, 0xec, 0xea, 0xb7, 0x72, 0x64, 0x3f, 0x59, 0xf4,
+	0x31, 0x64, 0x7a, 0x68, 0xf6, 0x38, 0xe6, 0x49, 0x41, 0xaa, 0x35, 0x8b, 0x37, 0xfa, 0x0c, 0x96,
+	0xb6, 0x13, 0x92, 0xbb, 0xc1, 0x60, 0x7e, 0xe2, 0x4b, 0x47, 0x80, 0xbe, 0x80, 0xcc, 0xa2, 0x6c,
+	0xd1, 0xe4, 0xa9, 0x7f, 0x65, 0x5d, 0x1f, 0xd4, 0xd6, 0xd7, 0x57, 0x2c, 0xd6, 0xe8, 0x73, 0x38,
+	0x35, 0x78, 0x37, 0xa0, 0x75, 0x1b, 0xa9, 0xe4, 0x16, 0xf3, 0x59, 0x41, 0xaa, 0x94, 0xad, 0x23,
+	0x78, 0x33, 0x61, 0x13, 0xe9, 0x1b, 0x5a, 0xcb, 0x05, 0x46, 0x52, 0x16, 0x48, 0x11, 0x0c, 0xa4,
+	0x73, 0x00, 0x83, 0xba, 0x1f, 0x37, 0xbb, 0x9e, 0x8b, 0x7c, 0x5e, 0x90, 0x6a, 0xc1, 0x96, 0x1e,
+	0x79, 0xdf, 0x73, 0x51, 0x7e, 0x86, 0xe4, 0x43, 0x37, 0x91, 0xf4, 0xd0, 0xf4, 0xdd, 0x76, 0x33,
+	0xd9, 0x21, 0x41, 0x73, 0x40, 0x3e, 0xe2, 0x48, 0x73, 0x98, 0xf3, 0xb6, 0x35, 0x68, 0xad, 0xb7,
+	0xba, 0x64, 0x87, 0x2b, 0x7d, 0x00, 0x49, 0xd7, 0x46, 0x93, 0x49, 0xd7, 0x4e, 0xe3, 0xae, 0xaf,
+	0xfe, 0xdf, 0xb8, 0x0c, 0xd2, 0xdb, 0x4e, 0x0a, 0xff, 0x57, 0x52, 0x94, 0x6f, 0xe0, 0xe1, 0x27,
+	0xa5, 0xf6, 0x83, 0xbe, 0x51, 0x2d, 0xb2, 0x90, 0xc5, 0x94, 0xa8, 0xe3, 0x46, 0xa0, 0xcb, 0xc9,
+	0xbf, 0x12, 0x0d, 0xb5, 0xb2, 0x01, 0xfa, 0x6b, 0xab, 0xd5, 0x4a, 0x5a, 0xa4, 0x25, 0xcc, 0x34,
+	0xa2, 0xb1, 0x39, 0x29, 0x4e, 0xfe, 0x6a, 0x0d, 0x25, 0x5a, 0x41, 0x66, 0x70, 0xab, 0x4c, 0xeb,
+	0xd5, 0xae, 0x2e, 0xcf, 0x8e, 0x24, 0xe6, 0x71, 0x16, 0xeb, 0xe5, 0x53, 0x98, 0xbd, 0x1b, 0x1d,
+	0x5a, 0x4a, 0x21, 0x6d, 0xb9, 0xe3, 0xd1, 0xba, 0x3f, 0x97, 0x0d, 0x64, 0x81, 0x1e, 0x5d, 0x92,
+	0x83, 0xcb, 0x7b, 0xf2, 0x38, 0x83, 0x13, 0x8b, 0x77, 0x3e, 0x90, 0x94, 0x4d, 0xc7, 0xdf, 0x97,
+	0x2b, 0xfd, 0x63, 0xb9, 0xca, 0xd7, 0xb0, 0xfa, 0xe2, 0x94, 0xc1, 0xf8, 0xd0, 0x51, 0x39, 0xb9,
+	0x5f, 0x79, 0x93, 0xf9, 0x1d, 0x7f, 0xf5, 0x63, 0x00, 0x99, 0x36, 0xcb, 0xef, 0x4a, 0x03, 0x00,
+	0x00,
 }
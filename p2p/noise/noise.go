@@ -0,0 +1,399 @@
+//Package noise implements the Noise_XX_25519_AESGCM_SHA256 protocol of the
+//Noise Protocol Framework (revision 34): the XX handshake, in which both
+//sides send an ephemeral and a static key, and the transport ciphers it
+//splits into.
+package noise
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	protocolName = "Noise_XX_25519_AESGCM_SHA256"
+	dhLen        = 32
+	hashLen      = 32
+	tagLen       = 16
+	// MaxMessageLen is the largest handshake message
+	MaxMessageLen = 65535
+)
+
+var (
+	//ErrNonceExhausted is returned once a cipher state sent 2^64-1 messages
+	ErrNonceExhausted = errors.New("noise: nonce exhausted")
+	//ErrHandshakeDone is returned by handshake calls after the handshake ended
+	ErrHandshakeDone = errors.New("noise: handshake already complete")
+	//ErrOutOfTurn is returned when a side writes or reads out of turn
+	ErrOutOfTurn    = errors.New("noise: message out of turn")
+	errShortMessage = errors.New("noise: message too short")
+	errBadKeyLen    = errors.New("noise: bad Curve25519 key length")
+	// errLowOrderKey is the all-zero output of a DH with a low order point,
+	// which a peer could send to fix the shared secret
+	errLowOrderKey = errors.New("noise: low order Curve25519 public key")
+)
+
+//DHKey is a Curve25519 key pair
+type DHKey struct {
+	Private []byte
+	Public  []byte
+}
+
+//GenerateKey creates a new Curve25519 key pair
+func GenerateKey() (DHKey, error) {
+	var private, public [dhLen]byte
+	if _, err := io.ReadFull(rand.Reader, private[:]); err != nil {
+		return DHKey{}, err
+	}
+	curve25519.ScalarBaseMult(&public, &private)
+	return DHKey{Private: private[:], Public: public[:]}, nil
+}
+
+//CipherState encrypts or decrypts the messages of one direction. Its nonce
+//counts the messages, so a dropped, replayed or reordered message fails to
+//decrypt.
+type CipherState struct {
+	k    []byte
+	n    uint64
+	aead cipher.AEAD
+}
+
+func (c *CipherState) initializeKey(k []byte) {
+	c.k = k
+	c.n = 0
+	block, _ := aes.NewCipher(k)
+	c.aead, _ = cipher.NewGCM(block)
+}
+
+func (c *CipherState) hasKey() bool {
+	return c.aead != nil
+}
+
+// nonce is 32 zero bits followed by the big-endian counter
+func nonce(n uint64) []byte {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf[4:], n)
+	return buf
+}
+
+//Encrypt seals plaintext with ad as associated data
+func (c *CipherState) Encrypt(ad, plaintext []byte) ([]byte, error) {
+	if !c.hasKey() {
+		return plaintext, nil
+	}
+	if c.n == math.MaxUint64 {
+		return nil, ErrNonceExhausted
+	}
+	out := c.aead.Seal(nil, nonce(c.n), plaintext, ad)
+	c.n++
+	return out, nil
+}
+
+//Decrypt opens ciphertext with ad as associated data
+func (c *CipherState) Decrypt(ad, ciphertext []byte) ([]byte, error) {
+	if !c.hasKey() {
+		return ciphertext, nil
+	}
+	if c.n == math.MaxUint64 {
+		return nil, ErrNonceExhausted
+	}
+	out, err := c.aead.Open(nil, nonce(c.n), ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+	c.n++
+	return out, nil
+}
+
+//Rekey replaces the key with one derived from it. Both sides must rekey after
+//the same message for the channel to keep working.
+func (c *CipherState) Rekey() {
+	k := c.aead.Seal(nil, nonce(math.MaxUint64), make([]byte, 32), nil)[:32]
+	n := c.n
+	c.initializeKey(k)
+	c.n = n
+}
+
+type symmetricState struct {
+	cs CipherState
+	ck []byte
+	h  []byte
+}
+
+func newSymmetricState() *symmetricState {
+	h := make([]byte, hashLen)
+	// The protocol name is shorter than the hash and is padded with zeros
+	copy(h, protocolName)
+	return &symmetricState{ck: append([]byte{}, h...), h: h}
+}
+
+// hkdf derives two outputs from the chaining key and ikm
+func (s *symmetricState) hkdf(ikm []byte) (out1, out2 []byte) {
+	r := hkdf.New(sha256.New, ikm, s.ck, nil)
+	out := make([]byte, 2*hashLen)
+	io.ReadFull(r, out)
+	return out[:hashLen], out[hashLen:]
+}
+
+func (s *symmetricState) mixKey(ikm []byte) {
+	var k []byte
+	s.ck, k = s.hkdf(ikm)
+	s.cs.initializeKey(k)
+}
+
+func (s *symmetricState) mixHash(data []byte) {
+	h := sha256.New()
+	h.Write(s.h)
+	h.Write(data)
+	s.h = h.Sum(nil)
+}
+
+func (s *symmetricState) encryptAndHash(plaintext []byte) ([]byte, error) {
+	ciphertext, err := s.cs.Encrypt(s.h, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(ciphertext)
+	return ciphertext, nil
+}
+
+func (s *symmetricState) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := s.cs.Decrypt(s.h, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+func (s *symmetricState) split() (c1, c2 *CipherState) {
+	k1, k2 := s.hkdf(nil)
+	c1, c2 = &CipherState{}, &CipherState{}
+	c1.initializeKey(k1)
+	c2.initializeKey(k2)
+	return
+}
+
+//HandshakeState runs one side of the XX handshake. The initiator sends its
+//ephemeral key, the responder its ephemeral and static keys, and the
+//initiator its static key.
+type HandshakeState struct {
+	ss        *symmetricState
+	s, e      DHKey
+	rs, re    []byte
+	initiator bool
+	step      int
+	send      *CipherState
+	recv      *CipherState
+}
+
+//NewHandshake starts a handshake with static as the long-lived key of this
+//side. The prologue must be the same on both sides.
+func NewHandshake(initiator bool, static DHKey, prologue []byte) *HandshakeState {
+	ss := newSymmetricState()
+	ss.mixHash(prologue)
+	return &HandshakeState{ss: ss, s: static, initiator: initiator}
+}
+
+func dh(private, public []byte) ([]byte, error) {
+	if len(private) != dhLen || len(public) != dhLen {
+		return nil, errBadKeyLen
+	}
+	var priv, pub, shared [dhLen]byte
+	copy(priv[:], private)
+	copy(pub[:], public)
+	curve25519.ScalarMult(&shared, &priv, &pub)
+	if subtle.ConstantTimeCompare(shared[:], make([]byte, dhLen)) == 1 {
+		return nil, errLowOrderKey
+	}
+	return shared[:], nil
+}
+
+func (h *HandshakeState) mixDH(private, public []byte) error {
+	shared, err := dh(private, public)
+	if err != nil {
+		return err
+	}
+	h.ss.mixKey(shared)
+	return nil
+}
+
+//WriteTurn reports whether this side writes the next message
+func (h *HandshakeState) WriteTurn() bool {
+	return (h.step%2 == 0) == h.initiator
+}
+
+//WriteMessage returns the next handshake message, carrying payload
+func (h *HandshakeState) WriteMessage(payload []byte) ([]byte, error) {
+	if h.Complete() {
+		return nil, ErrHandshakeDone
+	}
+	if !h.WriteTurn() {
+		return nil, ErrOutOfTurn
+	}
+	var msg []byte
+	var err error
+	switch h.step {
+	case 0:
+		if h.e, err = GenerateKey(); err != nil {
+			return nil, err
+		}
+		msg = append(msg, h.e.Public...)
+		h.ss.mixHash(h.e.Public)
+	case 1:
+		if h.e, err = GenerateKey(); err != nil {
+			return nil, err
+		}
+		msg = append(msg, h.e.Public...)
+		h.ss.mixHash(h.e.Public)
+		if err = h.mixDH(h.e.Private, h.re); err != nil {
+			return nil, err
+		}
+		if msg, err = h.writeStatic(msg); err != nil {
+			return nil, err
+		}
+		if err = h.mixDH(h.s.Private, h.re); err != nil {
+			return nil, err
+		}
+	case 2:
+		if msg, err = h.writeStatic(msg); err != nil {
+			return nil, err
+		}
+		if err = h.mixDH(h.s.Private, h.re); err != nil {
+			return nil, err
+		}
+	}
+	ciphertext, err := h.ss.encryptAndHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	msg = append(msg, ciphertext...)
+	if len(msg) > MaxMessageLen {
+		return nil, errors.New("noise: message too long")
+	}
+	h.next()
+	return msg, nil
+}
+
+func (h *HandshakeState) writeStatic(msg []byte) ([]byte, error) {
+	ciphertext, err := h.ss.encryptAndHash(h.s.Public)
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, ciphertext...), nil
+}
+
+//ReadMessage processes the next handshake message of the peer and returns
+//its payload
+func (h *HandshakeState) ReadMessage(msg []byte) ([]byte, error) {
+	if h.Complete() {
+		return nil, ErrHandshakeDone
+	}
+	if h.WriteTurn() {
+		return nil, ErrOutOfTurn
+	}
+	var err error
+	switch h.step {
+	case 0:
+		if msg, err = h.readEphemeral(msg); err != nil {
+			return nil, err
+		}
+	case 1:
+		if msg, err = h.readEphemeral(msg); err != nil {
+			return nil, err
+		}
+		if err = h.mixDH(h.e.Private, h.re); err != nil {
+			return nil, err
+		}
+		if msg, err = h.readStatic(msg); err != nil {
+			return nil, err
+		}
+		if err = h.mixDH(h.e.Private, h.rs); err != nil {
+			return nil, err
+		}
+	case 2:
+		if msg, err = h.readStatic(msg); err != nil {
+			return nil, err
+		}
+		if err = h.mixDH(h.e.Private, h.rs); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := h.ss.decryptAndHash(msg)
+	if err != nil {
+		return nil, err
+	}
+	h.next()
+	return payload, nil
+}
+
+func (h *HandshakeState) readEphemeral(msg []byte) ([]byte, error) {
+	if len(msg) < dhLen {
+		return nil, errShortMessage
+	}
+	h.re = append([]byte{}, msg[:dhLen]...)
+	h.ss.mixHash(h.re)
+	return msg[dhLen:], nil
+}
+
+func (h *HandshakeState) readStatic(msg []byte) ([]byte, error) {
+	n := dhLen
+	if h.ss.cs.hasKey() {
+		n += tagLen
+	}
+	if len(msg) < n {
+		return nil, errShortMessage
+	}
+	rs, err := h.ss.decryptAndHash(msg[:n])
+	if err != nil {
+		return nil, err
+	}
+	h.rs = rs
+	return msg[n:], nil
+}
+
+func (h *HandshakeState) next() {
+	h.step++
+	if h.Complete() {
+		c1, c2 := h.ss.split()
+		if h.initiator {
+			h.send, h.recv = c1, c2
+		} else {
+			h.send, h.recv = c2, c1
+		}
+		// Forget the ephemeral key
+		h.e = DHKey{}
+	}
+}
+
+//Complete reports whether all three messages were exchanged
+func (h *HandshakeState) Complete() bool {
+	return h.step == 3
+}
+
+//Ciphers returns the transport ciphers for the messages this side sends and
+//receives, nil until the handshake is complete
+func (h *HandshakeState) Ciphers() (send, recv *CipherState) {
+	return h.send, h.recv
+}
+
+//PeerStatic returns the static key of the peer, known from the second message
+//on
+func (h *HandshakeState) PeerStatic() []byte {
+	return h.rs
+}
+
+//HandshakeHash identifies the session. Signing it binds an identity to the
+//channel.
+func (h *HandshakeState) HandshakeHash() []byte {
+	return h.ss.h
+}
@@ -0,0 +1,120 @@
+package noise
+
+import (
+	"bytes"
+	"testing"
+)
+
+func handshake(t *testing.T) (initiator, responder *HandshakeState) {
+	si, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	initiator = NewHandshake(true, si, []byte("prologue"))
+	responder = NewHandshake(false, sr, []byte("prologue"))
+	for from, to := initiator, responder; !initiator.Complete(); from, to = to, from {
+		msg, err := from.WriteMessage([]byte("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := to.ReadMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(payload) != "payload" {
+			t.Fatalf("payload %q", payload)
+		}
+	}
+	if !responder.Complete() {
+		t.Fatal("responder not complete")
+	}
+	if !bytes.Equal(initiator.PeerStatic(), sr.Public) || !bytes.Equal(responder.PeerStatic(), si.Public) {
+		t.Error("static keys not exchanged")
+	}
+	if !bytes.Equal(initiator.HandshakeHash(), responder.HandshakeHash()) {
+		t.Error("handshake hashes differ")
+	}
+	return
+}
+
+func TestHandshake(t *testing.T) {
+	initiator, responder := handshake(t)
+	isend, irecv := initiator.Ciphers()
+	rsend, rrecv := responder.Ciphers()
+	for i, pair := range [][2]*CipherState{{isend, rrecv}, {rsend, irecv}} {
+		ciphertext, err := pair[0].Encrypt(nil, []byte("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(ciphertext, []byte("hello")) {
+			t.Errorf("direction %d not encrypted", i)
+		}
+		plaintext, err := pair[1].Decrypt(nil, ciphertext)
+		if err != nil || string(plaintext) != "hello" {
+			t.Errorf("direction %d: %q %v", i, plaintext, err)
+		}
+		// A replayed message carries a nonce already used
+		if _, err := pair[1].Decrypt(nil, ciphertext); err == nil {
+			t.Errorf("direction %d accepted a replay", i)
+		}
+	}
+
+	if _, err := initiator.WriteMessage(nil); err != ErrHandshakeDone {
+		t.Errorf("write after the handshake: %v", err)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	initiator, responder := handshake(t)
+	send, _ := initiator.Ciphers()
+	_, recv := responder.Ciphers()
+	before, _ := send.Encrypt(nil, []byte("a"))
+	recv.Decrypt(nil, before)
+
+	send.Rekey()
+	ciphertext, _ := send.Encrypt(nil, []byte("b"))
+	if _, err := recv.Decrypt(nil, append([]byte{}, ciphertext...)); err == nil {
+		t.Fatal("message under a new key opened with the old one")
+	}
+	// A failed decryption does not move the nonce on
+	recv.Rekey()
+	if plaintext, err := recv.Decrypt(nil, ciphertext); err != nil || string(plaintext) != "b" {
+		t.Errorf("after rekey: %q %v", plaintext, err)
+	}
+}
+
+func TestTamperedHandshake(t *testing.T) {
+	si, _ := GenerateKey()
+	sr, _ := GenerateKey()
+	initiator := NewHandshake(true, si, nil)
+	responder := NewHandshake(false, sr, nil)
+	msg, _ := initiator.WriteMessage(nil)
+	if _, err := responder.ReadMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+	msg, _ = responder.WriteMessage(nil)
+	// Flip a bit of the encrypted static key of the responder
+	msg[dhLen] ^= 1
+	if _, err := initiator.ReadMessage(msg); err == nil {
+		t.Error("tampered message accepted")
+	}
+	if _, err := responder.WriteMessage(nil); err != ErrOutOfTurn {
+		t.Errorf("write out of turn: %v", err)
+	}
+}
+
+func TestLowOrderKey(t *testing.T) {
+	sr, _ := GenerateKey()
+	responder := NewHandshake(false, sr, nil)
+	// An all-zero ephemeral key makes every DH with it all zero
+	if _, err := responder.ReadMessage(make([]byte, dhLen)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := responder.WriteMessage(nil); err != errLowOrderKey {
+		t.Errorf("DH with a low order key, got: %v, want: %v.", err, errLowOrderKey)
+	}
+}
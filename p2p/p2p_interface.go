@@ -25,14 +25,36 @@ const (
 	NoDiscover = iota // 0
 	//GossipDiscover means that p2p use gossip as a discover protocol
 	GossipDiscover
+	//KademliaDiscover means that p2p use a Kademlia DHT as a discover protocol
+	KademliaDiscover
 	swimPort = 7946
+	dhtPort  = 7947
+	// envPeersFile is the file the Kademlia DHT saves its peers to
+	envPeersFile = "PEERSFILE"
 )
 
+// QUICTransport, or'ed with the discover protocol in the netType of
+// CreateP2PNetwork, connects the peers over QUIC on the UDP port of the node
+// instead of TCP. Every message then travels on a stream of its own.
+const QUICTransport = 1 << 4
+
 // P2PMessage is a struct that includes message,sender and nonce
 type P2PMessage struct {
 	Msg          ptypes.DynamicAny
 	Sender       []byte
 	RequestNonce uint64
+	// Evidence proves to third parties that Sender sent Msg
+	Evidence *Evidence
+	// Topic is the topic Msg was published on, empty for a request that
+	// expects a reply
+	Topic string
+}
+
+// Reachability is the outcome of ConnectToAll: the members connected to, this
+// node included, and the ones that could not be reached
+type Reachability struct {
+	Reachable   [][]byte
+	Unreachable [][]byte
 }
 
 // P2PInterface represents a p2p network
@@ -43,35 +65,63 @@ type P2PInterface interface {
 	GetPort() string
 	Listen() error
 	Join(bootstrapIp []string) (num int, err error)
+	// SetPeerVerifier sets an extra check of authenticated peers. It must be
+	// called before Listen.
+	SetPeerVerifier(verify PeerVerifier)
 	ConnectTo(ip string, id []byte) ([]byte, error)
 	DisConnectTo(id []byte) error
 	Leave()
 	Request(id []byte, m proto.Message) (msg P2PMessage, err error)
 	Reply(id []byte, nonce uint64, m proto.Message) (err error)
+	// Broadcast sends m to the members of groupIds, which relay it to each
+	// other. Publish does the same for the members of a joined topic.
+	Broadcast(ctx context.Context, groupIds [][]byte, m proto.Message) error
+	JoinTopic(topic string, members [][]byte)
+	LeaveTopic(topic string)
+	Publish(ctx context.Context, topic string, m proto.Message) error
 	SubscribeEvent(chanBuffer int, messages ...interface{}) (outch chan P2PMessage, err error)
 	UnSubscribeEvent(messages ...interface{})
+	// Subscribe is SubscribeEvent with a filter and a policy for the
+	// messages a slow subscriber is not ready for
+	Subscribe(opts SubscribeOptions, messages ...interface{}) *Subscription
+	Unsubscribe(s *Subscription)
+	// Handle registers a handler that answers the requests of a message type
+	Handle(message interface{}, h Handler)
+	DispatchStats() []DispatchStats
 	NumOfMembers() int
 	MembersID() [][]byte
 	MembersIP() []net.IP
-	ConnectToAll(ctx context.Context, groupIds [][]byte, sessionID string) (out chan bool, errc chan error)
+	ConnectToAll(ctx context.Context, groupIds [][]byte, minReachable int, sessionID string) (out chan Reachability, errc chan error)
+	// Penalize lowers the score of a peer for an offense, banning it for a
+	// while once the score is used up
+	Penalize(id []byte, offense Offense)
+	PeerScores() []PeerScore
 	numOfClient() (int, int)
 }
 
-// CreateP2PNetwork creates a P2PInterface implementation , gets a public IP and generates a secret key
-func CreateP2PNetwork(id []byte, port string, netType int) (P2PInterface, error) {
+// CreateP2PNetwork creates a P2PInterface implementation , gets a public IP and generates a secret key.
+// The ID of the node is the address of signer, which proves it to peers by signing the handshake
+// and certifies the secret key that signs every package.
+func CreateP2PNetwork(signer Signer, port string, netType int) (P2PInterface, error) {
 	suite := suites.MustFind("bn256")
 	logger = log.New("module", "p2p")
 	p := &server{
-		suite:     suite,
-		messages:  make(chan P2PMessage, 100),
-		subscribe: make(chan subscription),
-		unscribe:  make(chan subscription),
-		port:      port,
+		suite:    suite,
+		messages: make(chan P2PMessage, 100),
+		port:     port,
+		peers:    newPeerTable(),
+		pubsub:   newPubsub(),
 	}
+	p.dispatcher = newDispatcher(p.Reply)
+	p.overQUIC = netType&QUICTransport != 0
+	netType &^= QUICTransport
 	p.ctx, p.cancel = context.WithCancel(context.Background())
-	p.secKey = suite.Scalar().Pick(suite.RandomStream())
-	p.pubKey = suite.Point().Mul(p.secKey, nil)
-	p.id = id
+	self, err := newIdentity(suite, signer)
+	if err != nil {
+		return nil, err
+	}
+	p.self = self
+	p.id = self.id
 
 	// If user specify a public ip from the env variable,use it as external IP.
 	if ip := net.ParseIP(os.Getenv("PUBLICIP")); ip != nil {
@@ -101,7 +151,11 @@ func CreateP2PNetwork(id []byte, port string, netType int) (P2PInterface, error)
 				return nil, err
 			}
 
-			if err := nat.SetMapping(p.ctx, natdev, "tcp", portInt, portInt, "DosClient"); err != nil {
+			protocol := "tcp"
+			if p.overQUIC {
+				protocol = "udp"
+			}
+			if err := nat.SetMapping(p.ctx, natdev, protocol, portInt, portInt, "DosClient"); err != nil {
 				fmt.Println(err)
 				return nil, err
 			}
@@ -112,6 +166,12 @@ func CreateP2PNetwork(id []byte, port string, netType int) (P2PInterface, error)
 					return nil, err
 				}
 			}
+			if netType == KademliaDiscover {
+				if err := nat.SetMapping(p.ctx, natdev, "udp", dhtPort, dhtPort, "DosDHT"); err != nil {
+					fmt.Println(err)
+					return nil, err
+				}
+			}
 			// If NAT port mapping success, then return NAT external IP.
 			p.addr = externalIp
 		} else {
@@ -128,6 +188,17 @@ func CreateP2PNetwork(id []byte, port string, netType int) (P2PInterface, error)
 			return nil, err
 		}
 		p.members = network
+	case KademliaDiscover:
+		path := os.Getenv(envPeersFile)
+		if path == "" {
+			path = "peers.json"
+		}
+		network, err := discover.NewKademlia(p.id, dhtPort, path)
+		if err != nil {
+			p.cancel()
+			return nil, err
+		}
+		p.members = network
 	default:
 	}
 	return p, nil
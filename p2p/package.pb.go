@@ -27,7 +27,10 @@ type Package struct {
 	// request_nonce is the request/response ID. Null if ID associated to a message is not a request/response.
 	RequestNonce uint64 `protobuf:"varint,4,opt,name=request_nonce,json=requestNonce,proto3" json:"request_nonce,omitempty"`
 	// reply_flag indicates this is a reply to a request
-	ReplyFlag            bool     `protobuf:"varint,5,opt,name=reply_flag,json=replyFlag,proto3" json:"reply_flag,omitempty"`
+	ReplyFlag bool `protobuf:"varint,5,opt,name=reply_flag,json=replyFlag,proto3" json:"reply_flag,omitempty"`
+	// scope is the hash of the topic, members and hop limit of a published
+	// message, so that relays cannot change them. Empty for other packages.
+	Scope                []byte   `protobuf:"bytes,6,opt,name=scope,proto3" json:"scope,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -92,11 +95,23 @@ func (m *Package) GetReplyFlag() bool {
 	return false
 }
 
+func (m *Package) GetScope() []byte {
+	if m != nil {
+		return m.Scope
+	}
+	return nil
+}
+
 type ID struct {
 	// public_key of the peer (we no longer use the public key as the peer ID, but use it to verify messages)
 	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
 	// id is the computed hash of the public key
-	Id                   []byte   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Id []byte `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// challenge is a fresh random the peer signs in its Auth
+	Challenge []byte `protobuf:"bytes,3,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	// certificate is the EIP-191 signature of the public key by the Ethereum
+	// key of id, which makes the package signatures attributable to id
+	Certificate          []byte   `protobuf:"bytes,4,opt,name=certificate,proto3" json:"certificate,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -140,6 +155,20 @@ func (m *ID) GetId() []byte {
 	return nil
 }
 
+func (m *ID) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *ID) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
 type Ping struct {
 	Count                uint64   `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -216,32 +245,177 @@ func (m *Pong) GetCount() uint64 {
 	return 0
 }
 
+// Auth proves that the sender of an ID holds the Ethereum key of its id
+type Auth struct {
+	// signature is the EIP-191 signature of the sender's public key followed
+	// by the challenge of the peer
+	Signature            []byte   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Auth) Reset()         { *m = Auth{} }
+func (m *Auth) String() string { return proto.CompactTextString(m) }
+func (*Auth) ProtoMessage()    {}
+func (*Auth) Descriptor() ([]byte, []int) {
+	return fileDescriptor_package_ff6a6a12a71b86f2, []int{4}
+}
+func (m *Auth) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Auth.Unmarshal(m, b)
+}
+func (m *Auth) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Auth.Marshal(b, m, deterministic)
+}
+func (dst *Auth) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Auth.Merge(dst, src)
+}
+func (m *Auth) XXX_Size() int {
+	return xxx_messageInfo_Auth.Size(m)
+}
+func (m *Auth) XXX_DiscardUnknown() {
+	xxx_messageInfo_Auth.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Auth proto.InternalMessageInfo
+
+func (m *Auth) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// Gossip carries a message published on a topic. It is relayed as its origin
+// signed it, so that every member can verify it.
+type Gossip struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// package is the Package signed by the origin with the message
+	Package []byte `protobuf:"bytes,2,opt,name=package,proto3" json:"package,omitempty"`
+	// public_key and certificate of the origin, as in its ID
+	PublicKey   []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Certificate []byte `protobuf:"bytes,4,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	// members to relay to, empty for the members of the topic
+	Members [][]byte `protobuf:"bytes,5,rep,name=members,proto3" json:"members,omitempty"`
+	// hops is the number of relays left
+	Hops uint32 `protobuf:"varint,6,opt,name=hops,proto3" json:"hops,omitempty"`
+	// hop_limit is the number of relays the origin allowed, part of the
+	// scope it signed
+	HopLimit             uint32   `protobuf:"varint,7,opt,name=hop_limit,json=hopLimit,proto3" json:"hop_limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Gossip) Reset()         { *m = Gossip{} }
+func (m *Gossip) String() string { return proto.CompactTextString(m) }
+func (*Gossip) ProtoMessage()    {}
+func (*Gossip) Descriptor() ([]byte, []int) {
+	return fileDescriptor_package_ff6a6a12a71b86f2, []int{5}
+}
+func (m *Gossip) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Gossip.Unmarshal(m, b)
+}
+func (m *Gossip) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Gossip.Marshal(b, m, deterministic)
+}
+func (dst *Gossip) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Gossip.Merge(dst, src)
+}
+func (m *Gossip) XXX_Size() int {
+	return xxx_messageInfo_Gossip.Size(m)
+}
+func (m *Gossip) XXX_DiscardUnknown() {
+	xxx_messageInfo_Gossip.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Gossip proto.InternalMessageInfo
+
+func (m *Gossip) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *Gossip) GetPackage() []byte {
+	if m != nil {
+		return m.Package
+	}
+	return nil
+}
+
+func (m *Gossip) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *Gossip) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
+func (m *Gossip) GetMembers() [][]byte {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *Gossip) GetHops() uint32 {
+	if m != nil {
+		return m.Hops
+	}
+	return 0
+}
+
+func (m *Gossip) GetHopLimit() uint32 {
+	if m != nil {
+		return m.HopLimit
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Package)(nil), "p2p.Package")
 	proto.RegisterType((*ID)(nil), "p2p.ID")
 	proto.RegisterType((*Ping)(nil), "p2p.Ping")
 	proto.RegisterType((*Pong)(nil), "p2p.Pong")
+	proto.RegisterType((*Auth)(nil), "p2p.Auth")
+	proto.RegisterType((*Gossip)(nil), "p2p.Gossip")
 }
 
 func init() { proto.RegisterFile("package.proto", fileDescriptor_package_ff6a6a12a71b86f2) }
 
 var fileDescriptor_package_ff6a6a12a71b86f2 = []byte{
-	// 265 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0xd1, 0x4a, 0xc3, 0x30,
-	0x18, 0x85, 0x49, 0xd7, 0xcd, 0xed, 0xb7, 0xf3, 0x22, 0x0c, 0x29, 0xb2, 0x41, 0xa9, 0x37, 0xbd,
-	0xea, 0x64, 0x7b, 0x02, 0x41, 0x04, 0x11, 0x64, 0xe4, 0x05, 0x4a, 0xda, 0xfe, 0x8d, 0x65, 0x25,
-	0x89, 0x6d, 0x72, 0x91, 0x07, 0xf3, 0xfd, 0xa4, 0x69, 0xd1, 0x2b, 0x2f, 0x02, 0x39, 0xdf, 0x39,
-	0x27, 0x1c, 0x02, 0x5b, 0xcd, 0xab, 0x2b, 0x17, 0x98, 0xeb, 0x5e, 0x19, 0x45, 0x17, 0xfa, 0xa4,
-	0x1f, 0x0e, 0xfe, 0x5e, 0xda, 0xe6, 0xa8, 0x8d, 0xd3, 0x38, 0x1c, 0xb9, 0x74, 0xe3, 0x99, 0x32,
-	0xe9, 0x37, 0x81, 0x9b, 0xcb, 0xd4, 0xa2, 0x4f, 0xb0, 0xe6, 0xd2, 0x99, 0xcf, 0x56, 0x8a, 0x98,
-	0x24, 0x24, 0xbb, 0x3d, 0xed, 0x72, 0xa1, 0x94, 0xe8, 0xe6, 0x07, 0x4b, 0xdb, 0xe4, 0xcf, 0xd2,
-	0xb1, 0xdf, 0x14, 0xdd, 0xc3, 0x66, 0x68, 0x85, 0xe4, 0xc6, 0xf6, 0x18, 0x07, 0x09, 0xc9, 0x22,
-	0xf6, 0x07, 0xe8, 0x3d, 0xac, 0x06, 0x94, 0x35, 0xf6, 0xf1, 0xc2, 0x5b, 0xb3, 0xa2, 0x8f, 0xb0,
-	0xed, 0xf1, 0xcb, 0xe2, 0x60, 0x0a, 0xa9, 0x64, 0x85, 0x71, 0x98, 0x90, 0x2c, 0x64, 0xd1, 0x0c,
-	0x3f, 0x46, 0x46, 0x0f, 0x00, 0x3d, 0xea, 0xce, 0x15, 0x4d, 0xc7, 0x45, 0xbc, 0x4c, 0x48, 0xb6,
-	0x66, 0x1b, 0x4f, 0x5e, 0x3b, 0x2e, 0xd2, 0x33, 0x04, 0x6f, 0x2f, 0x63, 0x48, 0xdb, 0xb2, 0x6b,
-	0xab, 0xe2, 0x8a, 0xce, 0x6f, 0x8e, 0xd8, 0x66, 0x22, 0xef, 0xe8, 0xe8, 0x1d, 0x04, 0x6d, 0x3d,
-	0xef, 0x0a, 0xda, 0x3a, 0xdd, 0x43, 0x78, 0x19, 0x67, 0xef, 0x60, 0x59, 0x29, 0x2b, 0x8d, 0x6f,
-	0x84, 0x6c, 0x12, 0xde, 0x55, 0xff, 0xb9, 0xe5, 0xca, 0x7f, 0xc1, 0xf9, 0x27, 0x00, 0x00, 0xff,
-	0xff, 0x1c, 0x9a, 0x85, 0xaf, 0x64, 0x01, 0x00, 0x00,
+	// 397 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0x5d, 0x8b, 0x13, 0x31,
+	0x14, 0x65, 0xda, 0xe9, 0xc7, 0xdc, 0x6d, 0x7d, 0x08, 0x45, 0x82, 0xee, 0xc2, 0x50, 0x7d, 0xe8,
+	0x53, 0x57, 0xd6, 0x5f, 0xb0, 0x20, 0x8a, 0x28, 0xb2, 0xe4, 0x0f, 0x94, 0x4c, 0x7a, 0x9b, 0x09,
+	0x9b, 0x26, 0x71, 0x92, 0x79, 0x98, 0xff, 0xe7, 0x9b, 0x7f, 0x4a, 0x92, 0x89, 0xae, 0x16, 0x64,
+	0x1f, 0x06, 0x72, 0xce, 0xbd, 0x39, 0x73, 0xcf, 0xb9, 0x81, 0xb5, 0xe3, 0xe2, 0x91, 0x4b, 0xdc,
+	0xbb, 0xce, 0x06, 0x4b, 0xa6, 0xee, 0xce, 0xbd, 0xba, 0x49, 0xe7, 0xa6, 0x3f, 0xdd, 0xba, 0x30,
+	0x38, 0xf4, 0xb7, 0xdc, 0x0c, 0xf1, 0x1b, 0x7b, 0xb6, 0x3f, 0x0b, 0x58, 0x3c, 0x8c, 0xb7, 0xc8,
+	0x3b, 0x58, 0x72, 0x33, 0x84, 0x56, 0x19, 0x49, 0x8b, 0xba, 0xd8, 0x5d, 0xdd, 0x6d, 0xf6, 0xd2,
+	0x5a, 0xa9, 0xb3, 0x60, 0xd3, 0x9f, 0xf6, 0xf7, 0x66, 0x60, 0x7f, 0xba, 0xc8, 0x35, 0x54, 0x5e,
+	0x49, 0xc3, 0x43, 0xdf, 0x21, 0x9d, 0xd4, 0xc5, 0x6e, 0xc5, 0x9e, 0x08, 0xf2, 0x12, 0xe6, 0x1e,
+	0xcd, 0x11, 0x3b, 0x3a, 0x4d, 0xa5, 0x8c, 0xc8, 0x1b, 0x58, 0x77, 0xf8, 0xbd, 0x47, 0x1f, 0x0e,
+	0xc6, 0x1a, 0x81, 0xb4, 0xac, 0x8b, 0x5d, 0xc9, 0x56, 0x99, 0xfc, 0x16, 0x39, 0x72, 0x03, 0xd0,
+	0xa1, 0xd3, 0xc3, 0xe1, 0xa4, 0xb9, 0xa4, 0xb3, 0xba, 0xd8, 0x2d, 0x59, 0x95, 0x98, 0x8f, 0x9a,
+	0x4b, 0xb2, 0x81, 0x99, 0x17, 0xd6, 0x21, 0x9d, 0x27, 0xe9, 0x11, 0x6c, 0x3d, 0x4c, 0x3e, 0x7f,
+	0x88, 0x57, 0x5d, 0xdf, 0x68, 0x25, 0x0e, 0x8f, 0x38, 0x24, 0x27, 0x2b, 0x56, 0x8d, 0xcc, 0x17,
+	0x1c, 0xc8, 0x0b, 0x98, 0xa8, 0x63, 0x9e, 0x76, 0xa2, 0x8e, 0xd1, 0x84, 0x68, 0xb9, 0xd6, 0x68,
+	0x24, 0xe6, 0x49, 0x9f, 0x08, 0x52, 0xc3, 0x95, 0xc0, 0x2e, 0xa8, 0x93, 0x12, 0x3c, 0x8c, 0xa3,
+	0xae, 0xd8, 0xdf, 0xd4, 0xf6, 0x1a, 0xca, 0x87, 0x18, 0xc6, 0x06, 0x66, 0xc2, 0xf6, 0x26, 0xa4,
+	0x3f, 0x96, 0x6c, 0x04, 0xa9, 0x6a, 0xff, 0x5b, 0x7d, 0x0b, 0xe5, 0x7d, 0x1f, 0xda, 0x7f, 0x83,
+	0x2c, 0x2e, 0x82, 0xdc, 0xfe, 0x28, 0x60, 0xfe, 0xc9, 0x7a, 0xaf, 0x5c, 0x94, 0x09, 0xd6, 0x29,
+	0x91, 0x9a, 0x2a, 0x36, 0x02, 0x42, 0x61, 0x91, 0x57, 0x9f, 0x7d, 0xfd, 0x86, 0x17, 0x59, 0x4c,
+	0x2f, 0xb3, 0x78, 0xd6, 0x5d, 0x94, 0x3e, 0xe3, 0xb9, 0xc1, 0xce, 0xd3, 0x59, 0x3d, 0x8d, 0xd2,
+	0x19, 0x12, 0x02, 0x65, 0x6b, 0x9d, 0x4f, 0x1b, 0x58, 0xb3, 0x74, 0x26, 0xaf, 0xa1, 0x6a, 0xad,
+	0x3b, 0x68, 0x75, 0x56, 0x81, 0x2e, 0x52, 0x61, 0xd9, 0x5a, 0xf7, 0x35, 0xe2, 0x66, 0x9e, 0x5e,
+	0xd1, 0xfb, 0x5f, 0x03, 0x00, 0xcc, 0xc9, 0x0d, 0xbe, 0xa7, 0x02, 0x00, 0x00,
 }
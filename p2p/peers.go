@@ -0,0 +1,224 @@
+package p2p
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Offense is a misbehaviour of a peer that lowers its score
+type Offense int
+
+const (
+	// OffenseInvalidSignature is a package that is not signed by its sender
+	OffenseInvalidSignature Offense = iota
+	// OffenseBadDeal is a DKG deal that fails to verify
+	OffenseBadDeal
+	// OffenseTimeout is a request left unanswered
+	OffenseTimeout
+	// OffenseFlood is a message over the rate limit of the peer
+	OffenseFlood
+)
+
+var offenseNames = map[Offense]string{
+	OffenseInvalidSignature: "invalidSignature",
+	OffenseBadDeal:          "badDeal",
+	OffenseTimeout:          "timeout",
+	OffenseFlood:            "flood",
+}
+
+func (o Offense) String() string {
+	return offenseNames[o]
+}
+
+// offensePenalty is what an offense costs
+var offensePenalty = map[Offense]float64{
+	OffenseInvalidSignature: 50,
+	OffenseBadDeal:          40,
+	OffenseTimeout:          10,
+	OffenseFlood:            5,
+}
+
+// A peer starts at maxScore and is banned for banDuration when its score falls
+// to zero. The score recovers by scoreRecovery points a minute, and a peer
+// comes back from a ban at half of maxScore. msgRate and msgBurst are the
+// messages per second a peer may send on average and at once, maxConnsPerIP
+// the most inbound connections from one IP.
+var (
+	maxScore      = 100.0
+	scoreRecovery = 1.0
+	banDuration   = 30 * time.Minute
+	msgRate       = 50.0
+	msgBurst      = 500.0
+	maxConnsPerIP = 4
+)
+
+// PeerScore is the standing of a peer
+type PeerScore struct {
+	ID          []byte
+	IP          string
+	Score       float64
+	Banned      bool
+	BannedUntil time.Time
+}
+
+type peerState struct {
+	ip          string
+	score       float64
+	updated     time.Time
+	tokens      float64
+	refilled    time.Time
+	bannedUntil time.Time
+}
+
+// peerTable keeps the score and the message allowance of every peer, and
+// counts the inbound connections of every IP
+type peerTable struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	peers     map[string]*peerState
+	conns     map[string]int
+	bannedIPs map[string]time.Time
+}
+
+func newPeerTable() *peerTable {
+	return &peerTable{
+		now:       time.Now,
+		peers:     make(map[string]*peerState),
+		conns:     make(map[string]int),
+		bannedIPs: make(map[string]time.Time),
+	}
+}
+
+// get returns the state of id with its score recovered up to now. The caller
+// holds the lock.
+func (t *peerTable) get(id []byte) *peerState {
+	now := t.now()
+	p := t.peers[string(id)]
+	if p == nil {
+		p = &peerState{score: maxScore, updated: now, tokens: msgBurst, refilled: now}
+		t.peers[string(id)] = p
+		return p
+	}
+	if !p.bannedUntil.IsZero() && !now.Before(p.bannedUntil) {
+		p.bannedUntil = time.Time{}
+		p.score = maxScore / 2
+		p.updated = now
+	}
+	if p.bannedUntil.IsZero() {
+		p.score += scoreRecovery * now.Sub(p.updated).Minutes()
+		if p.score > maxScore {
+			p.score = maxScore
+		}
+	}
+	p.updated = now
+	return p
+}
+
+// seen records that id connected from ip
+func (t *peerTable) seen(id []byte, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(id).ip = ip
+}
+
+// allowMessage takes a message from the allowance of id and reports whether
+// there was one left
+func (t *peerTable) allowMessage(id []byte) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.get(id)
+	now := t.now()
+	p.tokens += msgRate * now.Sub(p.refilled).Seconds()
+	if p.tokens > msgBurst {
+		p.tokens = msgBurst
+	}
+	p.refilled = now
+	if p.tokens < 1 {
+		return false
+	}
+	p.tokens--
+	return true
+}
+
+// penalize lowers the score of id for offense and reports whether it got id
+// banned
+func (t *peerTable) penalize(id []byte, offense Offense) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.get(id)
+	if !p.bannedUntil.IsZero() {
+		return false
+	}
+	if p.score -= offensePenalty[offense]; p.score > 0 {
+		return false
+	}
+	p.score = 0
+	p.bannedUntil = t.now().Add(banDuration)
+	if p.ip != "" && !isLoopback(p.ip) {
+		t.bannedIPs[p.ip] = p.bannedUntil
+	}
+	return true
+}
+
+func (t *peerTable) banned(id []byte) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.get(id).bannedUntil.IsZero()
+}
+
+// acceptConn reserves an inbound connection from ip and reports whether ip may
+// connect
+func (t *peerTable) acceptConn(ip string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until, ok := t.bannedIPs[ip]; ok {
+		if t.now().Before(until) {
+			return false
+		}
+		delete(t.bannedIPs, ip)
+	}
+	if !isLoopback(ip) && t.conns[ip] >= maxConnsPerIP {
+		return false
+	}
+	t.conns[ip]++
+	return true
+}
+
+// releaseConn frees a connection reserved by acceptConn
+func (t *peerTable) releaseConn(ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conns[ip]--; t.conns[ip] <= 0 {
+		delete(t.conns, ip)
+	}
+}
+
+// scores returns the standing of every known peer, lowest score first
+func (t *peerTable) scores() []PeerScore {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	list := make([]PeerScore, 0, len(t.peers))
+	for id := range t.peers {
+		p := t.get([]byte(id))
+		list = append(list, PeerScore{
+			ID:          []byte(id),
+			IP:          p.ip,
+			Score:       p.score,
+			Banned:      !p.bannedUntil.IsZero(),
+			BannedUntil: p.bannedUntil,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Score < list[j].Score
+	})
+	return list
+}
+
+// isLoopback reports whether ip is a loopback address. These are neither
+// limited nor banned, so that a network of nodes can run on one host.
+func isLoopback(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.IsLoopback()
+}
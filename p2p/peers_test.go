@@ -0,0 +1,94 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeerRateLimit(t *testing.T) {
+	tb := newPeerTable()
+	now := time.Now()
+	tb.now = func() time.Time { return now }
+	id := []byte("peer")
+	for i := 0; i < int(msgBurst); i++ {
+		if !tb.allowMessage(id) {
+			t.Fatalf("message %d of a burst refused", i)
+		}
+	}
+	if tb.allowMessage(id) {
+		t.Error("message over the burst allowed")
+	}
+	now = now.Add(time.Second)
+	for i := 0; i < int(msgRate); i++ {
+		if !tb.allowMessage(id) {
+			t.Fatalf("message %d of a second refused", i)
+		}
+	}
+	if tb.allowMessage(id) {
+		t.Error("message over the rate allowed")
+	}
+}
+
+func TestPeerBan(t *testing.T) {
+	tb := newPeerTable()
+	now := time.Now()
+	tb.now = func() time.Time { return now }
+	id := []byte("peer")
+	tb.seen(id, "10.0.0.1")
+	if !tb.acceptConn("10.0.0.1") {
+		t.Fatal("connection of a new peer refused")
+	}
+	tb.releaseConn("10.0.0.1")
+
+	if tb.penalize(id, OffenseInvalidSignature) || tb.banned(id) {
+		t.Fatal("peer banned for one offense")
+	}
+	if !tb.penalize(id, OffenseInvalidSignature) || !tb.banned(id) {
+		t.Fatal("peer not banned once its score is used up")
+	}
+	if tb.acceptConn("10.0.0.1") {
+		t.Error("connection from the IP of a banned peer accepted")
+	}
+	if scores := tb.scores(); len(scores) != 1 || !scores[0].Banned || scores[0].Score != 0 {
+		t.Errorf("scores %+v", scores)
+	}
+
+	now = now.Add(banDuration)
+	if tb.banned(id) {
+		t.Fatal("ban did not expire")
+	}
+	if !tb.acceptConn("10.0.0.1") {
+		t.Error("connection refused after the ban")
+	}
+	if score := tb.scores()[0].Score; score != maxScore/2 {
+		t.Errorf("score %f after a ban, want %f", score, maxScore/2)
+	}
+	now = now.Add(time.Hour)
+	if score := tb.scores()[0].Score; score != maxScore {
+		t.Errorf("score %f did not recover", score)
+	}
+}
+
+func TestPeerConnLimit(t *testing.T) {
+	tb := newPeerTable()
+	for i := 0; i < maxConnsPerIP; i++ {
+		if !tb.acceptConn("10.0.0.1") {
+			t.Fatalf("connection %d refused", i)
+		}
+	}
+	if tb.acceptConn("10.0.0.1") {
+		t.Error("connection over the limit accepted")
+	}
+	if !tb.acceptConn("10.0.0.2") {
+		t.Error("connection from another IP refused")
+	}
+	tb.releaseConn("10.0.0.1")
+	if !tb.acceptConn("10.0.0.1") {
+		t.Error("connection refused after one closed")
+	}
+	for i := 0; i <= maxConnsPerIP; i++ {
+		if !tb.acceptConn("127.0.0.1") {
+			t.Fatal("loopback connection refused")
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// gossipHops bounds how often a message is relayed, gossipFanout is the
+// number of members every relay forwards it to and seenSize the number of
+// message IDs remembered to drop the copies
+const (
+	gossipHops   = 2
+	gossipFanout = 3
+	seenSize     = 8192
+)
+
+// errGossipHops and errGossipScope are the errors of gossip that a relay
+// changed
+var (
+	errGossipHops  = errors.New("gossip hops exceed the hop limit")
+	errGossipScope = errors.New("gossip scope is not the one signed")
+)
+
+// pubsub keeps the members of the joined topics and the IDs of the messages
+// seen lately
+type pubsub struct {
+	mu     sync.Mutex
+	topics map[string][][]byte
+	seen   map[string]struct{}
+	ring   []string
+	next   int
+	seq    uint64
+}
+
+func newPubsub() *pubsub {
+	return &pubsub{
+		topics: make(map[string][][]byte),
+		seen:   make(map[string]struct{}),
+		ring:   make([]string, seenSize),
+		seq:    uint64(time.Now().UnixNano()),
+	}
+}
+
+func (ps *pubsub) join(topic string, members [][]byte) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.topics[topic] = members
+}
+
+func (ps *pubsub) leave(topic string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.topics, topic)
+}
+
+func (ps *pubsub) members(topic string) [][]byte {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.topics[topic]
+}
+
+// nextSeq numbers the messages published by this node, so that publishing
+// the same message twice gives two message IDs
+func (ps *pubsub) nextSeq() uint64 {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.seq++
+	return ps.seq
+}
+
+func (ps *pubsub) isSeen(id []byte) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	_, ok := ps.seen[string(id)]
+	return ok
+}
+
+// markSeen records id, forgetting the oldest ID once seenSize are known, and
+// reports whether id is new
+func (ps *pubsub) markSeen(id []byte) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if _, ok := ps.seen[string(id)]; ok {
+		return false
+	}
+	if old := ps.ring[ps.next]; old != "" {
+		delete(ps.seen, old)
+	}
+	ps.ring[ps.next] = string(id)
+	ps.next = (ps.next + 1) % len(ps.ring)
+	ps.seen[string(id)] = struct{}{}
+	return true
+}
+
+// messageID identifies a gossip message by its signed package
+func messageID(pa []byte) []byte {
+	return crypto.Keccak256(pa)
+}
+
+// gossipScope hashes the topic, members and hop limit of a gossip message,
+// each member prefixed with its length
+func gossipScope(topic string, members [][]byte, hopLimit uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(topic)
+	buf.WriteByte(0)
+	var size [4]byte
+	for _, id := range members {
+		binary.BigEndian.PutUint32(size[:], uint32(len(id)))
+		buf.Write(size[:])
+		buf.Write(id)
+	}
+	binary.BigEndian.PutUint32(size[:], hopLimit)
+	buf.Write(size[:])
+	return crypto.Keccak256(buf.Bytes())
+}
+
+// groupTopic names the topic of a broadcast to ids
+func groupTopic(ids [][]byte) string {
+	return fmt.Sprintf("group-%x", crypto.Keccak256(ids...)[:8])
+}
+
+func containsID(ids [][]byte, id []byte) bool {
+	for _, x := range ids {
+		if bytes.Equal(x, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinTopic sets the members the messages of topic are published and relayed
+// to
+func (n *server) JoinTopic(topic string, members [][]byte) {
+	n.pubsub.join(topic, members)
+}
+
+// LeaveTopic forgets the members of topic
+func (n *server) LeaveTopic(topic string) {
+	n.pubsub.leave(topic)
+}
+
+// Publish sends m to the members of a joined topic, which relay it to each
+// other. It returns once every member acknowledged m or ctx is done.
+func (n *server) Publish(ctx context.Context, topic string, m proto.Message) error {
+	members := n.pubsub.members(topic)
+	if members == nil {
+		return fmt.Errorf("p2p topic %s is not joined", topic)
+	}
+	return n.publish(ctx, topic, members, false, m)
+}
+
+// Broadcast sends m to the members of groupIds. The members relay it to each
+// other, so that it also reaches the ones this node cannot connect to. It
+// returns once every member acknowledged m or ctx is done.
+func (n *server) Broadcast(ctx context.Context, groupIds [][]byte, m proto.Message) error {
+	return n.publish(ctx, groupTopic(groupIds), groupIds, true, m)
+}
+
+// publish signs m and sends it to every member but this node, retrying each
+// one until it acknowledges. The members travel with the message if embed is
+// set, for the members that did not join topic.
+func (n *server) publish(ctx context.Context, topic string, members [][]byte, embed bool, m proto.Message) error {
+	var embedded [][]byte
+	if embed {
+		embedded = members
+	}
+	g, err := n.newGossip(topic, embedded, m)
+	if err != nil {
+		return err
+	}
+	n.pubsub.markSeen(messageID(g.Package))
+
+	var wg sync.WaitGroup
+	var reached, total int32
+	for _, id := range members {
+		if bytes.Equal(id, n.id) {
+			continue
+		}
+		total++
+		wg.Add(1)
+		go func(id []byte) {
+			defer wg.Done()
+			if n.sendGossip(ctx, id, g) == nil {
+				atomic.AddInt32(&reached, 1)
+			}
+		}(id)
+	}
+	wg.Wait()
+	if reached < total {
+		return fmt.Errorf("p2p %s reached %d of %d members", topic, reached, total)
+	}
+	return nil
+}
+
+// newGossip signs m in a package scoped to topic, the embedded members and
+// gossipHops relays
+func (n *server) newGossip(topic string, embedded [][]byte, m proto.Message) (*Gossip, error) {
+	anything, err := ptypes.MarshalAny(m)
+	if err != nil {
+		return nil, err
+	}
+	pa := &Package{Sender: n.id, Anything: anything, RequestNonce: n.pubsub.nextSeq(), Scope: gossipScope(topic, embedded, gossipHops)}
+	if err := signPackage(n.suite, n.self.secKey, pa); err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(pa)
+	if err != nil {
+		return nil, err
+	}
+	return &Gossip{Topic: topic, Package: data, PublicKey: n.self.pubKeyBytes, Certificate: n.self.certificate, Members: embedded, Hops: gossipHops, HopLimit: gossipHops}, nil
+}
+
+// verifyGossip checks that the origin of g signed its package together with
+// its topic, members and hop limit, and that it has relays left within that
+// limit
+func verifyGossip(g *Gossip) (*Evidence, *Package, error) {
+	if g.GetHopLimit() > gossipHops || g.GetHops() > g.GetHopLimit() {
+		return nil, nil, errGossipHops
+	}
+	evidence := &Evidence{Package: g.GetPackage(), PublicKey: g.GetPublicKey(), Certificate: g.GetCertificate()}
+	pa, err := evidence.Verify()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(pa.GetScope(), gossipScope(g.GetTopic(), g.GetMembers(), g.GetHopLimit())) {
+		return nil, nil, errGossipScope
+	}
+	return evidence, pa, nil
+}
+
+// sendGossip sends g to id in attempts of connectTimeout, doubling the wait
+// between attempts, until id acknowledges it or ctx is done
+func (n *server) sendGossip(ctx context.Context, id []byte, g *Gossip) error {
+	backoff := connectBackoff
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		msg, err := n.request(attemptCtx, id, g)
+		cancel()
+		if err == nil && msg.Msg.Message != nil {
+			return nil
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if backoff < connectTimeout {
+			backoff *= 2
+		}
+	}
+}
+
+// receiveGossip acknowledges g and checks that its origin signed it with its
+// topic, members and hop limit. The first time the message is seen it is
+// relayed and handed on to the subscribers, as sent by its origin.
+func (n *server) receiveGossip(c *client, m P2PMessage, g *Gossip) {
+	n.Reply(m.Sender, m.RequestNonce, &Gossip{})
+	id := messageID(g.GetPackage())
+	if n.pubsub.isSeen(id) {
+		return
+	}
+	evidence, pa, err := verifyGossip(g)
+	if err != nil {
+		n.Penalize(m.Sender, OffenseInvalidSignature)
+		return
+	}
+	origin := pa.GetSender()
+	members := g.GetMembers()
+	if len(members) == 0 {
+		members = n.pubsub.members(g.GetTopic())
+	}
+	if len(members) > 0 && !containsID(members, origin) {
+		return
+	}
+	if n.verifyPeer != nil && n.verifyPeer(origin) != nil {
+		return
+	}
+	var ptr ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(pa.GetAnything(), &ptr); err != nil {
+		return
+	}
+	if !n.pubsub.markSeen(id) {
+		return
+	}
+	if g.GetHops() > 0 {
+		n.relay(g, members, m.Sender, origin)
+	}
+	select {
+	case n.messages <- P2PMessage{Msg: ptr, Sender: origin, Evidence: evidence, Topic: g.GetTopic()}:
+	case <-c.ctx.Done():
+	}
+}
+
+// relay forwards g with one hop less to gossipFanout random members other
+// than the peer it came from and its origin
+func (n *server) relay(g *Gossip, members [][]byte, from, origin []byte) {
+	var targets [][]byte
+	for _, id := range members {
+		if !bytes.Equal(id, n.id) && !bytes.Equal(id, from) && !bytes.Equal(id, origin) {
+			targets = append(targets, id)
+		}
+	}
+	rand.Shuffle(len(targets), func(i, j int) {
+		targets[i], targets[j] = targets[j], targets[i]
+	})
+	if len(targets) > gossipFanout {
+		targets = targets[:gossipFanout]
+	}
+	fwd := &Gossip{
+		Topic:       g.GetTopic(),
+		Package:     g.GetPackage(),
+		PublicKey:   g.GetPublicKey(),
+		Certificate: g.GetCertificate(),
+		Members:     g.GetMembers(),
+		Hops:        g.GetHops() - 1,
+		HopLimit:    g.GetHopLimit(),
+	}
+	for _, id := range targets {
+		go func(id []byte) {
+			ctx, cancel := context.WithTimeout(n.ctx, connectTimeout)
+			defer cancel()
+			n.request(ctx, id, fwd)
+		}(id)
+	}
+}
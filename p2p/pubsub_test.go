@@ -0,0 +1,115 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/suites"
+)
+
+func TestSeen(t *testing.T) {
+	ps := newPubsub()
+	if !ps.markSeen([]byte("first")) || ps.markSeen([]byte("first")) {
+		t.Fatal("a message ID is new only once")
+	}
+	for i := 0; i < seenSize; i++ {
+		ps.markSeen([]byte{byte(i), byte(i >> 8)})
+	}
+	if ps.isSeen([]byte("first")) {
+		t.Error("the oldest message ID was not forgotten")
+	}
+	if len(ps.seen) != seenSize {
+		t.Errorf("%d message IDs remembered, want %d", len(ps.seen), seenSize)
+	}
+}
+
+func TestGossipScope(t *testing.T) {
+	suite := suites.MustFind("bn256")
+	self, _ := newIdentity(suite, newSigner())
+	n := &server{suite: suite, self: self, id: self.id, pubsub: newPubsub()}
+	members := [][]byte{self.id, []byte("b"), []byte("c")}
+	g, err := n.newGossip("topic", members, &Ping{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := verifyGossip(g); err != nil {
+		t.Fatal(err)
+	}
+	g.Hops--
+	if _, _, err := verifyGossip(g); err != nil {
+		t.Errorf("relayed gossip refused: %v", err)
+	}
+
+	tampered := []func(g *Gossip){
+		func(g *Gossip) { g.Topic = "other" },
+		func(g *Gossip) { g.Members = append(g.Members, []byte("d")) },
+		func(g *Gossip) { g.Members = nil },
+		func(g *Gossip) { g.Hops = gossipHops + 1 },
+		func(g *Gossip) { g.Hops, g.HopLimit = gossipHops+5, gossipHops+5 },
+		func(g *Gossip) { g.HopLimit = 1 },
+	}
+	for i, tamper := range tampered {
+		relayed := *g
+		tamper(&relayed)
+		if _, _, err := verifyGossip(&relayed); err == nil {
+			t.Errorf("tampered gossip %d accepted", i)
+		}
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	connectTimeout, connectBackoff = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { connectTimeout, connectBackoff = 30*time.Second, time.Second }()
+	signer := newSigner()
+	os.Setenv("PUBLICIP", "127.0.0.1")
+	log.Init(signer.Address().Bytes())
+
+	connect := func(from, to P2PInterface) {
+		port := from.GetPort()
+		from.SetPort(to.GetPort())
+		if _, err := from.ConnectTo("127.0.0.1", nil); err != nil {
+			t.Fatalf("ConnectTo ,Error %s", err)
+		}
+		from.SetPort(port)
+	}
+	a, _ := CreateP2PNetwork(signer, "9921", NoDiscover)
+	a.Listen()
+	b, _ := CreateP2PNetwork(newSigner(), "9922", NoDiscover)
+	b.Listen()
+	c, _ := CreateP2PNetwork(newSigner(), "9923", NoDiscover)
+	c.Listen()
+	// a reaches c only through b
+	connect(a, b)
+	connect(b, c)
+	events, _ := c.SubscribeEvent(10, Ping{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.Broadcast(ctx, [][]byte{a.GetID(), b.GetID(), c.GetID()}, &Ping{Count: 7}); err == nil {
+		t.Error("Broadcast reached a member it cannot connect to")
+	}
+	select {
+	case msg := <-events:
+		ping, ok := msg.Msg.Message.(*Ping)
+		if !ok || ping.Count != 7 {
+			t.Fatalf("relayed message %v", msg.Msg.Message)
+		}
+		if !bytes.Equal(msg.Sender, a.GetID()) || msg.Topic == "" {
+			t.Errorf("relayed message from %x on topic %q", msg.Sender, msg.Topic)
+		}
+		if _, err := msg.Evidence.Verify(); err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast was not relayed")
+	}
+	select {
+	case <-events:
+		t.Error("message delivered twice")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
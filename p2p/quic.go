@@ -0,0 +1,336 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+)
+
+// quicStreamLimit bounds the streams of packages in flight on a connection,
+// either way. streamTimeout bounds the transfer of one package.
+const (
+	quicStreamLimit = 256
+	streamTimeout   = 30 * time.Second
+)
+
+var (
+	errQUICClosed      = errors.New("quic transport is closed")
+	errNoStream        = errors.New("quic connection opened no stream")
+	errNoCertificate   = errors.New("quic peer presented no certificate")
+	errStreamNotClosed = errors.New("quic stream is not closed by the peer")
+)
+
+// quicConfig keeps idle connections open like the TCP keepalives do. gQUIC 44
+// cannot share a UDP socket between connections, so it is left out.
+var quicConfig = &quic.Config{
+	Versions:           []quic.VersionNumber{quic.VersionGQUIC43, quic.VersionGQUIC39},
+	HandshakeTimeout:   10 * time.Second,
+	IdleTimeout:        time.Minute,
+	KeepAlive:          true,
+	MaxIncomingStreams: quicStreamLimit + 1,
+}
+
+// quicConn is the first stream of a QUIC connection, which carries the
+// handshake. The packages then travel on a stream each, so that a large or
+// slow one does not hold up the others.
+type quicConn struct {
+	quic.Stream
+	sess    quic.Session
+	binding []byte
+}
+
+func (c *quicConn) LocalAddr() net.Addr {
+	return c.sess.LocalAddr()
+}
+
+func (c *quicConn) RemoteAddr() net.Addr {
+	return c.sess.RemoteAddr()
+}
+
+// Close closes the whole connection, not only the first stream
+func (c *quicConn) Close() error {
+	return c.sess.Close()
+}
+
+// certBinding returns the hash of the certificate of the listening peer.
+// The QUIC keys are signed with that certificate, so peers include it in the
+// Noise prologue for the signed handshake to prove who is at the other end of
+// this very connection.
+func certBinding(cert []byte) []byte {
+	hash := sha256.Sum256(cert)
+	return hash[:]
+}
+
+// quicTransport listens and dials on one UDP socket, so that a peer behind a
+// NAT is reached back through the mapping its outgoing packets opened. It is
+// the net.Listener of the node, Accept returning the first stream of the
+// connections once the peer opened it.
+type quicTransport struct {
+	udpConn   *net.UDPConn
+	listener  quic.Listener
+	tlsConf   *tls.Config
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func listenQUIC(port string) (*quicTransport, error) {
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort("", port))
+	if err != nil {
+		return nil, err
+	}
+	udpConn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf, err := newTLSConfig()
+	if err != nil {
+		udpConn.Close()
+		return nil, err
+	}
+	t := &quicTransport{
+		udpConn: udpConn,
+		tlsConf: tlsConf,
+		conns:   make(chan net.Conn),
+		done:    make(chan struct{}),
+	}
+	if t.listener, err = quic.Listen(udpConn, tlsConf, quicConfig); err != nil {
+		udpConn.Close()
+		return nil, err
+	}
+	go t.acceptConns()
+	return t, nil
+}
+
+// newTLSConfig makes a TLS configuration with a fresh self-signed
+// certificate. Peers skip the verification of certificates, they
+// authenticate each other by the handshake that follows.
+func newTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates:       []tls.Certificate{{Certificate: [][]byte{cert}, PrivateKey: key}},
+		InsecureSkipVerify: true,
+	}, nil
+}
+
+// acceptConns accepts the connections and waits for their first stream
+// apart, so that a peer that never opens it holds up no one else
+func (t *quicTransport) acceptConns() {
+	binding := certBinding(t.tlsConf.Certificates[0].Certificate[0])
+	for {
+		sess, err := t.listener.Accept()
+		if err != nil {
+			select {
+			case <-t.done:
+			default:
+				logger.Error(err)
+			}
+			t.Close()
+			return
+		}
+		go func(sess quic.Session) {
+			timer := time.AfterFunc(handshakeTimeout, func() {
+				sess.CloseWithError(0, errNoStream)
+			})
+			stream, err := sess.AcceptStream()
+			if !timer.Stop() || err != nil {
+				sess.Close()
+				return
+			}
+			select {
+			case t.conns <- &quicConn{Stream: stream, sess: sess, binding: binding}:
+			case <-t.done:
+				sess.Close()
+			}
+		}(sess)
+	}
+}
+
+// Accept returns the first stream of the next connection
+func (t *quicTransport) Accept() (net.Conn, error) {
+	select {
+	case conn := <-t.conns:
+		return conn, nil
+	case <-t.done:
+		return nil, errQUICClosed
+	}
+}
+
+// Close stops listening and closes the connections and the UDP socket
+func (t *quicTransport) Close() (err error) {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.listener.Close()
+		err = t.udpConn.Close()
+	})
+	return
+}
+
+func (t *quicTransport) Addr() net.Addr {
+	return t.listener.Addr()
+}
+
+// dial connects to addr from the UDP socket of the listener and opens the
+// first stream
+func (t *quicTransport) dial(ctx context.Context, addr string) (net.Conn, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := quic.DialContext(ctx, t.udpConn, udpAddr, addr, t.tlsConf, quicConfig)
+	if err != nil {
+		return nil, err
+	}
+	certs := sess.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		sess.CloseWithError(0, errNoCertificate)
+		return nil, errNoCertificate
+	}
+	stream, err := sess.OpenStreamSync()
+	if err != nil {
+		sess.Close()
+		return nil, err
+	}
+	return &quicConn{Stream: stream, sess: sess, binding: certBinding(certs[0].Raw)}, nil
+}
+
+// readStreams reads the package of every stream the peer opens
+func readStreams(ctx context.Context, sess quic.Session) (chan []byte, chan error) {
+	out := make(chan []byte, 10)
+	errc := make(chan error)
+	go func() {
+		var wg sync.WaitGroup
+		defer close(errc)
+		defer func() {
+			wg.Wait()
+			close(out)
+		}()
+		for {
+			stream, err := sess.AcceptStream()
+			if err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			wg.Add(1)
+			go func(stream quic.Stream) {
+				defer wg.Done()
+				stream.SetDeadline(time.Now().Add(streamTimeout))
+				buffer, err := readFrame(stream, msgSizeLimit)
+				if err != nil {
+					stream.CancelRead(0)
+					stream.CancelWrite(0)
+					return
+				}
+				if err = closeStream(stream); err != nil {
+					return
+				}
+				select {
+				case out <- buffer:
+				case <-ctx.Done():
+				}
+			}(stream)
+		}
+	}()
+	return out, errc
+}
+
+// sendStreams sends every package on a stream of its own, quicStreamLimit at
+// once at most
+func sendStreams(ctx context.Context, sess quic.Session, framec chan frame) chan error {
+	errc := make(chan error)
+	go func() {
+		var wg sync.WaitGroup
+		defer close(errc)
+		defer wg.Wait()
+		sem := make(chan struct{}, quicStreamLimit)
+		for {
+			select {
+			case f, ok := <-framec:
+				if !ok {
+					return
+				}
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func(f frame) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					if err := sendStream(sess, f); err != nil {
+						select {
+						case errc <- err:
+						case <-ctx.Done():
+						}
+					}
+				}(f)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return errc
+}
+
+func sendStream(sess quic.Session, f frame) error {
+	stream, err := sess.OpenStreamSync()
+	if err != nil {
+		return err
+	}
+	stream.SetDeadline(time.Now().Add(streamTimeout))
+	if err = writeFrame(stream, f.bytes); err != nil {
+		stream.CancelWrite(0)
+		stream.CancelRead(0)
+		return err
+	}
+	f.written()
+	return closeStream(stream)
+}
+
+// closeStream closes the sending side of a stream and waits for the peer to
+// close its own. gQUIC has no unidirectional streams, and a stream is
+// released only once both sides are closed.
+func closeStream(stream quic.Stream) error {
+	if err := stream.Close(); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(stream, make([]byte, 1)); err != io.EOF {
+		stream.CancelRead(0)
+		return errStreamNotClosed
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/DOSNetwork/core/log"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestQUIC(t *testing.T) {
+	signer := newSigner()
+	os.Setenv("PUBLICIP", "127.0.0.1")
+	log.Init(signer.Address().Bytes())
+
+	a, _ := CreateP2PNetwork(signer, "9931", NoDiscover|QUICTransport)
+	if err := a.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Leave()
+	b, _ := CreateP2PNetwork(newSigner(), "9932", NoDiscover|QUICTransport)
+	if err := b.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Leave()
+	b.Handle(Ping{}, func(msg P2PMessage) (proto.Message, error) {
+		return &Pong{Count: msg.Msg.Message.(*Ping).Count + 10}, nil
+	})
+
+	a.SetPort(b.GetPort())
+	id, err := a.ConnectTo("127.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("ConnectTo ,Error %s", err)
+	}
+	a.SetPort("9931")
+
+	// The requests share the connection, each one on a stream of its own
+	var wg sync.WaitGroup
+	for i := uint64(0); i < 20; i++ {
+		wg.Add(1)
+		go func(count uint64) {
+			defer wg.Done()
+			reply, err := a.Request(id, &Ping{Count: count})
+			if err != nil {
+				t.Errorf("Request ,Error %s", err)
+				return
+			}
+			if pong, ok := reply.Msg.Message.(*Pong); !ok || pong.Count != count+10 {
+				t.Errorf("reply %v to Ping %d", reply.Msg.Message, count)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if iNum, _ := b.numOfClient(); iNum != 1 {
+		t.Errorf("%d incoming connections, want 1", iNum)
+	}
+}
@@ -6,27 +6,48 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"reflect"
-
+	"sync"
 	"time"
 
-	"github.com/dedis/kyber"
-
 	"github.com/golang/protobuf/proto"
 
 	"github.com/DOSNetwork/core/p2p/discover"
 	"github.com/DOSNetwork/core/suites"
 )
 
+// connectAttempts, connectTimeout and connectBackoff bound the retries of
+// ConnectToAll
+var (
+	connectAttempts = 3
+	connectTimeout  = 30 * time.Second
+	connectBackoff  = time.Second
+)
+
+// replyTimeout is how long a peer has to answer a request written to its
+// connection before it is penalized
+var replyTimeout = 20 * time.Second
+
+var errPeerBanned = errors.New("p2p peer is banned")
+
 type server struct {
-	id     []byte
-	suite  suites.Suite
-	secKey kyber.Scalar
-	pubKey kyber.Point
+	id    []byte
+	suite suites.Suite
+	// self holds the keys of the handshakes and packages, verifyPeer
+	// authorizes the peers
+	self       *identity
+	verifyPeer PeerVerifier
+	// peers scores the peers, limits their messages and connections and
+	// bans the ones that misbehave
+	peers *peerTable
+	// pubsub keeps the topics and the gossip seen
+	pubsub *pubsub
 
 	addr     net.IP
 	port     string
 	listener net.Listener
+	// quic is the transport of the node if overQUIC is set, TCP otherwise
+	overQUIC bool
+	quic     *quicTransport
 
 	//client lookup
 	members  discover.Membership
@@ -35,15 +56,16 @@ type server struct {
 
 	addIncomingC    chan *client
 	removeIncomingC chan []byte
+	dropIncomingC   chan []byte
 	addCallingC     chan *client
 	removeCallingC  chan []byte
+	dialFailedC     chan request
 	incomingNum     int
 	callingNum      int
 
 	//Event
-	messages  chan P2PMessage
-	subscribe chan subscription
-	unscribe  chan subscription
+	messages   chan P2PMessage
+	dispatcher *dispatcher
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -54,7 +76,9 @@ type request struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	addr   net.IP
-	id     []byte
+	// port is the port of the record of id, empty for the port of the node
+	port string
+	id   []byte
 	//client signs and packs msg into Package
 	msg proto.Message
 	p   *Package
@@ -62,17 +86,18 @@ type request struct {
 	nonce uint64
 	reply chan interface{}
 	errc  chan error
-}
-
-type subscription struct {
-	eventType string
-	message   chan P2PMessage
+	// sent is closed once the request is written to the connection
+	sent chan struct{}
 }
 
 func (n *server) Join(bootstrapIP []string) (num int, err error) {
 	return n.members.Join(bootstrapIP)
 }
 
+func (n *server) SetPeerVerifier(verify PeerVerifier) {
+	n.verifyPeer = verify
+}
+
 func (n *server) NumOfMembers() int {
 	return n.members.NumOfPeers()
 }
@@ -85,43 +110,78 @@ func (n *server) MembersID() [][]byte {
 	return n.members.MembersID()
 }
 
-func (n *server) ConnectToAll(ctx context.Context, groupIds [][]byte, sessionID string) (out chan bool, errc chan error) {
-	out = make(chan bool)
+// ConnectToAll dials the members of groupIds concurrently, retrying each one
+// with backoff. Once every dial succeeded or gave up it sends the members
+// reached on out, or an error on errc if fewer than minReachable were.
+func (n *server) ConnectToAll(ctx context.Context, groupIds [][]byte, minReachable int, sessionID string) (out chan Reachability, errc chan error) {
+	out = make(chan Reachability)
 	errc = make(chan error)
 	go func() {
 		defer close(out)
 		defer close(errc)
-		//defer logger.TimeTrack(time.Now(), "ConnectToAll", map[string]interface{}{"GroupID": sessionID})
-		//var wg sync.WaitGroup
-		//wg.Add(len(groupIds) - 1)
-		for i := 0; i < len(groupIds); i++ {
-			if !bytes.Equal(n.GetID(), groupIds[i]) {
-				select {
-				case <-ctx.Done():
-				default:
-					_, err := n.ConnectTo("", groupIds[i])
-					if err != nil {
-						fmt.Println("ConnectTo ", err)
-						logger.TimeTrack(time.Now(), "ConnectToAllFail", map[string]interface{}{"GroupID": sessionID, "ConnectToID": fmt.Sprintf("%x", groupIds[i])})
-						select {
-						case errc <- err:
-						case <-ctx.Done():
-						}
-						return
-					}
-					logger.TimeTrack(time.Now(), "ConnectToAllSucc", map[string]interface{}{"GroupID": sessionID, "ConnectToID": fmt.Sprintf("%x", groupIds[i])})
+		reached := make([]bool, len(groupIds))
+		var wg sync.WaitGroup
+		for i := range groupIds {
+			if bytes.Equal(n.GetID(), groupIds[i]) {
+				reached[i] = true
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := n.connectWithRetry(ctx, groupIds[i]); err != nil {
+					logger.TimeTrack(time.Now(), "ConnectToAllFail", map[string]interface{}{"GroupID": sessionID, "ConnectToID": fmt.Sprintf("%x", groupIds[i]), "Error": err.Error()})
+					return
 				}
+				reached[i] = true
+				logger.TimeTrack(time.Now(), "ConnectToAllSucc", map[string]interface{}{"GroupID": sessionID, "ConnectToID": fmt.Sprintf("%x", groupIds[i])})
+			}(i)
+		}
+		wg.Wait()
+
+		var r Reachability
+		for i, ok := range reached {
+			if ok {
+				r.Reachable = append(r.Reachable, groupIds[i])
+			} else {
+				r.Unreachable = append(r.Unreachable, groupIds[i])
+			}
+		}
+		if len(r.Reachable) < minReachable {
+			select {
+			case errc <- fmt.Errorf("reached %d of %d members, %d needed", len(r.Reachable), len(groupIds), minReachable):
+			case <-ctx.Done():
 			}
+			return
 		}
-		//wg.Wait()
 		select {
-		case out <- true:
+		case out <- r:
 		case <-ctx.Done():
 		}
 	}()
 	return
 }
 
+// connectWithRetry makes up to connectAttempts attempts of connectTimeout
+// each to connect to id, doubling the wait between attempts
+func (n *server) connectWithRetry(ctx context.Context, id []byte) (err error) {
+	backoff := connectBackoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		_, err = n.connectTo(attemptCtx, "", id)
+		cancel()
+		if err == nil || attempt == connectAttempts {
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
 func (n *server) numOfClient() (iNum, cNum int) {
 	return n.incomingNum, n.callingNum
 }
@@ -152,11 +212,22 @@ func (n *server) Listen() (err error) {
 	go n.messageDispatch(context.Background())
 
 	p := fmt.Sprintf(":%s", n.port)
-	if n.listener, err = net.Listen("tcp", p); err != nil {
+	if n.overQUIC {
+		if n.quic, err = listenQUIC(n.port); err != nil {
+			logger.Error(err)
+			return
+		}
+		n.listener = n.quic
+	} else if n.listener, err = net.Listen("tcp", p); err != nil {
 		logger.Error(err)
 		return
 	}
 	fmt.Println("Listen to ", n.addr, " ", n.port)
+	if n.members != nil {
+		if err := n.advertise(); err != nil {
+			logger.Error(err)
+		}
+	}
 
 	go func() {
 		for {
@@ -168,29 +239,49 @@ func (n *server) Listen() (err error) {
 			}
 			start := time.Now()
 			//fmt.Println("new conn ", conn.RemoteAddr().String())
+			ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			if !n.peers.acceptConn(ip) {
+				logger.Event("p2pConnRefused", map[string]interface{}{"RemoteIP": ip})
+				conn.Close()
+				continue
+			}
 			go func(conn net.Conn, start time.Time) {
-				c, err := newClient(n.suite, n.secKey, n.pubKey, n.id, conn, true)
+				c, err := newClient(n.suite, n.self, n.verifyPeer, conn, true)
 				if err != nil {
 					//fmt.Println("listen to client err", err)
 					logger.Error(err)
+					n.peers.releaseConn(ip)
 					return
 				}
-				go func(c *client, messages chan P2PMessage) {
-					defer func() { n.removeIncomingC <- c.remoteID }()
+				if n.peers.banned(c.remoteID) {
+					c.close()
+					n.peers.releaseConn(ip)
+					return
+				}
+				n.peers.seen(c.remoteID, ip)
+				go func(c *client) {
+					defer func() {
+						n.peers.releaseConn(ip)
+						n.removeIncomingC <- c.remoteID
+					}()
 					for {
 						select {
 						case pa, ok := <-c.receiver:
 							if !ok {
 								return
 							}
-							if m, ok := pa.(P2PMessage); ok {
-								messages <- m
+							if m, ok := pa.(P2PMessage); ok && !n.deliver(c, m) {
+								c.close()
+								return
 							}
 						case err, ok := <-c.errc:
 							if !ok {
 								return
 							}
 							fmt.Println(string(n.id), "err.Error() ", err.Error())
+							if err == errInvalidPackage || err == errForeignPackage {
+								n.Penalize(c.remoteID, OffenseInvalidSignature)
+							}
 							if err.Error() == "EOF" || err.Error() == "use of closed network connection" {
 								c.close()
 								return
@@ -199,7 +290,7 @@ func (n *server) Listen() (err error) {
 							return
 						}
 					}
-				}(c, n.messages)
+				}(c)
 				n.addIncomingC <- c
 			}(conn, start)
 		}
@@ -210,6 +301,7 @@ func (n *server) Listen() (err error) {
 func (n *server) receiveHandler() {
 	n.addIncomingC = make(chan *client, 21)
 	n.removeIncomingC = make(chan []byte)
+	n.dropIncomingC = make(chan []byte)
 	n.replying = make(chan request)
 	clients := make(map[string]*client)
 
@@ -231,6 +323,10 @@ func (n *server) receiveHandler() {
 					delete(clients, string(id))
 				}
 				n.incomingNum = len(clients)
+			case id := <-n.dropIncomingC:
+				if c := clients[string(id)]; c != nil {
+					c.close()
+				}
 
 			case req, ok := <-n.replying:
 				if !ok || req.id == nil {
@@ -247,6 +343,40 @@ func (n *server) receiveHandler() {
 	}()
 }
 
+// dialAddr is the address req is dialed at
+func (n *server) dialAddr(req request) string {
+	port := req.port
+	if port == "" {
+		port = n.port
+	}
+	return net.JoinHostPort(req.addr.String(), port)
+}
+
+// dial connects to addr over the transport of the node within connectTimeout
+func (n *server) dial(ctx context.Context, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	if n.quic == nil {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	return n.quic.dial(ctx, addr)
+}
+
+// advertise signs the address of the listener and spreads it with the
+// membership, so that peers can dial the node by its ID
+func (n *server) advertise() error {
+	address := net.JoinHostPort(n.addr.String(), n.port)
+	seq := uint64(time.Now().Unix())
+	ctx, cancel := context.WithTimeout(n.ctx, handshakeTimeout)
+	defer cancel()
+	sig, err := n.self.signer.SignText(ctx, discover.RecordText(n.id, address, seq))
+	if err != nil {
+		return err
+	}
+	return n.members.Advertise(&discover.Record{ID: n.id, Address: address, Seq: seq, Signature: sig})
+}
+
 func (n *server) callHandler() {
 	n.calling = make(chan request, 21)
 	hangup := make(chan string)
@@ -256,6 +386,7 @@ func (n *server) callHandler() {
 	clients := make(map[string]*client)
 	n.addCallingC = make(chan *client)
 	n.removeCallingC = make(chan []byte)
+	n.dialFailedC = make(chan request)
 
 	go func() {
 		for {
@@ -319,14 +450,35 @@ func (n *server) callHandler() {
 				case <-req.ctx.Done():
 					continue
 				default:
+					if req.id != nil && n.peers.banned(req.id) {
+						go func(req request) {
+							select {
+							case req.errc <- errPeerBanned:
+							case <-req.ctx.Done():
+							}
+						}(req)
+						continue
+					}
 
 					if req.addr == nil && req.id != nil {
 						if bytes.Equal(idTostatus[string(req.id)], []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}) {
+							// Wait for the dial in progress
+							go func(req request) {
+								time.Sleep(1 * time.Second)
+								select {
+								case n.calling <- req:
+								case <-req.ctx.Done():
+								}
+							}(req)
 							continue
 						}
-						// Find Peer from routing map
+						// Find Peer from its signed record or the routing map
 						if n.members != nil {
-							req.addr = n.members.Lookup(req.id)
+							if r := n.members.Resolve(req.id); r != nil {
+								req.addr, req.port = r.IP(), r.Port()
+							} else {
+								req.addr = n.members.Lookup(req.id)
+							}
 						}
 
 						if req.addr == nil {
@@ -344,43 +496,43 @@ func (n *server) callHandler() {
 						idTostatus[string(req.id)] = []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}
 					}
 					if req.addr != nil {
-						addrToid[req.addr.String()+":"+n.port] = []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}
+						addrToid[n.dialAddr(req)] = []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}
 					}
 					go func(req request, start time.Time) {
 						var conn net.Conn
 						var c *client
-						if conn, err = net.Dial("tcp", req.addr.String()+":"+n.port); err != nil {
-							logger.Error(err)
+						// fail clears the pending marks of req before the
+						// caller learns of err and retries
+						fail := func(err error) {
+							select {
+							case n.dialFailedC <- req:
+							case <-n.ctx.Done():
+							}
 							select {
 							case req.errc <- err:
 							case <-req.ctx.Done():
 							}
-							//Retry later
-							go func(req request) {
-								select {
-								case n.calling <- req:
-								case <-req.ctx.Done():
-								}
-							}(req)
+						}
+						if conn, err = n.dial(req.ctx, n.dialAddr(req)); err != nil {
+							logger.Error(err)
+							fail(err)
 							return
 						}
 
-						if c, err = newClient(n.suite, n.secKey, n.pubKey, n.id, conn, false); err != nil {
+						if c, err = newClient(n.suite, n.self, n.verifyPeer, conn, false); err != nil {
 							logger.Error(err)
-							select {
-							case req.errc <- err:
-							case <-req.ctx.Done():
-							}
 							conn.Close()
-							//Retry later
-							go func(req request) {
-								select {
-								case n.calling <- req:
-								case <-req.ctx.Done():
-								}
-							}(req)
+							fail(err)
 							return
 						}
+						if n.peers.banned(c.remoteID) {
+							c.close()
+							fail(errPeerBanned)
+							return
+						}
+						if host, _, err := net.SplitHostPort(conn.RemoteAddr().String()); err == nil {
+							n.peers.seen(c.remoteID, host)
+						}
 
 						go func() {
 							defer func() { n.removeCallingC <- c.remoteID }()
@@ -390,13 +542,17 @@ func (n *server) callHandler() {
 									if !ok {
 										return
 									}
-									if m, ok := pa.(P2PMessage); ok {
-										n.messages <- m
+									if m, ok := pa.(P2PMessage); ok && !n.deliver(c, m) {
+										c.close()
+										return
 									}
 								case err, ok := <-c.errc:
 									if !ok {
 										return
 									}
+									if err == errInvalidPackage || err == errForeignPackage {
+										n.Penalize(c.remoteID, OffenseInvalidSignature)
+									}
 									if err.Error() == "EOF" || err.Error() == "use of closed network connection" {
 										c.close()
 										return
@@ -419,7 +575,8 @@ func (n *server) callHandler() {
 						}
 						select {
 						case n.addCallingC <- c:
-						case <-req.ctx.Done():
+						case <-n.ctx.Done():
+							c.close()
 						}
 					}(req, start)
 				}
@@ -432,6 +589,13 @@ func (n *server) callHandler() {
 				n.callingNum = len(clients)
 				addrToid[c.conn.RemoteAddr().String()] = c.remoteID
 				delete(idTostatus, string(c.remoteID))
+			case req := <-n.dialFailedC:
+				if req.id != nil && bytes.Equal(idTostatus[string(req.id)], []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}) {
+					delete(idTostatus, string(req.id))
+				}
+				if req.addr != nil && bytes.Equal(addrToid[n.dialAddr(req)], []byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'}) {
+					delete(addrToid, n.dialAddr(req))
+				}
 			case id, ok := <-n.removeCallingC:
 				if !ok {
 					return
@@ -472,10 +636,14 @@ func (n *server) DisConnectTo(id []byte) (err error) {
 This is a block call
 */
 func (n *server) ConnectTo(addr string, id []byte) ([]byte, error) {
-	var err error
-	callReq := request{}
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
+	return n.connectTo(ctx, addr, id)
+}
+
+func (n *server) connectTo(ctx context.Context, addr string, id []byte) ([]byte, error) {
+	var err error
+	callReq := request{}
 	callReq.ctx = ctx
 	callReq.rType = 0
 	callReq.id = id
@@ -509,46 +677,71 @@ func (n *server) ConnectTo(addr string, id []byte) ([]byte, error) {
 // Request sends a proto message to the specific node
 func (n *server) Request(id []byte, m proto.Message) (msg P2PMessage, err error) {
 	//defer logger.TimeTrack(time.Now(), "Request", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	return n.request(ctx, id, m)
+}
+
+func (n *server) request(ctx context.Context, id []byte, m proto.Message) (msg P2PMessage, err error) {
 	callReq := request{}
-	callReq.ctx, callReq.cancel = context.WithTimeout(context.Background(), 120*time.Second)
+	callReq.ctx, callReq.cancel = context.WithCancel(ctx)
 	defer callReq.cancel()
 	callReq.rType = 1
 	callReq.id = id
 	callReq.reply = make(chan interface{})
 	callReq.errc = make(chan error)
+	callReq.sent = make(chan struct{})
 	callReq.msg = m
 	select {
 	case n.calling <- callReq:
 	case <-callReq.ctx.Done():
+		err = callReq.ctx.Err()
 		return
 	}
 
-	select {
-	case r, ok := <-callReq.reply:
-		if !ok {
+	// The peer is penalized only if it leaves a request unanswered for
+	// replyTimeout once it is written to the connection, not for a dial that
+	// fails or a ctx that ends first.
+	sent := callReq.sent
+	var noReply <-chan time.Time
+	for {
+		select {
+		case <-sent:
+			sent = nil
+			timer := time.NewTimer(replyTimeout)
+			defer timer.Stop()
+			noReply = timer.C
+		case <-noReply:
+			noReply = nil
+			n.Penalize(id, OffenseTimeout)
+		case r, ok := <-callReq.reply:
+			if !ok {
+				return
+			}
+			msg, ok = r.(P2PMessage)
+			if !ok {
+				err = errors.New("Reply cast error")
+			}
 			return
-		}
-		msg, ok = r.(P2PMessage)
-		if !ok {
-			err = errors.New("Reply cast error")
-		}
-		return
-	case e, ok := <-callReq.errc:
-		if ok {
-			err = e
+		case e, ok := <-callReq.errc:
+			if ok {
+				err = e
+				if err == errInvalidPackage || err == errForeignPackage {
+					n.Penalize(id, OffenseInvalidSignature)
+				}
+			}
+			return
+		case <-callReq.ctx.Done():
+			err = callReq.ctx.Err()
+			go func() {
+				select {
+				case _ = <-callReq.reply:
+				case <-time.After(5 * time.Second):
+				}
+			}()
 			return
 		}
-	case <-callReq.ctx.Done():
-		err = callReq.ctx.Err()
-		go func() {
-			select {
-			case _ = <-callReq.reply:
-			case <-time.After(5 * time.Second):
-			}
-		}()
-		return
 	}
-	return
 }
 
 // Reply sends a reply to the specific node
@@ -573,8 +766,44 @@ func (n *server) Reply(id []byte, nonce uint64, response proto.Message) (err err
 	return
 }
 
+// Penalize lowers the score of id for offense and disconnects it once it is
+// banned
+func (n *server) Penalize(id []byte, offense Offense) {
+	if !n.peers.penalize(id, offense) {
+		return
+	}
+	logger.Event("peerBanned", map[string]interface{}{"ID": fmt.Sprintf("%x", id), "Offense": offense.String()})
+	go func() {
+		n.removeCallingC <- id
+		n.dropIncomingC <- id
+	}()
+}
+
+// PeerScores returns the standing of every peer, lowest score first
+func (n *server) PeerScores() []PeerScore {
+	return n.peers.scores()
+}
+
+// deliver hands m on to the subscribers if c is within its rate limit and
+// penalizes c otherwise. It reports false once c is banned.
+func (n *server) deliver(c *client, m P2PMessage) bool {
+	if !n.peers.allowMessage(c.remoteID) {
+		n.Penalize(c.remoteID, OffenseFlood)
+		return !n.peers.banned(c.remoteID)
+	}
+	if g, ok := m.Msg.Message.(*Gossip); ok {
+		n.receiveGossip(c, m, g)
+		return !n.peers.banned(c.remoteID)
+	}
+	select {
+	case n.messages <- m:
+	case <-c.ctx.Done():
+	}
+	return true
+}
+
+// messageDispatch hands the received messages to the dispatcher
 func (n *server) messageDispatch(ctx context.Context) {
-	subscriptions := make(map[string]chan P2PMessage)
 	go func() {
 		for {
 			select {
@@ -582,57 +811,46 @@ func (n *server) messageDispatch(ctx context.Context) {
 				if !ok {
 					return
 				}
-				if msg.Msg.Message == nil {
-
-					continue
-				}
-				messagetype := reflect.TypeOf(msg.Msg.Message).String()
-
-				if len(messagetype) > 0 && messagetype[0] == '*' {
-					messagetype = messagetype[1:]
-				}
-				out := subscriptions[messagetype]
-				if out != nil {
-					go func(msg P2PMessage) {
-						select {
-						case out <- msg:
-						}
-					}(msg)
-				}
-			case sub, ok := <-n.subscribe:
-				if !ok {
-					return
-				}
-				subscriptions[sub.eventType] = sub.message
-			case sub, ok := <-n.unscribe:
-				if !ok {
-					return
-				}
-				delete(subscriptions, sub.eventType)
+				n.dispatcher.dispatch(msg)
 			case <-ctx.Done():
+				return
 			}
 		}
 	}()
 }
 
+// Subscribe delivers the messages of the types of messages that opts select
+// on the channel of the returned subscription. Every subscription of a type
+// receives its messages.
+func (n *server) Subscribe(opts SubscribeOptions, messages ...interface{}) *Subscription {
+	return n.dispatcher.subscribe(opts, messages...)
+}
+
+// Unsubscribe stops the deliveries to s
+func (n *server) Unsubscribe(s *Subscription) {
+	n.dispatcher.unsubscribe(s)
+}
+
+// Handle registers h to answer the requests of the type of message, nil to
+// unregister it. The requests are still delivered to the subscriptions.
+func (n *server) Handle(message interface{}, h Handler) {
+	n.dispatcher.handle(message, h)
+}
+
+// DispatchStats counts the messages of every type subscribed to or handled
+func (n *server) DispatchStats() []DispatchStats {
+	return n.dispatcher.statistics()
+}
+
 // SubscribeEvent is a message subscription operation binding the P2PMessage
 func (n *server) SubscribeEvent(chanBuffer int, messages ...interface{}) (outch chan P2PMessage, err error) {
-	if chanBuffer > 0 {
-		outch = make(chan P2PMessage, chanBuffer)
-	} else {
-		outch = make(chan P2PMessage)
+	if chanBuffer < 0 {
+		chanBuffer = 0
 	}
-	for _, m := range messages {
-		n.subscribe <- subscription{reflect.TypeOf(m).String(), outch}
-	}
-	return
+	return n.Subscribe(SubscribeOptions{Buffer: chanBuffer}, messages...).out, nil
 }
 
-// UnSubscribeEvent is a un-subscription operation
+// UnSubscribeEvent removes every subscription to the types of messages
 func (n *server) UnSubscribeEvent(messages ...interface{}) {
-	for _, m := range messages {
-		n.unscribe <- subscription{reflect.TypeOf(m).String(), nil}
-
-	}
-	return
+	n.dispatcher.unsubscribeAll(messages...)
 }
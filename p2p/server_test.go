@@ -1,6 +1,9 @@
 package p2p
 
 import (
+	"bytes"
+	"context"
+	"net"
 	"os"
 	"strconv"
 	"sync"
@@ -8,6 +11,7 @@ import (
 	"time"
 
 	"github.com/DOSNetwork/core/log"
+	"github.com/DOSNetwork/core/p2p/discover"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -23,9 +27,9 @@ func receiveEvent(pListener P2PInterface, t *testing.T) {
 }
 
 func TestServer(t *testing.T) {
-	listener := []byte("9")
+	listener := newSigner()
 	os.Setenv("PUBLICIP", "127.0.0.1")
-	log.Init(listener[:])
+	log.Init(listener.Address().Bytes())
 
 	pListener, _ := CreateP2PNetwork(listener, "9905", NoDiscover)
 	pListener.Listen()
@@ -37,8 +41,7 @@ func TestServer(t *testing.T) {
 	for c := 9904; c < 9905; c++ {
 		go func(c int) {
 			defer wgForPeer.Done()
-			id := []byte(strconv.Itoa(c))
-			p, _ := CreateP2PNetwork(id, strconv.Itoa(c), NoDiscover)
+			p, _ := CreateP2PNetwork(newSigner(), strconv.Itoa(c), NoDiscover)
 			p.Listen()
 
 			var count uint64
@@ -81,3 +84,163 @@ func TestServer(t *testing.T) {
 		time.Sleep(1 * time.Second)
 	}
 }
+
+func TestConnectToAll(t *testing.T) {
+	connectTimeout, connectBackoff = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { connectTimeout, connectBackoff = 30*time.Second, time.Second }()
+	signer := newSigner()
+	os.Setenv("PUBLICIP", "127.0.0.1")
+	log.Init(signer.Address().Bytes())
+
+	member, _ := CreateP2PNetwork(signer, "9906", NoDiscover)
+	member.Listen()
+	p, _ := CreateP2PNetwork(newSigner(), "9907", NoDiscover)
+	p.Listen()
+	p.SetPort("9906")
+	if _, err := p.ConnectTo("127.0.0.1", nil); err != nil {
+		t.Fatalf("ConnectTo ,Error %s", err)
+	}
+
+	missing := newSigner().Address().Bytes()
+	groupIds := [][]byte{p.GetID(), member.GetID(), missing}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, errc := p.ConnectToAll(ctx, groupIds, 2, "partial")
+	select {
+	case r := <-out:
+		if len(r.Reachable) != 2 || len(r.Unreachable) != 1 || !bytes.Equal(r.Unreachable[0], missing) {
+			t.Errorf("ConnectToAll ,Expected 2 reachable and 1 unreachable, Actual %d and %d", len(r.Reachable), len(r.Unreachable))
+		}
+	case err := <-errc:
+		t.Fatalf("ConnectToAll ,Error %s", err)
+	}
+
+	// Asking for every member fails
+	out, errc = p.ConnectToAll(ctx, groupIds, 3, "all")
+	select {
+	case <-out:
+		t.Error("ConnectToAll succeeded without an unreachable member")
+	case err := <-errc:
+		if err == nil {
+			t.Error("ConnectToAll ,Expected an error")
+		}
+	}
+}
+
+// staticMembers resolves IDs to fixed addresses
+type staticMembers map[string]string
+
+func (m staticMembers) Join(ip []string) (int, error)      { return 0, nil }
+func (m staticMembers) Leave()                             {}
+func (m staticMembers) Lookup(id []byte) net.IP            { return nil }
+func (m staticMembers) NumOfPeers() int                    { return len(m) }
+func (m staticMembers) MembersIP() []net.IP                { return nil }
+func (m staticMembers) MembersID() [][]byte                { return nil }
+func (m staticMembers) Advertise(r *discover.Record) error { return nil }
+func (m staticMembers) Resolve(id []byte) *discover.Record {
+	if addr, ok := m[string(id)]; ok {
+		return &discover.Record{ID: id, Address: addr}
+	}
+	return nil
+}
+
+func TestConnectRecovery(t *testing.T) {
+	connectTimeout, connectBackoff = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { connectTimeout, connectBackoff = 30*time.Second, time.Second }()
+	signer := newSigner()
+	os.Setenv("PUBLICIP", "127.0.0.1")
+	log.Init(signer.Address().Bytes())
+
+	memberSigner := newSigner()
+	memberID := memberSigner.Address().Bytes()
+	p, _ := CreateP2PNetwork(signer, "9943", NoDiscover)
+	p.(*server).members = staticMembers{string(memberID): "127.0.0.1:9944"}
+	p.Listen()
+	defer p.Leave()
+	groupIds := [][]byte{p.GetID(), memberID}
+	connectAll := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		out, errc := p.ConnectToAll(ctx, groupIds, 2, "recovery")
+		select {
+		case <-out:
+			return nil
+		case err := <-errc:
+			return err
+		}
+	}
+
+	if err := connectAll(); err == nil {
+		t.Fatal("ConnectToAll reached a member that is down")
+	}
+	// Once the member is up the failed dials no longer hold it back
+	member, _ := CreateP2PNetwork(memberSigner, "9944", NoDiscover)
+	member.Listen()
+	defer member.Leave()
+	if err := connectAll(); err != nil {
+		t.Fatalf("ConnectToAll after the member came up ,Error %s", err)
+	}
+}
+
+func scoreOf(p P2PInterface, id []byte) float64 {
+	for _, s := range p.PeerScores() {
+		if bytes.Equal(s.ID, id) {
+			return s.Score
+		}
+	}
+	return maxScore
+}
+
+func TestRequestTimeout(t *testing.T) {
+	connectTimeout, connectBackoff = 200*time.Millisecond, 10*time.Millisecond
+	replyTimeout = 500 * time.Millisecond
+	defer func() {
+		connectTimeout, connectBackoff = 30*time.Second, time.Second
+		replyTimeout = 20 * time.Second
+	}()
+	signer := newSigner()
+	os.Setenv("PUBLICIP", "127.0.0.1")
+	log.Init(signer.Address().Bytes())
+
+	// The member answers no Ping, and no one listens for the missing one
+	memberSigner := newSigner()
+	memberID := memberSigner.Address().Bytes()
+	missingID := newSigner().Address().Bytes()
+	p, _ := CreateP2PNetwork(signer, "9945", NoDiscover)
+	p.(*server).members = staticMembers{string(memberID): "127.0.0.1:9946", string(missingID): "127.0.0.1:9947"}
+	p.Listen()
+	defer p.Leave()
+	member, _ := CreateP2PNetwork(memberSigner, "9946", NoDiscover)
+	member.Listen()
+	defer member.Leave()
+
+	request := func(id []byte, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_, err := p.(*server).request(ctx, id, &Ping{})
+		return err
+	}
+
+	if err := request(missingID, time.Second); err == nil {
+		t.Fatal("request reached a peer that is down")
+	}
+	if score := scoreOf(p, missingID); score != maxScore {
+		t.Errorf("A failed dial ,Expected score %v Actual %v", maxScore, score)
+	}
+
+	// The caller gives up before the peer is due to answer
+	if err := request(memberID, 200*time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatalf("request ,Expected %v Actual %v", context.DeadlineExceeded, err)
+	}
+	if score := scoreOf(p, memberID); score != maxScore {
+		t.Errorf("A request the caller gave up ,Expected score %v Actual %v", maxScore, score)
+	}
+
+	// The peer leaves a written request unanswered
+	if err := request(memberID, 2*time.Second); err != context.DeadlineExceeded {
+		t.Fatalf("request ,Expected %v Actual %v", context.DeadlineExceeded, err)
+	}
+	if score := scoreOf(p, memberID); score >= maxScore {
+		t.Errorf("An unanswered request ,Expected score below %v Actual %v", maxScore, score)
+	}
+}
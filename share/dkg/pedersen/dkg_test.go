@@ -274,6 +274,63 @@ func TestSetTimeout(t *testing.T) {
 
 }
 
+func TestCertifiedThreshold(t *testing.T) {
+	dkgs = dkgGen()
+	threshold := nbParticipants/2 + 1
+	// only a threshold of the participants deal and respond
+	present := dkgs[:threshold]
+	resps := make([]*Response, 0, nbParticipants*nbParticipants)
+	for _, dkg := range present {
+		deals, err := dkg.Deals()
+		require.Nil(t, err)
+		for i, d := range deals {
+			if i >= threshold {
+				continue
+			}
+			resp, err := dkgs[i].ProcessDeal(d)
+			require.Nil(t, err)
+			resps = append(resps, resp)
+		}
+	}
+	for _, resp := range resps {
+		for _, dkg := range present {
+			if resp.Response.Index == dkg.index {
+				continue
+			}
+			_, err := dkg.ProcessResponse(resp)
+			require.Nil(t, err)
+		}
+	}
+	for _, dkg := range present {
+		require.False(t, dkg.Certified())
+		dkg.SetTimeout()
+		require.True(t, dkg.Certified())
+		require.Len(t, dkg.QUAL(), threshold)
+	}
+
+	msg := []byte("Hello threshold Boneh-Lynn-Shacham")
+	var pubPoly *share.PubPoly
+	var sigShares [][]byte
+	for _, dkg := range present {
+		dks, err := dkg.DistKeyShare()
+		require.Nil(t, err)
+		pubPoly = share.NewPubPoly(suite, suite.Point().Base(), dks.Commitments())
+		sig, err := tbls.Sign(suite, dks.Share, msg)
+		require.Nil(t, err)
+		sigShares = append(sigShares, sig)
+	}
+	sig, err := tbls.Recover(suite, pubPoly, msg, sigShares, threshold, nbParticipants)
+	require.Nil(t, err)
+	require.Nil(t, bls.Verify(suite, pubPoly.Commit(), msg, sig))
+
+	// fewer than a threshold do not certify
+	dkgs = dkgGen()
+	for _, dkg := range dkgs {
+		dkg.SetTimeout()
+		require.False(t, dkg.Certified())
+	}
+}
+
 func TestDistKeyShare(t *testing.T) {
 	fullExchange(t)
 
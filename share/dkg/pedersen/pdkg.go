@@ -20,13 +20,19 @@ import (
 	"github.com/dedis/kyber"
 )
 
+// GroupPubKey is the public key a group generated, marshalled by the suite
+type GroupPubKey struct {
+	GroupID *big.Int
+	PubKey  []byte
+}
+
 // PDKGInterface is a interface for DKG
 type PDKGInterface interface {
 	GetGroupPublicPoly(groupId string) *share.PubPoly
 	GetShareSecurity(groupId string) *share.PriShare
 	GetGroupIDs(groupId string) [][]byte
 	GetGroupNumber() int
-	Grouping(ctx context.Context, groupId string, Participants [][]byte) (chan [5]*big.Int, chan error, error)
+	Grouping(ctx context.Context, groupId string, Participants [][]byte) (chan *GroupPubKey, chan error, error)
 	GroupDissolve(groupId string)
 }
 
@@ -53,6 +59,14 @@ type request struct {
 	reply      chan []interface{}
 }
 
+// flush asks to reply to the request of reply with the messages that arrived
+// so far
+type flush struct {
+	reqType   int
+	sessionID string
+	reply     chan []interface{}
+}
+
 // NewPDKG creates a pdkg struct
 func NewPDKG(p p2p.P2PInterface, suite suites.Suite) PDKGInterface {
 	d := &pdkg{
@@ -68,38 +82,52 @@ func NewPDKG(p p2p.P2PInterface, suite suites.Suite) PDKGInterface {
 
 func handlePeerMsg(sessionMap map[string][]interface{}, sessionReq map[string]request, p p2p.P2PInterface, sessionID string, content interface{}) {
 	sessionMap[sessionID] = append(sessionMap[sessionID], content)
-	if sessionMap[sessionID] != nil {
-		if len(sessionMap[sessionID]) == sessionReq[sessionID].numOfResps {
-			go func(req request, m []interface{}) {
-				select {
-				case <-req.ctx.Done():
-				case req.reply <- m:
-				}
-				close(req.reply)
-			}(sessionReq[sessionID], sessionMap[sessionID])
-
-			delete(sessionMap, sessionID)
-			delete(sessionReq, sessionID)
-		}
+	if req, ok := sessionReq[sessionID]; ok && len(sessionMap[sessionID]) >= req.numOfResps {
+		deliver(sessionMap, sessionReq, sessionID)
 	}
 }
 
 func handleRequest(sessionMap map[string][]interface{}, sessionReq map[string]request, req request) {
 	sessionReq[req.sessionID] = req
-	if len(sessionMap[req.sessionID]) == req.numOfResps {
-		go func(req request, m []interface{}) {
-			select {
-			case <-req.ctx.Done():
-			case req.reply <- m:
-			}
-			close(req.reply)
-		}(sessionReq[req.sessionID], sessionMap[req.sessionID])
+	if len(sessionMap[req.sessionID]) >= req.numOfResps {
+		deliver(sessionMap, sessionReq, req.sessionID)
+	}
+}
+
+// handleFlush replies to the request of f with the messages that arrived so
+// far, if it is still waiting
+func handleFlush(sessionMap map[string][]interface{}, sessionReq map[string]request, f flush) {
+	if req, ok := sessionReq[f.sessionID]; ok && req.reply == f.reply {
+		deliver(sessionMap, sessionReq, f.sessionID)
+	}
+}
+
+// deliver replies to the request of sessionID with the messages collected for
+// it
+func deliver(sessionMap map[string][]interface{}, sessionReq map[string]request, sessionID string) {
+	go func(req request, m []interface{}) {
+		select {
+		case <-req.ctx.Done():
+		case req.reply <- m:
+		}
+		close(req.reply)
+	}(sessionReq[sessionID], sessionMap[sessionID])
 
-		delete(sessionMap, req.sessionID)
-		delete(sessionReq, req.sessionID)
+	delete(sessionMap, sessionID)
+	delete(sessionReq, sessionID)
+}
+
+// ack answers a request with an empty message of its type
+func ack(reply proto.Message) p2p.Handler {
+	return func(p2p.P2PMessage) (proto.Message, error) {
+		return reply, nil
 	}
 }
+
 func (d *pdkg) listen() {
+	d.p.Handle(PublicKey{}, ack(&PublicKey{}))
+	d.p.Handle(Deal{}, ack(&Deal{}))
+	d.p.Handle(Responses{}, ack(&Responses{}))
 	peersToBuf, _ := d.p.SubscribeEvent(50, PublicKey{}, Deal{}, Responses{})
 	go func() {
 		sessionPubKeys := make(map[string][]interface{})
@@ -117,13 +145,15 @@ func (d *pdkg) listen() {
 				switch content := msg.Msg.Message.(type) {
 				case *PublicKey:
 					d.logger.Event("PeerPublicKey", map[string]interface{}{"GroupID": content.SessionId})
-					d.p.Reply(msg.Sender, msg.RequestNonce, &PublicKey{})
 					handlePeerMsg(sessionPubKeys, sessionReqPubs, d.p, content.SessionId, content)
 				case *Deal:
-					d.p.Reply(msg.Sender, msg.RequestNonce, &Deal{})
+					//A deal is blamed on the member at its index, which must be its sender
+					if !d.isDealer(content, msg.Sender) {
+						d.p.Penalize(msg.Sender, p2p.OffenseBadDeal)
+						continue
+					}
 					handlePeerMsg(sessionDeals, sessionReqDeals, d.p, content.SessionId, content)
 				case *Responses:
-					d.p.Reply(msg.Sender, msg.RequestNonce, &Responses{})
 					resps := content.Response
 					for _, resp := range resps {
 						handlePeerMsg(sessionResps, sessionReResps, d.p, content.SessionId, resp)
@@ -134,7 +164,8 @@ func (d *pdkg) listen() {
 				if !ok {
 					return
 				}
-				if r, ok := req.(request); ok {
+				switch r := req.(type) {
+				case request:
 					switch r.reqType {
 					case 0:
 						handleRequest(sessionPubKeys, sessionReqPubs, r)
@@ -143,7 +174,16 @@ func (d *pdkg) listen() {
 					case 2:
 						handleRequest(sessionResps, sessionReResps, r)
 					}
-				} else {
+				case flush:
+					switch r.reqType {
+					case 0:
+						handleFlush(sessionPubKeys, sessionReqPubs, r)
+					case 1:
+						handleFlush(sessionDeals, sessionReqDeals, r)
+					case 2:
+						handleFlush(sessionResps, sessionReResps, r)
+					}
+				default:
 					fmt.Println("handleRequest cast error")
 
 				}
@@ -152,46 +192,73 @@ func (d *pdkg) listen() {
 	}()
 }
 
-func (d *pdkg) Grouping(ctx context.Context, sessionID string, groupIds [][]byte) (chan [5]*big.Int, chan error, error) {
+func (d *pdkg) Grouping(ctx context.Context, sessionID string, groupIds [][]byte) (chan *GroupPubKey, chan error, error) {
 	group := &group{participants: groupIds}
 	var errcList []chan error
 	if _, loaded := d.groups.LoadOrStore(sessionID, group); loaded {
 		return nil, nil, errors.New("dkg: duplicate share public key")
 	}
 
-	//Check if all members are reachable
-	connc, errc := d.p.ConnectToAll(ctx, groupIds, sessionID)
+	//Check if enough members are reachable. The members whose public key does
+	//not arrive in time are left out of the DKG, which is certified once a
+	//threshold of the members qualify.
+	connc, errc := d.p.ConnectToAll(ctx, groupIds, threshold(len(groupIds)), sessionID)
 	errcList = append(errcList, errc)
 	//exchange pub key
 	selfPubc, secrc, errc := genPub(ctx, d.logger, connc, d.suite, d.p.GetID(), groupIds, sessionID)
 	errcList = append(errcList, errc)
 	selfPubcs := fanOut(ctx, selfPubc, 2)
-	errcList = append(errcList, sendToMembers(ctx, d.logger, selfPubcs[0], d.p, groupIds, sessionID))
-	peerPubc := askMembers(ctx, d.logger, d.bufToNode, len(groupIds)-1, 0, sessionID)
-	errcList = append(errcList, errc)
-	partPubsc, errc := exchangePub(ctx, d.logger, selfPubcs[1], peerPubc, d.p, groupIds, sessionID)
+	errcList = append(errcList, sendToMembers(ctx, d.logger, selfPubcs[0], d.p, sessionID))
+	partPubsc, errc := exchangePub(ctx, d.logger, selfPubcs[1], d.bufToNode, d.p, groupIds, sessionID)
 	errcList = append(errcList, errc)
 
 	//generate a dkg
-	dkgcStep1, errc := genDistKeyGenerator(ctx, d.logger, secrc, partPubsc, len(groupIds), d.suite, sessionID)
+	dkgcStep1, errc := genDistKeyGenerator(ctx, d.logger, secrc, partPubsc, groupIds, d.suite, sessionID)
 	errcList = append(errcList, errc)
 
 	//generate deals for other member and process deals from other member
 	dkgcStep2, errc := genDealsAndSend(ctx, d.logger, dkgcStep1, d.p, groupIds, sessionID)
 	errcList = append(errcList, errc)
-	dkgcStep3, respsc, errc := getAndProcessDeals(ctx, d.logger, dkgcStep2, askMembers(ctx, d.logger, d.bufToNode, len(groupIds)-1, 1, sessionID), sessionID)
+	dkgcStep3, respsc, errc := getAndProcessDeals(ctx, d.logger, dkgcStep2, d.p, groupIds, d.bufToNode, sessionID)
 	errcList = append(errcList, errc)
-	errcList = append(errcList, sendToMembers(ctx, d.logger, respsc, d.p, groupIds, sessionID))
+	errcList = append(errcList, sendToMembers(ctx, d.logger, respsc, d.p, sessionID))
 
 	//process response to certify dkg and generate a group sec and pub key
-	cetifiedDkgc, errc := getAndProcessResponses(ctx, d.logger, dkgcStep3, askMembers(ctx, d.logger, d.bufToNode, (len(groupIds)-1)*(len(groupIds)-1), 2, sessionID), sessionID)
+	cetifiedDkgc, errc := getAndProcessResponses(ctx, d.logger, dkgcStep3, d.bufToNode, sessionID)
+	errcList = append(errcList, errc)
 	outc, errc := genGroup(ctx, d.logger, group, d.suite, cetifiedDkgc, sessionID)
 	errcList = append(errcList, errc)
 	errc = mergeErrors(ctx, d.logger, sessionID, errcList...)
 	return outc, errc, nil
 }
 
-func genPub(ctx context.Context, logger log.Logger, conn chan bool, suite suites.Suite, id []byte, groupIds [][]byte, sessionID string) (out chan interface{}, secrc chan kyber.Scalar, errc chan error) {
+// threshold is the number of honest members a group of n needs
+func threshold(n int) int {
+	return n/2 + 1
+}
+
+// exchangeTimeout is how long a member waits for the messages of the others
+// at each step of the DKG. The members that miss it are left out. It outlasts
+// the dials ConnectToAll makes to an unreachable member, which hold up some
+// members more than others.
+var exchangeTimeout = 2 * time.Minute
+
+// session is a DKG and the members that take part in it, the ones whose public
+// key arrived in time. The others are absent: their index holds a placeholder
+// key and no deal goes to or comes from them.
+type session struct {
+	dkg     *DistKeyGenerator
+	members [][]byte
+	absent  map[uint32]bool
+}
+
+// groupMsg is a message for some members of a group
+type groupMsg struct {
+	members [][]byte
+	msg     proto.Message
+}
+
+func genPub(ctx context.Context, logger log.Logger, conn chan p2p.Reachability, suite suites.Suite, id []byte, groupIds [][]byte, sessionID string) (out chan interface{}, secrc chan kyber.Scalar, errc chan error) {
 	out = make(chan interface{})
 	secrc = make(chan kyber.Scalar)
 	errc = make(chan error)
@@ -200,9 +267,12 @@ func genPub(ctx context.Context, logger log.Logger, conn chan bool, suite suites
 		defer close(out)
 		defer close(errc)
 		select {
-		case c, ok := <-conn:
-			if ok && c == true {
+		case reach, ok := <-conn:
+			if ok {
 				defer logger.TimeTrack(time.Now(), "genPub", map[string]interface{}{"GroupID": sessionID})
+				if len(reach.Unreachable) > 0 {
+					logger.Event("unreachableMembers", map[string]interface{}{"GroupID": sessionID, "Unreachable": len(reach.Unreachable)})
+				}
 				//Index pub key
 				index := -1
 				for i, groupId := range groupIds {
@@ -226,7 +296,7 @@ func genPub(ctx context.Context, logger log.Logger, conn chan bool, suite suites
 				} else {
 					pubkey := &PublicKey{SessionId: sessionID, Index: uint32(index), Publickey: &vss.PublicKey{Binary: bin}}
 					select {
-					case out <- pubkey:
+					case out <- &groupMsg{members: reach.Reachable, msg: pubkey}:
 					case <-ctx.Done():
 					}
 					return
@@ -241,7 +311,10 @@ func genPub(ctx context.Context, logger log.Logger, conn chan bool, suite suites
 	return
 }
 
-func exchangePub(ctx context.Context, logger log.Logger, selfPubc chan interface{}, peerPubc chan []interface{}, p p2p.P2PInterface, groupIds [][]byte, sessionID string) (out chan []*PublicKey, errc chan error) {
+// exchangePub collects the public keys of the members once this member sent
+// its own, up to exchangeTimeout for the ones that are late. It fails if fewer
+// than a threshold arrive.
+func exchangePub(ctx context.Context, logger log.Logger, selfPubc chan interface{}, bufToNode chan interface{}, p p2p.P2PInterface, groupIds [][]byte, sessionID string) (out chan []*PublicKey, errc chan error) {
 	out = make(chan []*PublicKey)
 	errc = make(chan error)
 	go func() {
@@ -256,39 +329,50 @@ func exchangePub(ctx context.Context, logger log.Logger, selfPubc chan interface
 			if ok {
 				fmt.Println("exchangePub selfPubc")
 				logger.TimeTrack(time.Now(), "exchangePubselfPubc", map[string]interface{}{"GroupID": sessionID})
-				if pubkey, ok := resp.(*PublicKey); ok {
-					partPubs = append(partPubs, pubkey)
-				}
-			}
-		}
-		for {
-			select {
-			case <-ctx.Done():
-			case resps, ok := <-peerPubc:
-				if !ok {
-					return
-				}
-				fmt.Println("exchangePub peerPubc ", len(resps))
-				logger.TimeTrack(time.Now(), "exchangePubpeerPubc", map[string]interface{}{"GroupID": sessionID})
-				for _, resp := range resps {
-					if pubkey, ok := resp.(*PublicKey); ok {
+				if m, ok := resp.(*groupMsg); ok {
+					if pubkey, ok := m.msg.(*PublicKey); ok {
 						partPubs = append(partPubs, pubkey)
 					}
 				}
 			}
-			if len(partPubs) == len(groupIds) {
-				select {
-				case <-ctx.Done():
-				case out <- partPubs:
-				}
+		}
+		if len(partPubs) == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case resps, ok := <-askMembers(ctx, logger, bufToNode, len(groupIds)-1, 0, sessionID):
+			if !ok {
 				return
 			}
+			fmt.Println("exchangePub peerPubc ", len(resps))
+			logger.TimeTrack(time.Now(), "exchangePubpeerPubc", map[string]interface{}{"GroupID": sessionID})
+			seen := make(map[uint32]bool)
+			for _, pubkey := range partPubs {
+				seen[pubkey.Index] = true
+			}
+			for _, resp := range resps {
+				if pubkey, ok := resp.(*PublicKey); ok && int(pubkey.Index) < len(groupIds) && !seen[pubkey.Index] {
+					seen[pubkey.Index] = true
+					partPubs = append(partPubs, pubkey)
+				}
+			}
+		}
+		if len(partPubs) < threshold(len(groupIds)) {
+			reportErr(ctx, errc, fmt.Errorf("got %d of %d public keys, %d needed", len(partPubs), len(groupIds), threshold(len(groupIds))))
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case out <- partPubs:
 		}
 	}()
 	return
 }
 
-func sendToMembers(ctx context.Context, logger log.Logger, msgc chan interface{}, p p2p.P2PInterface, groupIds [][]byte, sessionID string) (errc chan error) {
+// sendToMembers sends the groupMsg of msgc to its members
+func sendToMembers(ctx context.Context, logger log.Logger, msgc chan interface{}, p p2p.P2PInterface, sessionID string) (errc chan error) {
 	errc = make(chan error)
 	go func() {
 		defer close(errc)
@@ -298,25 +382,11 @@ func sendToMembers(ctx context.Context, logger log.Logger, msgc chan interface{}
 			if ok {
 				defer logger.TimeTrack(time.Now(), "sendToMembers", map[string]interface{}{"GroupID": sessionID})
 
-				if m, ok := msg.(proto.Message); ok {
-					var wg sync.WaitGroup
-					wg.Add(len(groupIds) - 1)
-					for i, id := range groupIds {
-						if r := bytes.Compare(p.GetID(), id); r != 0 {
-							go func(i int, id []byte) {
-								defer wg.Done()
-								for {
-									//retry until success or ctx.Done
-									if _, err := p.Request(id, m); err != nil {
-										reportErr(ctx, errc, err)
-									} else {
-										return
-									}
-								}
-							}(i, id)
-						}
+				if m, ok := msg.(*groupMsg); ok {
+					//retry until every member has it or ctx.Done
+					if err := p.Broadcast(ctx, m.members, m.msg); err != nil {
+						reportErr(ctx, errc, err)
 					}
-					wg.Wait()
 				}
 			}
 		}
@@ -324,6 +394,8 @@ func sendToMembers(ctx context.Context, logger log.Logger, msgc chan interface{}
 	return
 }
 
+// askMembers asks for numOfResp messages of reqTpe, and for the ones that
+// arrived once exchangeTimeout is over if they are fewer
 func askMembers(ctx context.Context, logger log.Logger, bufToNode chan interface{}, numOfResp, reqTpe int, sessionID string) (out chan []interface{}) {
 	out = make(chan []interface{})
 	go func() {
@@ -332,14 +404,30 @@ func askMembers(ctx context.Context, logger log.Logger, bufToNode chan interface
 		req := request{ctx: ctx, reqType: reqTpe, sessionID: sessionID, numOfResps: numOfResp, reply: out}
 		select {
 		case <-ctx.Done():
+			return
 		case bufToNode <- req:
 		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(exchangeTimeout):
+		}
+		select {
+		case <-ctx.Done():
+		case bufToNode <- flush{reqType: reqTpe, sessionID: sessionID, reply: out}:
+		}
 	}()
 	return
 }
 
-func genDistKeyGenerator(ctx context.Context, logger log.Logger, secrc chan kyber.Scalar, partPubs chan []*PublicKey, numOfPubkeys int, suite suites.Suite, sessionID string) (out chan *DistKeyGenerator, errc chan error) {
-	out = make(chan *DistKeyGenerator)
+// absentKey is the public key that stands for an absent member. Every member
+// derives the same one, and no one knows its secret.
+func absentKey(suite suites.Suite, sessionID string, index int) kyber.Point {
+	return suite.Point().Pick(suite.XOF([]byte(fmt.Sprintf("dkg-absent-%s-%d", sessionID, index))))
+}
+
+func genDistKeyGenerator(ctx context.Context, logger log.Logger, secrc chan kyber.Scalar, partPubs chan []*PublicKey, groupIds [][]byte, suite suites.Suite, sessionID string) (out chan *session, errc chan error) {
+	out = make(chan *session)
 	errc = make(chan error)
 	go func() {
 		defer close(out)
@@ -353,7 +441,7 @@ func genDistKeyGenerator(ctx context.Context, logger log.Logger, secrc chan kybe
 				case pubs, ok := <-partPubs:
 					if ok {
 						defer logger.TimeTrack(time.Now(), "genDistKeyGenerator", map[string]interface{}{"GroupID": sessionID})
-						pubPoints := make([]kyber.Point, numOfPubkeys)
+						pubPoints := make([]kyber.Point, len(groupIds))
 						for _, pubkey := range pubs {
 							pubPoints[pubkey.Index] = suite.Point()
 							if err := pubPoints[pubkey.Index].UnmarshalBinary(pubkey.Publickey.Binary); err != nil {
@@ -361,13 +449,26 @@ func genDistKeyGenerator(ctx context.Context, logger log.Logger, secrc chan kybe
 								return
 							}
 						}
-						dkg, err := NewDistKeyGenerator(suite, sec, pubPoints, numOfPubkeys/2+1)
+						sess := &session{absent: make(map[uint32]bool)}
+						for i := range pubPoints {
+							if pubPoints[i] == nil {
+								pubPoints[i] = absentKey(suite, sessionID, i)
+								sess.absent[uint32(i)] = true
+							} else {
+								sess.members = append(sess.members, groupIds[i])
+							}
+						}
+						if len(sess.absent) > 0 {
+							logger.Event("absentMembers", map[string]interface{}{"GroupID": sessionID, "Absent": len(sess.absent)})
+						}
+						dkg, err := NewDistKeyGenerator(suite, sec, pubPoints, threshold(len(groupIds)))
 						if err != nil {
 							reportErr(ctx, errc, err)
 						} else {
+							sess.dkg = dkg
 							select {
 							case <-ctx.Done():
-							case out <- dkg:
+							case out <- sess:
 							}
 							return
 						}
@@ -380,23 +481,26 @@ func genDistKeyGenerator(ctx context.Context, logger log.Logger, secrc chan kybe
 	return
 }
 
-func genDealsAndSend(ctx context.Context, logger log.Logger, dkgc chan *DistKeyGenerator, p p2p.P2PInterface, groupIds [][]byte, sessionID string) (out chan *DistKeyGenerator, errc chan error) {
-	out = make(chan *DistKeyGenerator)
+func genDealsAndSend(ctx context.Context, logger log.Logger, sessc chan *session, p p2p.P2PInterface, groupIds [][]byte, sessionID string) (out chan *session, errc chan error) {
+	out = make(chan *session)
 	errc = make(chan error)
 	go func() {
 		defer close(out)
 		defer close(errc)
 		select {
 		case <-ctx.Done():
-		case dkg, ok := <-dkgc:
+		case sess, ok := <-sessc:
 			if ok {
 				defer logger.TimeTrack(time.Now(), "genDealsAndSend", map[string]interface{}{"GroupID": sessionID})
 
-				if deals, err := dkg.Deals(); err == nil {
+				if deals, err := sess.dkg.Deals(); err == nil {
 					var wg sync.WaitGroup
-					wg.Add(len(groupIds) - 1)
 					for i, d := range deals {
+						if sess.absent[uint32(i)] {
+							continue
+						}
 						d.SessionId = sessionID
+						wg.Add(1)
 						func(id []byte, d *Deal) {
 							defer wg.Done()
 							if _, err := p.Request(id, d); err != nil {
@@ -408,7 +512,7 @@ func genDealsAndSend(ctx context.Context, logger log.Logger, dkgc chan *DistKeyG
 					wg.Wait()
 					select {
 					case <-ctx.Done():
-					case out <- dkg:
+					case out <- sess:
 					}
 				} else {
 					reportErr(ctx, errc, err)
@@ -418,45 +522,50 @@ func genDealsAndSend(ctx context.Context, logger log.Logger, dkgc chan *DistKeyG
 	}()
 	return
 }
-func getAndProcessDeals(ctx context.Context, logger log.Logger, dkgc chan *DistKeyGenerator, dealsc chan []interface{}, sessionID string) (dkgOut chan *DistKeyGenerator, out chan interface{}, errc chan error) {
-	dkgOut = make(chan *DistKeyGenerator)
+
+// getAndProcessDeals processes the deals of the other members and penalizes
+// the dealers of the ones that fail. A deal that cannot be decrypted may come
+// from a member that left out another set of members, so it is only dropped.
+func getAndProcessDeals(ctx context.Context, logger log.Logger, sessc chan *session, p p2p.P2PInterface, groupIds [][]byte, bufToNode chan interface{}, sessionID string) (sessOut chan *session, out chan interface{}, errc chan error) {
+	sessOut = make(chan *session)
 	out = make(chan interface{})
 	errc = make(chan error)
 	go func() {
-		defer close(dkgOut)
+		defer close(sessOut)
 		defer close(out)
 		defer close(errc)
 		select {
 		case <-ctx.Done():
-		case dkg, ok := <-dkgc:
+		case sess, ok := <-sessc:
 			if ok {
 				defer logger.TimeTrack(time.Now(), "getAndProcessDeals", map[string]interface{}{"GroupID": sessionID})
 				select {
 				case <-ctx.Done():
-				case deals, ok := <-dealsc:
+				case deals, ok := <-askMembers(ctx, logger, bufToNode, len(sess.members)-1, 1, sessionID):
 					if ok {
 						var resps []*Response
 						for _, d := range deals {
-							if deal, ok := d.(*Deal); ok {
-								if resp, err := dkg.ProcessDeal(deal); err == nil {
+							if deal, ok := d.(*Deal); ok && !sess.absent[deal.Index] {
+								if resp, err := sess.dkg.ProcessDeal(deal); err == nil {
 									resp.SessionId = sessionID
 									if vss.StatusApproval == resp.Response.Status {
 										resps = append(resps, resp)
 									} else {
-										reportErr(ctx, errc, errors.New("resp StatusNotApproval"))
+										penalizeDealer(p, groupIds, deal)
+										logger.Error(errors.New("resp StatusNotApproval"))
 									}
 								} else {
-									reportErr(ctx, errc, err)
+									logger.Error(err)
 								}
 							}
 						}
 
 						select {
-						case out <- &Responses{SessionId: sessionID, Response: resps}:
+						case out <- &groupMsg{members: sess.members, msg: &Responses{SessionId: sessionID, Response: resps}}:
 						case <-ctx.Done():
 						}
 						select {
-						case dkgOut <- dkg:
+						case sessOut <- sess:
 						case <-ctx.Done():
 						}
 					}
@@ -467,7 +576,28 @@ func getAndProcessDeals(ctx context.Context, logger log.Logger, dkgc chan *DistK
 	return
 }
 
-func getAndProcessResponses(ctx context.Context, logger log.Logger, dkgc chan *DistKeyGenerator, respsc chan []interface{}, sessionID string) (out chan *DistKeyGenerator, errc chan error) {
+// isDealer reports whether sender is the member at the index of deal, if the
+// group of deal is known
+func (d *pdkg) isDealer(deal *Deal, sender []byte) bool {
+	v, ok := d.groups.Load(deal.SessionId)
+	if !ok {
+		return true
+	}
+	participants := v.(*group).participants
+	return int(deal.Index) < len(participants) && bytes.Equal(participants[deal.Index], sender)
+}
+
+// penalizeDealer penalizes the member that sent deal, the one at its index
+func penalizeDealer(p p2p.P2PInterface, groupIds [][]byte, deal *Deal) {
+	if int(deal.Index) < len(groupIds) {
+		p.Penalize(groupIds[deal.Index], p2p.OffenseBadDeal)
+	}
+}
+
+// getAndProcessResponses processes the responses of the other members to the
+// deals. Once they are in or exchangeTimeout is over, the missing responses
+// count as complaints.
+func getAndProcessResponses(ctx context.Context, logger log.Logger, sessc chan *session, bufToNode chan interface{}, sessionID string) (out chan *DistKeyGenerator, errc chan error) {
 	out = make(chan *DistKeyGenerator)
 	errc = make(chan error)
 	go func() {
@@ -475,22 +605,32 @@ func getAndProcessResponses(ctx context.Context, logger log.Logger, dkgc chan *D
 		defer close(errc)
 		select {
 		case <-ctx.Done():
-		case dkg, ok := <-dkgc:
+		case sess, ok := <-sessc:
 			if ok {
 				defer logger.TimeTrack(time.Now(), "getAndProcessResponses", map[string]interface{}{"GroupID": sessionID})
+				others := len(sess.members) - 1
 				select {
 				case <-ctx.Done():
-				case resps, ok := <-respsc:
+				case resps, ok := <-askMembers(ctx, logger, bufToNode, others*others, 2, sessionID):
 					if ok {
+						dkg := sess.dkg
 						for _, r := range resps {
 							if resp, ok := r.(*Response); ok {
-								if _, err := dkg.ProcessResponse(resp); err != nil {
+								if sess.absent[resp.Index] || sess.absent[resp.Response.GetIndex()] {
+									continue
+								}
+								//skip the responses to a deal this member did not get or dropped
+								if _, ok := dkg.verifiers[resp.Index]; !ok {
+									continue
+								}
+								if _, err := dkg.ProcessResponse(resp); err != nil && err != vss.ErrNoDealBeforeResponse {
 									reportErr(ctx, errc, err)
 								}
 							} else {
 								reportErr(ctx, errc, errors.New("Response cast error"))
 							}
 						}
+						dkg.SetTimeout()
 
 						select {
 						case <-ctx.Done():
@@ -504,8 +644,8 @@ func getAndProcessResponses(ctx context.Context, logger log.Logger, dkgc chan *D
 	}()
 	return
 }
-func genGroup(ctx context.Context, logger log.Logger, group *group, suite suites.Suite, dkgc <-chan *DistKeyGenerator, sessionID string) (out chan [5]*big.Int, errc chan error) {
-	out = make(chan [5]*big.Int)
+func genGroup(ctx context.Context, logger log.Logger, group *group, suite suites.Suite, dkgc <-chan *DistKeyGenerator, sessionID string) (out chan *GroupPubKey, errc chan error) {
+	out = make(chan *GroupPubKey)
 	errc = make(chan error)
 	go func() {
 		defer close(out)
@@ -522,10 +662,9 @@ func genGroup(ctx context.Context, logger log.Logger, group *group, suite suites
 					group.secShare = secShare
 					group.pubPoly = share.NewPubPoly(suite, suite.Point().Base(), group.secShare.Commitments())
 					pubKey := group.pubPoly.Commit()
-					if pubKeyCoor, err := decodePubKey(pubKey); err == nil {
+					if pubKeyMar, err := pubKey.MarshalBinary(); err == nil {
 						if groupId, ok := new(big.Int).SetString(sessionID, 16); ok {
-							dataReturn := [5]*big.Int{groupId}
-							copy(dataReturn[1:], pubKeyCoor[:])
+							dataReturn := &GroupPubKey{GroupID: groupId, PubKey: pubKeyMar}
 							select {
 							case <-ctx.Done():
 							case out <- dataReturn:
@@ -597,19 +736,6 @@ func (d *pdkg) GroupDissolve(groupId string) {
 	d.logger.Event("GroupDissolve", map[string]interface{}{"GroupID": groupId, "GroupNumber": d.GetGroupNumber()})
 }
 
-func decodePubKey(pubKey kyber.Point) (pubKeyCoor [4]*big.Int, err error) {
-	pubKeyMar, err := pubKey.MarshalBinary()
-	if err != nil {
-		return
-	}
-
-	for i := 0; i < 4; i++ {
-		pubKeyCoor[i] = new(big.Int).SetBytes(pubKeyMar[32*i+1 : 32*i+33])
-	}
-
-	return
-}
-
 func reportErr(ctx context.Context, errc chan error, err error) {
 	s := stack.Trace().TrimRuntime()
 	//d.logger.Error(err)
@@ -3,11 +3,13 @@ package dkg
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"os"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/DOSNetwork/core/log"
 
@@ -16,6 +18,8 @@ import (
 	bls "github.com/DOSNetwork/core/sign/bls"
 	tbls "github.com/DOSNetwork/core/sign/tbls"
 	"github.com/DOSNetwork/core/suites"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func buildConn(s, d p2p.P2PInterface, port string, t *testing.T) {
@@ -31,9 +35,24 @@ func buildConn(s, d p2p.P2PInterface, port string, t *testing.T) {
 	}
 	s.SetPort(oldPort)
 }
-func setUpP2P(id []byte, port string, t *testing.T) (p p2p.P2PInterface) {
-	var err error
-	p, err = p2p.CreateP2PNetwork(id, port, 0)
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text))), s.key)
+}
+
+func setUpP2P(port string, t *testing.T) (p p2p.P2PInterface) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = p2p.CreateP2PNetwork(&keySigner{key}, port, 0)
 	if err != nil {
 		t.Errorf("CreateP2PNetwork err %s", err)
 	}
@@ -41,18 +60,15 @@ func setUpP2P(id []byte, port string, t *testing.T) (p p2p.P2PInterface) {
 	return
 }
 
-func buildPdkg(size int, t *testing.T) ([]PDKGInterface, [][]byte) {
+func buildPdkg(size, portStart int, t *testing.T) ([]PDKGInterface, [][]byte) {
 	var p []p2p.P2PInterface
 	var d []PDKGInterface
 	var groupIds [][]byte
-	portStart := 9905
-	id := "Participant"
 	suite := suites.MustFind("bn256")
 	for i := 0; i < size; i++ {
 		nodePort := strconv.Itoa(portStart + i)
-		nodeId := []byte(id + strconv.Itoa(i))
-		groupIds = append(groupIds, nodeId)
-		pi := setUpP2P(nodeId, nodePort, t)
+		pi := setUpP2P(nodePort, t)
+		groupIds = append(groupIds, pi.GetID())
 		p = append(p, pi)
 		d = append(d, NewPDKG(pi, suite))
 	}
@@ -74,7 +90,7 @@ func TestPDKG(t *testing.T) {
 	id := []byte("Participant0")
 	log.Init(id[:])
 
-	pdkgs, groupIds := buildPdkg(11, t)
+	pdkgs, groupIds := buildPdkg(11, 9905, t)
 
 	var wg sync.WaitGroup
 	wg.Add(11)
@@ -125,3 +141,64 @@ func TestPDKG(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestPDKGAbsentMember(t *testing.T) {
+	defer func(timeout time.Duration) { exchangeTimeout = timeout }(exchangeTimeout)
+	exchangeTimeout = 10 * time.Second
+
+	os.Setenv("APPSESSION", "test")
+	os.Setenv("LOGIP", "163.172.36.173:9500")
+	os.Setenv("PUBLICIP", "0.0.0.0")
+	id := []byte("Participant0")
+	log.Init(id[:])
+
+	pdkgs, ids := buildPdkg(5, 9916, t)
+	//the member at index 2 never shows up
+	groupIds := append([][]byte{ids[0], ids[1], []byte("absent member id")}, ids[2:]...)
+	groupID := "a"
+
+	var wg sync.WaitGroup
+	wg.Add(len(pdkgs))
+	pubKeys := make([][]byte, len(pdkgs))
+	for i, pdkg := range pdkgs {
+		go func(i int, pdkg PDKGInterface) {
+			defer wg.Done()
+			out, errc, err := pdkg.Grouping(context.Background(), groupID, groupIds)
+			if err != nil {
+				t.Errorf("Grouping err %s", err)
+				return
+			}
+			select {
+			case pubKey, ok := <-out:
+				if ok {
+					pubKeys[i] = pubKey.PubKey
+				}
+			case err := <-errc:
+				t.Errorf("Grouping err %s", err)
+			}
+		}(i, pdkg)
+	}
+	wg.Wait()
+	for i := range pubKeys {
+		if !bytes.Equal(pubKeys[i], pubKeys[0]) {
+			t.Fatalf("Grouping ,Expected the same group public key, Actual [% x] and [% x]", pubKeys[0], pubKeys[i])
+		}
+	}
+
+	content := []byte{'a', 'b'}
+	var sigShares [][]byte
+	for _, pdkg := range pdkgs[:threshold(len(groupIds))] {
+		sig, err := tbls.Sign(suite, pdkg.GetShareSecurity(groupID), content)
+		if err != nil {
+			t.Fatalf("Sign err %s", err)
+		}
+		sigShares = append(sigShares, sig)
+	}
+	sig, err := tbls.Recover(suite, pdkgs[0].GetGroupPublicPoly(groupID), content, sigShares, threshold(len(groupIds)), len(groupIds))
+	if err != nil {
+		t.Fatalf("Recover err %s", err)
+	}
+	if err = bls.Verify(suite, pdkgs[1].GetGroupPublicPoly(groupID).Commit(), content, sig); err != nil {
+		t.Errorf("Verify err %s", err)
+	}
+}
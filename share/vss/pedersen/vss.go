@@ -490,6 +490,9 @@ func RecoverSecret(suite suites.Suite, deals []*Deal, n, t int) (kyber.Scalar, e
 // for this DKG protocol round. The caller is expected to call this after a long timeout
 // so each DKG node can still compute its share if enough Deals are valid.
 func (v *Verifier) SetTimeout() {
+	if v.aggregator == nil {
+		return
+	}
 	v.aggregator.cleanVerifiers()
 }
 
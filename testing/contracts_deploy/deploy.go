@@ -82,10 +82,10 @@ func updateBridgeAddr(path string, updated string) error {
 	return nil
 }
 
-func updateBridge(client *ethclient.Client, key *keystore.Key, bridgeAddress, proxyAddress, paymentAddress, crAddress common.Address) (err error) {
+func updateBridge(client *ethclient.Client, key *keystore.Key, chainID *big.Int, bridgeAddress, proxyAddress, paymentAddress, crAddress common.Address) (err error) {
 	var auth *bind.TransactOpts
 	fmt.Println("start to update proxy address to bridge...")
-	auth = bind.NewKeyedTransactor(key.PrivateKey)
+	auth = onchain.NewKeyedTransactor(key.PrivateKey, chainID)
 	auth.GasLimit = uint64(6000000)
 	auth.GasPrice = big.NewInt(30000000000)
 	bridge, err := dosbridge.NewDosbridge(bridgeAddress, client)
@@ -157,10 +157,10 @@ func updateBridge(client *ethclient.Client, key *keystore.Key, bridgeAddress, pr
 	return
 }
 
-func addProxyToCRWhiteList(client *ethclient.Client, key *keystore.Key, crAddress common.Address, proxyAddress common.Address) (err error) {
+func addProxyToCRWhiteList(client *ethclient.Client, key *keystore.Key, chainID *big.Int, crAddress common.Address, proxyAddress common.Address) (err error) {
 	var auth *bind.TransactOpts
 	fmt.Println("start to update proxy address to bridge...")
-	auth = bind.NewKeyedTransactor(key.PrivateKey)
+	auth = onchain.NewKeyedTransactor(key.PrivateKey, chainID)
 	auth.GasLimit = uint64(6000000)
 	auth.GasPrice = big.NewInt(30000000000)
 	cr, err := commitreveal.NewCommitreveal(crAddress, client)
@@ -195,7 +195,8 @@ func addProxyToCRWhiteList(client *ethclient.Client, key *keystore.Key, crAddres
 func deployContract(contractPath, targetTask, configPath string, config configuration.Config, chainConfig configuration.ChainConfig, client *ethclient.Client, key *keystore.Key) {
 	var tx *types.Transaction
 	var address common.Address
-	auth := bind.NewKeyedTransactor(key.PrivateKey)
+	chainID := new(big.Int).SetUint64(chainConfig.ChainID)
+	auth := onchain.NewKeyedTransactor(key.PrivateKey, chainID)
 	auth.GasLimit = uint64(6000000)
 	auth.GasPrice = big.NewInt(30000000000)
 	var err error
@@ -295,12 +296,12 @@ func deployContract(contractPath, targetTask, configPath string, config configur
 		proxyAddress := common.HexToAddress(chainConfig.DOSProxyAddress)
 		crAddress := common.HexToAddress(chainConfig.CommitReveal)
 
-		err := updateBridge(client, key, bridgeAddress, proxyAddress, paymentAddress, crAddress)
+		err := updateBridge(client, key, chainID, bridgeAddress, proxyAddress, paymentAddress, crAddress)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		err = addProxyToCRWhiteList(client, key, crAddress, proxyAddress)
+		err = addProxyToCRWhiteList(client, key, chainID, crAddress, proxyAddress)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -367,6 +368,9 @@ func main() {
 
 	chainConfig := config.GetChainConfig()
 	fmt.Println("Use : ", chainConfig)
+	if chainConfig.ChainID == 0 {
+		log.Fatal("ChainID is not set in the config")
+	}
 	fmt.Println(" address ", chainConfig.RemoteNodeAddressPool[0])
 
 	password := os.Getenv("PASSPHRASE")